@@ -18,10 +18,14 @@ package cmd
 
 import (
 	"fmt"
+	"strings"
 
+	"github.com/charmbracelet/lipgloss"
 	"github.com/spf13/afero"
 	"github.com/spf13/cobra"
 
+	"github.com/nitrictech/cli/pkg/pflagx"
+	"github.com/nitrictech/cli/pkg/project/scaffold"
 	"github.com/nitrictech/cli/pkg/view/tui"
 	add_website "github.com/nitrictech/cli/pkg/view/tui/commands/website"
 	"github.com/nitrictech/cli/pkg/view/tui/teax"
@@ -32,12 +36,54 @@ import (
 var addCmd = &cobra.Command{
 	Use:   "add",
 	Short: "Add new resources to your Nitric project",
-	Long: `Add new components such as websites to an existing Nitric project.
-Run 'nitric add website' to add a new website.`,
-	Example: `# Add a new website interactively
+	Long: `Add new components such as services or websites to an existing Nitric project.
+Run 'nitric add service' to add a new service or 'nitric add website' to add a new website.`,
+	Example: `# Add a new typescript api service
+nitric add service orders --lang ts
+
+# Add a new website interactively
 nitric add website`,
 }
 
+var (
+	addServiceLang string
+	addServiceType string
+)
+
+var addServiceCmd = &cobra.Command{
+	Use:   "service [serviceName]",
+	Short: "Add a new service to your Nitric project",
+	Long: `Add a new service to your Nitric project, creating a handler from a snippet for the language.
+
+The handler is created using an existing match pattern for the language from your nitric.yaml,
+or a new services entry is added if there isn't one. Job handlers are added as batch services.`,
+	Example: `# Add a typescript api service
+nitric add service orders --lang ts
+
+# Add a python topic subscriber
+nitric add service order-created --lang py --type topic
+
+# Add a go batch job
+nitric add service generate-report --lang go --type job`,
+	Run: func(cmd *cobra.Command, args []string) {
+		fs := afero.NewOsFs()
+
+		result, err := scaffold.AddService(fs, "", scaffold.ServiceOptions{
+			Name:     args[0],
+			Language: addServiceLang,
+			Type:     scaffold.HandlerType(addServiceType),
+		})
+		tui.CheckErr(err)
+
+		if result.NewEntry {
+			fmt.Printf("Added %s to nitric.yaml\n", lipgloss.NewStyle().Foreground(tui.Colors.Blue).Render(result.Match))
+		}
+
+		fmt.Printf("Created %s service %s\n", addServiceType, lipgloss.NewStyle().Foreground(tui.Colors.Blue).Render(result.File))
+	},
+	Args: cobra.ExactArgs(1),
+}
+
 var addWebsiteCmd = &cobra.Command{
 	Use:   "website [websiteName] [toolName]",
 	Short: "Add a new website to your Nitric project",
@@ -88,6 +134,7 @@ nitric add website my-site astro`,
 func init() {
 	rootCmd.AddCommand(addCmd)
 
+	addCmd.AddCommand(addServiceCmd)
 	addCmd.AddCommand(addWebsiteCmd)
 
 	addStackCmd := &cobra.Command{
@@ -100,5 +147,9 @@ func init() {
 	addStackCmd.Flags().AddFlagSet(newStackCmd.Flags())
 	addCmd.AddCommand(addStackCmd)
 
+	addServiceCmd.Flags().VarP(pflagx.NewStringEnumVar(&addServiceLang, scaffold.Languages, ""), "lang", "l", fmt.Sprintf("the language of the service, one of %s", strings.Join(scaffold.Languages, ", ")))
+	tui.CheckErr(addServiceCmd.MarkFlagRequired("lang"))
+	addServiceCmd.Flags().VarP(pflagx.NewStringEnumVar(&addServiceType, scaffold.HandlerTypes, string(scaffold.HandlerType_Api)), "type", "t", fmt.Sprintf("the type of handler to create, one of %s", strings.Join(scaffold.HandlerTypes, ", ")))
+
 	addWebsiteCmd.Flags().StringP("path", "p", "", "base url path for the website, e.g. /my-site")
 }
@@ -0,0 +1,181 @@
+// Copyright Nitric Pty Ltd.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+
+	"github.com/nitrictech/cli/pkg/pflagx"
+	"github.com/nitrictech/cli/pkg/project"
+	"github.com/nitrictech/cli/pkg/project/localconfig"
+	"github.com/nitrictech/cli/pkg/project/stack"
+	"github.com/nitrictech/cli/pkg/project/yamlschema"
+	"github.com/nitrictech/cli/pkg/view/tui"
+	"github.com/nitrictech/cli/pkg/view/tui/fragments"
+)
+
+var configSchemaProvider string
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Validate nitric configuration files",
+	Long:  `Validate the nitric.yaml, local.nitric.yaml and stack files of a project, or print their JSON Schemas for use in editors.`,
+}
+
+// validateConfigFile checks a configuration file decodes into the configuration type and has no unknown keys
+func validateConfigFile(fs afero.Fs, filePath string, config any, schema *yamlschema.Schema) error {
+	contents, err := afero.ReadFile(fs, filePath)
+	if err != nil {
+		return err
+	}
+
+	if err := yaml.Unmarshal(contents, config); err != nil {
+		return err
+	}
+
+	return yamlschema.Validate(contents, schema)
+}
+
+// printConfigErrors prints the result of validating a configuration file, returning true if it's valid
+func printConfigErrors(filePath string, err error) bool {
+	if err == nil {
+		fmt.Println(fragments.CustomTag("pass", tui.Colors.White, tui.Colors.Green), filePath)
+		return true
+	}
+
+	fmt.Println(fragments.CustomTag("fail", tui.Colors.White, tui.Colors.Red), filePath)
+
+	fieldErrs := yamlschema.FieldErrors{}
+	if !errors.As(err, &fieldErrs) {
+		fmt.Printf("  %s\n", strings.ReplaceAll(err.Error(), "\n", "\n  "))
+		return false
+	}
+
+	for _, fieldErr := range fieldErrs {
+		fmt.Printf("  %s:%d:%d %s\n", filePath, fieldErr.Line, fieldErr.Column, strings.SplitN(fieldErr.Error(), ": ", 2)[1])
+	}
+
+	return false
+}
+
+// printConfigWarnings prints the keys of a configuration file that may be misspelled, without failing its validation
+func printConfigWarnings(filePath string, warnings yamlschema.FieldErrors) {
+	fmt.Println(fragments.CustomTag("warn", tui.Colors.White, tui.Colors.Orange), filePath)
+
+	for _, warning := range warnings {
+		fmt.Printf("  %s:%d:%d %s\n", filePath, warning.Line, warning.Column, strings.SplitN(warning.Error(), ": ", 2)[1])
+	}
+}
+
+var configValidateCmd = &cobra.Command{
+	Use:   "validate",
+	Short: "Validate the configuration files of the project",
+	Long: `Validate the nitric.yaml, local.nitric.yaml and stack files in the current directory.
+
+Unknown or misspelled keys are reported with their line and column, and the service match patterns of the nitric.yaml are checked.
+Keys of stack files that aren't in their provider's stack template are reported as warnings, providers may accept more configuration than their template shows.
+Exits with a non-zero status if any file is invalid, so it can be used in CI.`,
+	Example: `nitric config validate`,
+	Run: func(cmd *cobra.Command, args []string) {
+		fs := afero.NewOsFs()
+
+		failed := 0
+		total := 1
+
+		err := validateConfigFile(fs, "nitric.yaml", &project.ProjectConfiguration{}, project.ConfigurationSchema())
+		if err == nil {
+			// check the match patterns and build contexts of the services
+			_, err = project.FromFile(fs, "")
+		}
+
+		if !printConfigErrors("nitric.yaml", err) {
+			failed++
+		}
+
+		if exists, _ := afero.Exists(fs, "local.nitric.yaml"); exists {
+			total++
+
+			if !printConfigErrors("local.nitric.yaml", validateConfigFile(fs, "local.nitric.yaml", &localconfig.LocalConfiguration{}, localconfig.LocalConfigurationSchema())) {
+				failed++
+			}
+		}
+
+		stackFiles, err := stack.GetAllStackFiles(fs)
+		tui.CheckErr(err)
+
+		for _, stackFile := range stackFiles {
+			total++
+
+			warnings, err := stack.ValidateFile(fs, stackFile)
+			if len(warnings) > 0 {
+				printConfigWarnings(stackFile, warnings)
+				continue
+			}
+
+			if !printConfigErrors(stackFile, err) {
+				failed++
+			}
+		}
+
+		if failed > 0 {
+			tui.CheckErr(fmt.Errorf("%d of %d configuration files are invalid", failed, total))
+		}
+	},
+	Args: cobra.ExactArgs(0),
+}
+
+var configSchemaCmd = &cobra.Command{
+	Use:       "schema [project|local|stack]",
+	Short:     "Print the JSON Schema of a configuration file",
+	Long:      `Print the JSON Schema of the nitric.yaml (project), local.nitric.yaml (local) or a provider's stack files (stack).`,
+	ValidArgs: []string{"project", "local", "stack"},
+	Example: `nitric config schema project > nitric.schema.json
+nitric config schema stack --provider nitric/gcp`,
+	Run: func(cmd *cobra.Command, args []string) {
+		var schema *yamlschema.Schema
+
+		switch args[0] {
+		case "project":
+			schema = project.ConfigurationSchema()
+		case "local":
+			schema = localconfig.LocalConfigurationSchema()
+		case "stack":
+			schema = stack.ConfigSchema(configSchemaProvider)
+		}
+
+		schemaJson, err := json.MarshalIndent(schema, "", "  ")
+		tui.CheckErr(err)
+
+		fmt.Println(string(schemaJson))
+	},
+	Args: cobra.MatchAll(cobra.ExactArgs(1), cobra.OnlyValidArgs),
+}
+
+func init() {
+	configSchemaCmd.Flags().VarP(pflagx.NewStringEnumVar(&configSchemaProvider, stack.ProviderNames(), "nitric/aws"), "provider", "p", fmt.Sprintf("the provider of the stack schema, one of %s", strings.Join(stack.ProviderNames(), ", ")))
+
+	configCmd.AddCommand(configValidateCmd)
+	configCmd.AddCommand(configSchemaCmd)
+	rootCmd.AddCommand(configCmd)
+}
@@ -0,0 +1,670 @@
+// Copyright Nitric Pty Ltd.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+	orderedmap "github.com/wk8/go-ordered-map/v2"
+
+	"github.com/nitrictech/cli/pkg/cloud"
+	"github.com/nitrictech/cli/pkg/cloud/sql"
+	"github.com/nitrictech/cli/pkg/collector"
+	"github.com/nitrictech/cli/pkg/paths"
+	"github.com/nitrictech/cli/pkg/project"
+	"github.com/nitrictech/cli/pkg/project/stack"
+	"github.com/nitrictech/cli/pkg/system"
+	"github.com/nitrictech/cli/pkg/view/tui"
+	resourcespb "github.com/nitrictech/nitric/core/pkg/proto/resources/v1"
+	sqlpb "github.com/nitrictech/nitric/core/pkg/proto/sql/v1"
+)
+
+var (
+	dbMigrateDownSteps int
+	dbMigrateDownAll   bool
+	dbResetNoMigrate   bool
+)
+
+var dbCmd = &cobra.Command{
+	Use:     "db",
+	Aliases: []string{"database"},
+	Short:   "Work with the local databases of your project",
+	Long: `Work with the local databases of your project.
+
+Commands use the database container of a running nitric start or nitric run,
+the container is started for the duration of the command when the project isn't running.`,
+}
+
+// withLocalDatabaseServer runs fn with the local database server of the project.
+// The server is stopped if it was started by the command before an error from fn is reported, as reporting it exits the process.
+func withLocalDatabaseServer(proj *project.Project, fn func(server *sql.LocalSqlServer) error) {
+	server, started, err := sql.ConnectLocalSqlServer(proj.Name, sql.LocalSqlServerOptions{
+		ProjectDirectory: proj.Directory,
+		Config:           proj.LocalConfig.Sql,
+	})
+	tui.CheckErr(err)
+
+	err = fn(server)
+
+	if started {
+		err = errors.Join(err, server.Stop())
+	}
+
+	tui.CheckErr(err)
+}
+
+// warnSqlVersionMismatch warns when the local databases use a different postgres major version than the project's stacks deploy
+// The warning is printed straight away when sql.version is set, otherwise it's logged once the project declares a database
+func warnSqlVersionMismatch(fs afero.Fs, proj *project.Project, localCloud *cloud.LocalCloud) {
+	warnings := sqlVersionMismatches(fs, proj)
+	if len(warnings) == 0 {
+		return
+	}
+
+	if proj.LocalConfig.Sql.Version != "" {
+		for _, warning := range warnings {
+			tui.Warning.Println(warning)
+		}
+
+		return
+	}
+
+	var once sync.Once
+
+	localCloud.Databases.SubscribeToState(func(state sql.State) {
+		if len(state) == 0 {
+			return
+		}
+
+		once.Do(func() {
+			for _, warning := range warnings {
+				system.Log(warning)
+			}
+		})
+	})
+}
+
+// sqlVersionMismatches returns a warning for each stack that deploys a different postgres major version than the local databases use
+func sqlVersionMismatches(fs afero.Fs, proj *project.Project) []string {
+	sqlConfig := proj.LocalConfig.Sql
+	if sqlConfig.Version == "" {
+		sqlConfig.Version = sql.DefaultVersion
+	}
+
+	localVersion := sqlConfig.MajorVersion()
+	if localVersion == "" {
+		return nil
+	}
+
+	stacks, err := stack.GetAllStacks[map[string]any](fs)
+	if err != nil {
+		// stack files are validated by the stack commands
+		return nil
+	}
+
+	warnings := []string{}
+
+	for _, stackName := range slices.Sorted(maps.Keys(stacks)) {
+		targetVersion, ok := stack.PostgresMajorVersion(stacks[stackName].Provider)
+		if ok && targetVersion != localVersion {
+			warnings = append(warnings, fmt.Sprintf("local databases use postgres %s, but stack %s deploys postgres %s, set sql.version in local.nitric.yaml to match", localVersion, stackName, targetVersion))
+		}
+	}
+
+	return warnings
+}
+
+// localDatabaseConnectionString returns the connection string of a database, creating it if it doesn't exist
+func localDatabaseConnectionString(server *sql.LocalSqlServer, databaseName string) (string, error) {
+	resp, err := server.ConnectionString(context.Background(), &sqlpb.SqlConnectionStringRequest{DatabaseName: databaseName})
+	if err != nil {
+		return "", err
+	}
+
+	return resp.ConnectionString, nil
+}
+
+// declaredDatabase builds and collects the project's services to find the declaration of a database
+func declaredDatabase(fs afero.Fs, proj *project.Project, databaseName string) *resourcespb.SqlDatabaseResource {
+	_, serviceRequirements, batchRequirements := collectProjectSpec(fs, proj)
+
+	database, ok := collector.SqlDatabases(serviceRequirements, batchRequirements)[databaseName]
+	if !ok {
+		tui.CheckErr(fmt.Errorf("database %s isn't declared by any service in the project", databaseName))
+	}
+
+	return database
+}
+
+// printMigrationOutput prints the output of a migrations container
+func printMigrationOutput(databaseName string, output string) {
+	for _, line := range strings.Split(strings.TrimSpace(output), "\n") {
+		if line != "" {
+			fmt.Printf("%s-migrations: %s\n", databaseName, line)
+		}
+	}
+}
+
+// runMigrationCommand builds the migrations of a declared database and runs a migrate command against it
+func runMigrationCommand(databaseName string, args ...string) {
+	fs := afero.NewOsFs()
+
+	proj, err := project.FromFile(fs, "")
+	tui.CheckErr(err)
+
+	database := declaredDatabase(fs, proj, databaseName)
+
+	// only the default migrations image includes the migrate tool
+	_, err = collector.MigrationsDirectory(databaseName, database)
+	tui.CheckErr(err)
+
+	withLocalDatabaseServer(proj, func(server *sql.LocalSqlServer) error {
+		connectionString, err := localDatabaseConnectionString(server, databaseName)
+		if err != nil {
+			return err
+		}
+
+		_, err = project.BuildDatabaseMigrationImages(fs, map[string]*resourcespb.SqlDatabaseResource{databaseName: database}, !noBuilder)
+		if err != nil {
+			return err
+		}
+
+		return project.RunMigrationCommand(databaseName, connectionString, printMigrationOutput, args...)
+	})
+}
+
+var dbListCmd = &cobra.Command{
+	Use:     "list",
+	Short:   "List the local databases of the project",
+	Example: `nitric db list`,
+	Run: func(cmd *cobra.Command, args []string) {
+		proj, err := project.FromFile(afero.NewOsFs(), "")
+		tui.CheckErr(err)
+
+		withLocalDatabaseServer(proj, func(server *sql.LocalSqlServer) error {
+			databases, err := server.Databases(context.Background())
+			if err != nil {
+				return err
+			}
+
+			if len(databases) == 0 {
+				tui.Warning.Println("No local databases found, databases are created when the services that declare them run")
+				return nil
+			}
+
+			nameLength := 0
+			for _, name := range databases {
+				nameLength = max(nameLength, len(name))
+			}
+
+			for _, name := range databases {
+				connectionString, err := localDatabaseConnectionString(server, name)
+				if err != nil {
+					return err
+				}
+
+				fmt.Printf("%-*s  %s\n", nameLength, name, lipgloss.NewStyle().Foreground(tui.Colors.TextMuted).Render(connectionString))
+			}
+
+			return nil
+		})
+	},
+	Args: cobra.ExactArgs(0),
+}
+
+// printQueryResults prints query results as a table of columns
+func printQueryResults(results []*orderedmap.OrderedMap[string, any]) {
+	if len(results) == 0 {
+		fmt.Println("(0 rows)")
+		return
+	}
+
+	columns := []string{}
+	widths := map[string]int{}
+
+	for pair := results[0].Oldest(); pair != nil; pair = pair.Next() {
+		columns = append(columns, pair.Key)
+		widths[pair.Key] = len(pair.Key)
+	}
+
+	rows := make([][]string, 0, len(results))
+
+	for _, result := range results {
+		row := []string{}
+
+		for _, column := range columns {
+			value := ""
+			if v, _ := result.Get(column); v != nil {
+				value = fmt.Sprint(v)
+			}
+
+			widths[column] = max(widths[column], len(value))
+			row = append(row, value)
+		}
+
+		rows = append(rows, row)
+	}
+
+	header := []string{}
+	separator := []string{}
+
+	for _, column := range columns {
+		header = append(header, fmt.Sprintf(" %-*s ", widths[column], column))
+		separator = append(separator, strings.Repeat("-", widths[column]+2))
+	}
+
+	fmt.Println(lipgloss.NewStyle().Bold(true).Render(strings.Join(header, "|")))
+	fmt.Println(strings.Join(separator, "+"))
+
+	for _, row := range rows {
+		cells := []string{}
+
+		for i, value := range row {
+			cells = append(cells, fmt.Sprintf(" %-*s ", widths[columns[i]], value))
+		}
+
+		fmt.Println(strings.Join(cells, "|"))
+	}
+
+	fmt.Printf("(%d rows)\n", len(rows))
+}
+
+const dbShellHelp = `Statements are run when they end with a semicolon, multiple statements are run in one transaction.
+
+  \l    list databases
+  \dt   list tables
+  \?    show this help
+  \q    quit`
+
+var dbShellCmd = &cobra.Command{
+	Use:   "shell [databaseName]",
+	Short: "Open an interactive SQL shell for a local database",
+	Example: `nitric db shell my-db
+echo "SELECT 1;" | nitric db shell my-db`,
+	Run: func(cmd *cobra.Command, args []string) {
+		proj, err := project.FromFile(afero.NewOsFs(), "")
+		tui.CheckErr(err)
+
+		databaseName := args[0]
+
+		withLocalDatabaseServer(proj, func(server *sql.LocalSqlServer) error {
+			databases, err := server.Databases(context.Background())
+			if err != nil {
+				return err
+			}
+
+			if !slices.Contains(databases, databaseName) {
+				return fmt.Errorf("database %s doesn't exist, run nitric db list to see the local databases", databaseName)
+			}
+
+			connectionString, err := localDatabaseConnectionString(server, databaseName)
+			if err != nil {
+				return err
+			}
+
+			query := func(statement string) {
+				result, err := server.Query(context.Background(), connectionString, statement, sql.QueryOptions{})
+				if err != nil {
+					tui.Error.Println(err.Error())
+					return
+				}
+
+				printQueryResults(result.Rows)
+			}
+
+			interactive := !isNonInteractive()
+
+			if interactive {
+				fmt.Printf("Connected to %s, type \\? for help\n", databaseName)
+			}
+
+			scanner := bufio.NewScanner(os.Stdin)
+			statement := ""
+
+			for {
+				if interactive {
+					if statement == "" {
+						fmt.Printf("%s=> ", databaseName)
+					} else {
+						fmt.Printf("%s-> ", databaseName)
+					}
+				}
+
+				if !scanner.Scan() {
+					break
+				}
+
+				line := strings.TrimSpace(scanner.Text())
+
+				if statement == "" && strings.HasPrefix(line, "\\") {
+					switch strings.Fields(line)[0] {
+					case "\\q":
+						return nil
+					case "\\l":
+						for _, name := range databases {
+							fmt.Println(name)
+						}
+					case "\\dt":
+						query("SELECT table_schema AS schema, table_name AS name, table_type AS type FROM information_schema.tables WHERE table_schema NOT IN ('pg_catalog', 'information_schema') ORDER BY 1, 2")
+					case "\\?":
+						fmt.Println(dbShellHelp)
+					default:
+						tui.Error.Printfln("unknown command %s, type \\? for help", line)
+					}
+
+					continue
+				}
+
+				// semicolons in strings, quoted identifiers and comments don't end a statement
+				terminated, remainder := sql.SQLSplitTerminated(statement + "\n" + line)
+				if len(terminated) > 0 {
+					query(strings.Join(terminated, "\n"))
+				}
+
+				statement = strings.TrimSpace(remainder)
+			}
+
+			if err := scanner.Err(); err != nil {
+				return err
+			}
+
+			// run an unterminated final statement from piped input
+			if statement != "" {
+				query(statement)
+			}
+
+			return nil
+		})
+	},
+	Args: cobra.ExactArgs(1),
+}
+
+var dbMigrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Apply, revert or inspect the migrations of a local database",
+	Long: `Apply, revert or inspect the migrations of a local database.
+
+Migrations are built from the migrations path declared for the database, down and status require file:// migrations.`,
+}
+
+var dbMigrateUpCmd = &cobra.Command{
+	Use:     "up [databaseName]",
+	Short:   "Apply the pending migrations of a local database",
+	Example: `nitric db migrate up my-db`,
+	Run: func(cmd *cobra.Command, args []string) {
+		fs := afero.NewOsFs()
+
+		proj, err := project.FromFile(fs, "")
+		tui.CheckErr(err)
+
+		databaseName := args[0]
+		database := declaredDatabase(fs, proj, databaseName)
+
+		if database.GetMigrations().GetMigrationsPath() == "" {
+			tui.CheckErr(fmt.Errorf("database %s has no migrations", databaseName))
+		}
+
+		withLocalDatabaseServer(proj, func(server *sql.LocalSqlServer) error {
+			connectionString, err := localDatabaseConnectionString(server, databaseName)
+			if err != nil {
+				return err
+			}
+
+			servers := map[string]*sql.DatabaseServer{
+				databaseName: {DatabaseName: databaseName, ConnectionString: connectionString},
+			}
+
+			return project.BuildAndRunMigrations(fs, servers, map[string]*resourcespb.SqlDatabaseResource{databaseName: database}, !noBuilder, printMigrationOutput)
+		})
+
+		fmt.Printf("Applied migrations of database %s\n", databaseName)
+	},
+	Args: cobra.ExactArgs(1),
+}
+
+var dbMigrateDownCmd = &cobra.Command{
+	Use:   "down [databaseName]",
+	Short: "Revert the applied migrations of a local database",
+	Example: `nitric db migrate down my-db
+nitric db migrate down my-db --steps 3
+nitric db migrate down my-db --all`,
+	Run: func(cmd *cobra.Command, args []string) {
+		migrateArgs := []string{"down", fmt.Sprint(dbMigrateDownSteps)}
+		if dbMigrateDownAll {
+			migrateArgs = []string{"down", "-all"}
+		}
+
+		runMigrationCommand(args[0], migrateArgs...)
+	},
+	Args: cobra.ExactArgs(1),
+}
+
+var dbMigrateStatusCmd = &cobra.Command{
+	Use:     "status [databaseName]",
+	Short:   "Print the current migration version of a local database",
+	Example: `nitric db migrate status my-db`,
+	Run: func(cmd *cobra.Command, args []string) {
+		runMigrationCommand(args[0], "version")
+	},
+	Args: cobra.ExactArgs(1),
+}
+
+var dbNewMigrationCmd = &cobra.Command{
+	Use:   "new-migration [databaseName] [title]",
+	Short: "Create up and down migration files for a database",
+	Long: `Create empty up and down migration files in the file:// migrations path declared for a database.
+
+Files are named with a timestamp version and the title, e.g. 20240102150405_add_users.up.sql`,
+	Example: `nitric db new-migration my-db "add users"`,
+	Run: func(cmd *cobra.Command, args []string) {
+		fs := afero.NewOsFs()
+
+		proj, err := project.FromFile(fs, "")
+		tui.CheckErr(err)
+
+		database := declaredDatabase(fs, proj, args[0])
+
+		migrationsDir, err := collector.MigrationsDirectory(args[0], database)
+		tui.CheckErr(err)
+
+		files, err := project.NewMigrationFiles(fs, migrationsDir, args[1], time.Now())
+		tui.CheckErr(err)
+
+		for _, file := range files {
+			fmt.Printf("Created %s\n", file)
+		}
+	},
+	Args: cobra.ExactArgs(2),
+}
+
+var dbResetCmd = &cobra.Command{
+	Use:   "reset [databaseName]",
+	Short: "Drop and recreate a local database",
+	Long:  `Drop and recreate a local database, then apply its migrations unless --no-migrate is provided.`,
+	Example: `nitric db reset my-db
+nitric db reset my-db --no-migrate`,
+	Run: func(cmd *cobra.Command, args []string) {
+		fs := afero.NewOsFs()
+
+		proj, err := project.FromFile(fs, "")
+		tui.CheckErr(err)
+
+		databaseName := args[0]
+
+		var database *resourcespb.SqlDatabaseResource
+		if !dbResetNoMigrate {
+			database = declaredDatabase(fs, proj, databaseName)
+		}
+
+		withLocalDatabaseServer(proj, func(server *sql.LocalSqlServer) error {
+			if err := server.DropDatabase(context.Background(), databaseName); err != nil {
+				return err
+			}
+
+			connectionString, err := localDatabaseConnectionString(server, databaseName)
+			if err != nil {
+				return err
+			}
+
+			fmt.Printf("Reset database %s\n", databaseName)
+
+			if database.GetMigrations().GetMigrationsPath() == "" {
+				return nil
+			}
+
+			servers := map[string]*sql.DatabaseServer{
+				databaseName: {DatabaseName: databaseName, ConnectionString: connectionString},
+			}
+
+			err = project.BuildAndRunMigrations(fs, servers, map[string]*resourcespb.SqlDatabaseResource{databaseName: database}, !noBuilder, printMigrationOutput)
+			if err != nil {
+				return err
+			}
+
+			fmt.Printf("Applied migrations of database %s\n", databaseName)
+
+			return nil
+		})
+	},
+	Args: cobra.ExactArgs(1),
+}
+
+var dbSnapshotCmd = &cobra.Command{
+	Use:     "snapshot",
+	Aliases: []string{"snapshots"},
+	Short:   "Save and restore snapshots of the local databases",
+	Long: fmt.Sprintf(`Save and restore snapshots of the local databases, snapshots are stored in %s.
+
+Set sql.branchSnapshots in local.nitric.yaml to keep a snapshot for each git branch,
+nitric start and nitric run swap them when the checked out branch changes.`, paths.NitricDatabaseSnapshotsDir("")),
+}
+
+var dbSnapshotCreateCmd = &cobra.Command{
+	Use:   "create [snapshotName] [databaseName...]",
+	Short: "Snapshot local databases, or all databases if none are provided",
+	Example: `nitric db snapshot create before-upgrade
+nitric db snapshot create seeded users orders`,
+	Run: func(cmd *cobra.Command, args []string) {
+		proj, err := project.FromFile(afero.NewOsFs(), "")
+		tui.CheckErr(err)
+
+		withLocalDatabaseServer(proj, func(server *sql.LocalSqlServer) error {
+			snapshot, err := server.CreateSnapshot(context.Background(), args[0], args[1:])
+			if err != nil {
+				return err
+			}
+
+			fmt.Printf("Saved snapshot %s of databases %s\n", snapshot.Name, strings.Join(snapshot.Databases, ", "))
+
+			return nil
+		})
+	},
+	Args: cobra.MinimumNArgs(1),
+}
+
+var dbSnapshotRestoreCmd = &cobra.Command{
+	Use:   "restore [snapshotName] [databaseName...]",
+	Short: "Replace local databases with a snapshot, or all databases in the snapshot if none are provided",
+	Example: `nitric db snapshot restore before-upgrade
+nitric db snapshot restore seeded users`,
+	Run: func(cmd *cobra.Command, args []string) {
+		proj, err := project.FromFile(afero.NewOsFs(), "")
+		tui.CheckErr(err)
+
+		withLocalDatabaseServer(proj, func(server *sql.LocalSqlServer) error {
+			return server.RestoreSnapshot(context.Background(), args[0], args[1:])
+		})
+
+		fmt.Printf("Restored snapshot %s\n", args[0])
+	},
+	Args: cobra.MinimumNArgs(1),
+}
+
+var dbSnapshotListCmd = &cobra.Command{
+	Use:     "list",
+	Short:   "List the database snapshots of the project",
+	Example: `nitric db snapshot list`,
+	Run: func(cmd *cobra.Command, args []string) {
+		proj, err := project.FromFile(afero.NewOsFs(), "")
+		tui.CheckErr(err)
+
+		snapshots, err := sql.Snapshots(proj.Directory)
+		tui.CheckErr(err)
+
+		if len(snapshots) == 0 {
+			tui.Warning.Println("No database snapshots found")
+			return
+		}
+
+		nameLength := 0
+		for _, snapshot := range snapshots {
+			nameLength = max(nameLength, len(snapshot.Name))
+		}
+
+		for _, snapshot := range snapshots {
+			details := fmt.Sprintf("%s  %s", snapshot.CreatedAt.Format(time.DateTime), strings.Join(snapshot.Databases, ", "))
+
+			fmt.Printf("%-*s  %s\n", nameLength, snapshot.Name, lipgloss.NewStyle().Foreground(tui.Colors.TextMuted).Render(details))
+		}
+	},
+	Args: cobra.ExactArgs(0),
+}
+
+var dbSnapshotDeleteCmd = &cobra.Command{
+	Use:     "delete [snapshotName]",
+	Short:   "Delete a database snapshot",
+	Example: `nitric db snapshot delete before-upgrade`,
+	Run: func(cmd *cobra.Command, args []string) {
+		proj, err := project.FromFile(afero.NewOsFs(), "")
+		tui.CheckErr(err)
+
+		tui.CheckErr(sql.DeleteSnapshot(proj.Directory, args[0]))
+
+		fmt.Printf("Deleted snapshot %s\n", args[0])
+	},
+	Args: cobra.ExactArgs(1),
+}
+
+func init() {
+	dbMigrateDownCmd.Flags().IntVar(&dbMigrateDownSteps, "steps", 1, "the number of migrations to revert")
+	dbMigrateDownCmd.Flags().BoolVar(&dbMigrateDownAll, "all", false, "revert all applied migrations")
+	dbMigrateDownCmd.MarkFlagsMutuallyExclusive("steps", "all")
+
+	dbResetCmd.Flags().BoolVar(&dbResetNoMigrate, "no-migrate", false, "don't apply migrations after recreating the database")
+
+	for _, cmd := range []*cobra.Command{dbMigrateUpCmd, dbMigrateDownCmd, dbMigrateStatusCmd, dbNewMigrationCmd, dbResetCmd} {
+		cmd.Flags().BoolVar(&noBuilder, "no-builder", false, "don't create a buildx container")
+	}
+
+	dbMigrateCmd.AddCommand(dbMigrateUpCmd, dbMigrateDownCmd, dbMigrateStatusCmd)
+	dbSnapshotCmd.AddCommand(dbSnapshotCreateCmd, dbSnapshotRestoreCmd, dbSnapshotListCmd, dbSnapshotDeleteCmd)
+	dbCmd.AddCommand(dbListCmd, dbShellCmd, dbMigrateCmd, dbNewMigrationCmd, dbResetCmd, dbSnapshotCmd)
+
+	rootCmd.AddCommand(dbCmd)
+}
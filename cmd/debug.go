@@ -17,26 +17,43 @@
 package cmd
 
 import (
+	"encoding/base64"
+	"encoding/json"
 	"fmt"
 	"os"
+	"path/filepath"
 	"strings"
 
+	"github.com/charmbracelet/lipgloss"
+	"github.com/getkin/kin-openapi/openapi3"
 	"github.com/samber/lo"
 	"github.com/spf13/afero"
 	"github.com/spf13/cobra"
 	"google.golang.org/protobuf/encoding/protojson"
+	"gopkg.in/yaml.v3"
 
+	"github.com/nitrictech/cli/pkg/audit"
 	"github.com/nitrictech/cli/pkg/collector"
+	"github.com/nitrictech/cli/pkg/dashboard"
 	"github.com/nitrictech/cli/pkg/env"
+	"github.com/nitrictech/cli/pkg/graph"
+	"github.com/nitrictech/cli/pkg/pflagx"
 	"github.com/nitrictech/cli/pkg/project"
 	"github.com/nitrictech/cli/pkg/view/tui"
 	"github.com/nitrictech/cli/pkg/view/tui/commands/build"
+	"github.com/nitrictech/cli/pkg/view/tui/components/view"
 	"github.com/nitrictech/cli/pkg/view/tui/teax"
+	deploymentspb "github.com/nitrictech/nitric/core/pkg/proto/deployments/v1"
 )
 
 var (
-	debugEnvFile string
-	debugFile    string
+	debugEnvFile       string
+	debugFile          string
+	debugGraphFormat   string
+	debugFailOn        string
+	debugApiName       string
+	debugOpenApiFormat string
+	debugNoExamples    bool
 )
 
 var debugCmd = &cobra.Command{
@@ -51,76 +68,81 @@ var debugCmd = &cobra.Command{
 	},
 }
 
-var specCmd = &cobra.Command{
-	Use:   "spec",
-	Short: "Output the nitric application cloud spec.",
-	Long:  `Output the nitric application cloud spec.`,
-	Run: func(cmd *cobra.Command, args []string) {
-		fs := afero.NewOsFs()
+// collectProjectSpec builds the project's services and batches, then collects their requirements into a deployment spec
+func collectProjectSpec(fs afero.Fs, proj *project.Project) (*deploymentspb.Spec, []*collector.ServiceRequirements, []*collector.BatchRequirements) {
+	// Build the Project's Services (Containers)
+	buildUpdates, err := proj.BuildServices(fs, !noBuilder)
+	tui.CheckErr(err)
 
-		proj, err := project.FromFile(fs, "")
-		tui.CheckErr(err)
+	batchBuildUpdates, err := proj.BuildBatches(fs, !noBuilder)
+	tui.CheckErr(err)
 
-		// Build the Project's Services (Containers)
-		buildUpdates, err := proj.BuildServices(fs, !noBuilder)
-		tui.CheckErr(err)
+	allBuildUpdates := lo.FanIn(10, buildUpdates, batchBuildUpdates)
+
+	if isNonInteractive() {
+		// build progress is written to stderr, so commands with machine-readable output can be piped
+		fmt.Fprintln(os.Stderr, "building project services")
+		for _, service := range proj.GetServices() {
+			fmt.Fprintf(os.Stderr, "service matched '%s', auto-naming this service '%s'\n", service.GetFilePath(), service.Name)
+		}
 
-		batchBuildUpdates, err := proj.BuildBatches(fs, !noBuilder)
+		// non-interactive environment
+		for update := range allBuildUpdates {
+			for _, line := range strings.Split(strings.TrimSuffix(update.Message, "\n"), "\n") {
+				fmt.Fprintf(os.Stderr, "%s [%s]: %s\n", update.ServiceName, update.Status, line)
+			}
+		}
+	} else {
+		prog := teax.NewProgram(build.NewModel(allBuildUpdates, "Building Services"))
+		// blocks but quits once the above updates channel is closed by the build process
+		buildModel, err := prog.Run()
 		tui.CheckErr(err)
+		if buildModel.(build.Model).Err != nil {
+			tui.CheckErr(fmt.Errorf("error building services"))
+		}
+	}
 
-		allBuildUpdates := lo.FanIn(10, buildUpdates, batchBuildUpdates)
+	// Step 2. Start the collectors and containers (respectively in pairs)
+	// Step 3. Merge requirements from collectors into a specification
+	serviceRequirements, err := proj.CollectServicesRequirements()
+	tui.CheckErr(err)
 
-		if isNonInteractive() {
-			fmt.Println("building project services")
-			for _, service := range proj.GetServices() {
-				fmt.Printf("service matched '%s', auto-naming this service '%s'\n", service.GetFilePath(), service.Name)
-			}
+	batchRequirements, err := proj.CollectBatchRequirements()
+	tui.CheckErr(err)
 
-			// non-interactive environment
-			for update := range buildUpdates {
-				for _, line := range strings.Split(strings.TrimSuffix(update.Message, "\n"), "\n") {
-					fmt.Printf("%s [%s]: %s\n", update.ServiceName, update.Status, line)
-				}
-			}
-		} else {
-			prog := teax.NewProgram(build.NewModel(allBuildUpdates, "Building Services"))
-			// blocks but quits once the above updates channel is closed by the build process
-			buildModel, err := prog.Run()
-			tui.CheckErr(err)
-			if buildModel.(build.Model).Err != nil {
-				tui.CheckErr(fmt.Errorf("error building services"))
-			}
-		}
+	websiteRequirements, err := proj.CollectWebsiteRequirements()
+	tui.CheckErr(err)
 
-		// Step 2. Start the collectors and containers (respectively in pairs)
-		// Step 3. Merge requirements from collectors into a specification
-		serviceRequirements, err := proj.CollectServicesRequirements()
-		tui.CheckErr(err)
+	additionalEnvFiles := []string{}
 
-		batchRequirements, err := proj.CollectBatchRequirements()
-		tui.CheckErr(err)
+	if debugEnvFile != "" {
+		additionalEnvFiles = append(additionalEnvFiles, debugEnvFile)
+	}
 
-		websiteRequirements, err := proj.CollectWebsiteRequirements()
-		tui.CheckErr(err)
+	// missing default env files are skipped, so any error is a parse error or a missing --env-file
+	envVariables, err := env.ReadLocalEnv(additionalEnvFiles...)
+	tui.CheckErr(err)
 
-		additionalEnvFiles := []string{}
+	tui.CheckErr(env.RejectSecretRefs(envVariables))
 
-		if debugEnvFile != "" {
-			additionalEnvFiles = append(additionalEnvFiles, envFile)
-		}
+	spec, err := collector.ServiceRequirementsToSpec(proj.Name, envVariables, serviceRequirements, batchRequirements, websiteRequirements)
+	tui.CheckErr(err)
 
-		envVariables, err := env.ReadLocalEnv(additionalEnvFiles...)
-		if err != nil && os.IsNotExist(err) {
-			if !os.IsNotExist(err) {
-				tui.CheckErr(err)
-			}
-			// If it doesn't exist set blank
-			envVariables = map[string]string{}
-		}
+	return spec, serviceRequirements, batchRequirements
+}
 
-		spec, err := collector.ServiceRequirementsToSpec(proj.Name, envVariables, serviceRequirements, batchRequirements, websiteRequirements)
+var specCmd = &cobra.Command{
+	Use:   "spec",
+	Short: "Output the nitric application cloud spec.",
+	Long:  `Output the nitric application cloud spec.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		fs := afero.NewOsFs()
+
+		proj, err := project.FromFile(fs, "")
 		tui.CheckErr(err)
 
+		spec, serviceRequirements, batchRequirements := collectProjectSpec(fs, proj)
+
 		migrationImageContexts, err := collector.GetMigrationImageBuildContexts(serviceRequirements, batchRequirements, fs)
 		tui.CheckErr(err)
 		// Build images from contexts and provide updates on the builds
@@ -174,6 +196,287 @@ var specCmd = &cobra.Command{
 	Aliases: []string{"spec"},
 }
 
+var graphFileExtensions = map[graph.Format]string{
+	graph.Format_Dot:     "dot",
+	graph.Format_Mermaid: "mmd",
+	graph.Format_SVG:     "svg",
+}
+
+var graphCmd = &cobra.Command{
+	Use:   "graph",
+	Short: "Output a resource dependency graph of the nitric application.",
+	Long: `Output a resource dependency graph of the nitric application.
+
+Services are linked to the resources they use, labelled with the permissions they request,
+and triggers (topics, schedules, apis etc.) are linked to the services that handle them.
+
+Use '-o -' to write the graph to stdout.`,
+	Example: `nitric debug graph
+nitric debug graph --format mermaid -o docs/architecture.mmd
+nitric debug graph --format svg -o architecture.svg`,
+	Run: func(cmd *cobra.Command, args []string) {
+		fs := afero.NewOsFs()
+
+		proj, err := project.FromFile(fs, "")
+		tui.CheckErr(err)
+
+		spec, _, _ := collectProjectSpec(fs, proj)
+
+		g, err := graph.FromSpec(proj.Name, spec)
+		tui.CheckErr(err)
+
+		format := graph.Format(debugGraphFormat)
+
+		output, err := g.Render(format)
+		tui.CheckErr(err)
+
+		if debugFile == "-" {
+			fmt.Print(string(output))
+			return
+		}
+
+		outputFile := debugFile
+		if outputFile == "" {
+			outputFile = fmt.Sprintf("./nitric-graph.%s", graphFileExtensions[format])
+		}
+
+		err = os.WriteFile(outputFile, output, 0o644)
+		tui.CheckErr(err)
+
+		fmt.Printf("Successfully outputted resource graph to %s\n", outputFile)
+	},
+	Args: cobra.ExactArgs(0),
+}
+
+func printPolicyReport(report *audit.Report) {
+	principalLength := len("principal")
+	resourceLength := len("resource")
+
+	for _, grant := range report.Grants {
+		principalLength = max(principalLength, len(grant.Principal))
+		resourceLength = max(resourceLength, len(grant.Resource)+len(grant.ResourceType.String())+3)
+	}
+
+	principalStyle := lipgloss.NewStyle().Bold(true).Foreground(tui.Colors.Blue).Width(principalLength + 1).PaddingRight(1).BorderRight(true).BorderStyle(lipgloss.NormalBorder()).BorderForeground(tui.Colors.Gray)
+	resourceStyle := lipgloss.NewStyle().Foreground(tui.Colors.Purple).Width(resourceLength + 2).PaddingLeft(1).PaddingRight(1).BorderRight(true).BorderStyle(lipgloss.NormalBorder()).BorderForeground(tui.Colors.Gray)
+	readStyle := lipgloss.NewStyle().Foreground(tui.Colors.Text).PaddingLeft(1)
+	writeStyle := lipgloss.NewStyle().Foreground(tui.Colors.Orange).PaddingLeft(1)
+
+	v := view.New()
+	v.Break()
+	v.Add("principal").WithStyle(principalStyle)
+	v.Add("resource").WithStyle(resourceStyle)
+	v.Addln("actions").WithStyle(readStyle)
+	v.Break()
+
+	for _, grant := range report.Grants {
+		v.Add(grant.Principal).WithStyle(principalStyle)
+		v.Addf("%s (%s)", grant.Resource, strings.ToLower(grant.ResourceType.String())).WithStyle(resourceStyle)
+
+		for _, action := range grant.Actions {
+			if audit.Access(action) == audit.AccessType_Write {
+				v.Add(action.String()).WithStyle(writeStyle)
+			} else {
+				v.Add(action.String()).WithStyle(readStyle)
+			}
+		}
+
+		v.Break()
+	}
+
+	fmt.Println(v.Render())
+
+	for _, finding := range report.Findings {
+		tui.Warning.Printfln("[%s] %s", finding.Type, finding.Message)
+	}
+}
+
+var policiesCmd = &cobra.Command{
+	Use:   "policies",
+	Short: "Audit the access each service has to the application's resources.",
+	Long: `Audit the access each service has to the application's resources.
+
+Outputs a principal x resource x action matrix for the application, with warnings for
+overly broad grants and for resources that are never read or never written.
+
+Use --fail-on with a rules file to fail when findings are present, e.g. in CI:
+
+  # findings that fail the audit (broad-grant, no-readers, no-writers, unreviewed-grant)
+  failOn:
+    - unreviewed-grant
+  # write access that has been reviewed, names and types may contain glob patterns
+  reviewed:
+    - principal: api
+      principalType: service
+      resource: uploads
+      resourceType: bucket
+      actions: [BucketFilePut]`,
+	Example: `nitric debug policies
+nitric debug policies --fail-on policy-rules.yaml -o policy-report.json`,
+	Run: func(cmd *cobra.Command, args []string) {
+		fs := afero.NewOsFs()
+
+		proj, err := project.FromFile(fs, "")
+		tui.CheckErr(err)
+
+		var rules *audit.Rules
+		// load rules up front, so an invalid rule file fails before the project is built
+		if debugFailOn != "" {
+			rules, err = audit.RulesFromFile(fs, debugFailOn)
+			tui.CheckErr(err)
+		}
+
+		spec, _, _ := collectProjectSpec(fs, proj)
+
+		report := audit.FromSpec(spec)
+
+		failures := []*audit.Finding{}
+		if rules != nil {
+			failures = rules.Apply(report)
+		}
+
+		printPolicyReport(report)
+
+		if debugFile != "" {
+			reportJson, err := json.MarshalIndent(report, "", "  ")
+			tui.CheckErr(err)
+
+			err = os.WriteFile(debugFile, reportJson, 0o644)
+			tui.CheckErr(err)
+
+			fmt.Printf("Successfully outputted policy report to %s\n", debugFile)
+		}
+
+		if len(failures) > 0 {
+			tui.CheckErr(fmt.Errorf("policy audit failed with %d finding(s)", len(failures)))
+		}
+	},
+	Args: cobra.ExactArgs(0),
+}
+
+func headerValue(headers map[string][]string, name string) string {
+	for key, values := range headers {
+		if strings.EqualFold(key, name) && len(values) > 0 {
+			return values[0]
+		}
+	}
+
+	return ""
+}
+
+// apiHistoryExamples returns examples of requests and responses for each API, from the dashboard's API history
+func apiHistoryExamples(projectDir string) (map[string][]collector.ApiExample, error) {
+	records, err := dashboard.ReadHistoryRecords[dashboard.ApiHistoryItem](projectDir, dashboard.API)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return map[string][]collector.ApiExample{}, nil
+		}
+
+		return nil, err
+	}
+
+	examples := map[string][]collector.ApiExample{}
+
+	for _, record := range records {
+		if record.Event.Request == nil || record.Event.Response == nil {
+			continue
+		}
+
+		// response bodies are stored as base64 encoded bytes
+		responseBody := []byte{}
+		if data, ok := record.Event.Response.Data.(string); ok {
+			responseBody, _ = base64.StdEncoding.DecodeString(data)
+		}
+
+		examples[record.Event.Api] = append(examples[record.Event.Api], collector.ApiExample{
+			Method:              record.Event.Request.Method,
+			Path:                record.Event.Request.Path,
+			RequestContentType:  headerValue(record.Event.Request.Headers, "Content-Type"),
+			RequestBody:         record.Event.Request.Body,
+			Status:              int(record.Event.Response.Status),
+			ResponseContentType: headerValue(record.Event.Response.Headers, "Content-Type"),
+			ResponseBody:        responseBody,
+		})
+	}
+
+	return examples, nil
+}
+
+var openApiCmd = &cobra.Command{
+	Use:   "openapi",
+	Short: "Output the OpenAPI documents for the nitric application's APIs.",
+	Long: `Output the OpenAPI documents for the nitric application's APIs.
+
+An OpenAPI document is written for each API, including its security schemes.
+Requests and responses from the local dashboard's API history are added as examples,
+along with schemas inferred from their JSON bodies.`,
+	Example: `nitric debug openapi
+nitric debug openapi --api main --format yaml -o ./docs`,
+	Run: func(cmd *cobra.Command, args []string) {
+		fs := afero.NewOsFs()
+
+		proj, err := project.FromFile(fs, "")
+		tui.CheckErr(err)
+
+		spec, _, _ := collectProjectSpec(fs, proj)
+
+		docs, err := collector.ApiDocumentsFromSpec(spec)
+		tui.CheckErr(err)
+
+		if debugApiName != "" {
+			doc, ok := docs[debugApiName]
+			if !ok {
+				tui.CheckErr(fmt.Errorf("api '%s' not found, available apis: %s", debugApiName, strings.Join(lo.Keys(docs), ", ")))
+			}
+
+			docs = map[string]*openapi3.T{debugApiName: doc}
+		}
+
+		if len(docs) == 0 {
+			tui.CheckErr(fmt.Errorf("no apis found in project"))
+		}
+
+		examples := map[string][]collector.ApiExample{}
+		if !debugNoExamples {
+			examples, err = apiHistoryExamples(proj.Directory)
+			tui.CheckErr(err)
+		}
+
+		outputDir := lo.Ternary(debugFile != "", debugFile, ".")
+
+		err = os.MkdirAll(outputDir, 0o755)
+		tui.CheckErr(err)
+
+		for apiName, doc := range docs {
+			collector.AddApiExamples(doc, examples[apiName])
+
+			docJson, err := json.MarshalIndent(doc, "", "  ")
+			tui.CheckErr(err)
+
+			output := docJson
+
+			if debugOpenApiFormat == "yaml" {
+				// convert via a generic value, so the document's custom JSON marshalling (e.g. extensions) is preserved
+				var docValue any
+
+				err = json.Unmarshal(docJson, &docValue)
+				tui.CheckErr(err)
+
+				output, err = yaml.Marshal(docValue)
+				tui.CheckErr(err)
+			}
+
+			outputFile := filepath.Join(outputDir, fmt.Sprintf("%s.openapi.%s", apiName, debugOpenApiFormat))
+
+			err = os.WriteFile(outputFile, output, 0o644)
+			tui.CheckErr(err)
+
+			fmt.Printf("Successfully outputted openapi document for api %s to %s\n", apiName, outputFile)
+		}
+	},
+	Args: cobra.ExactArgs(0),
+}
+
 func init() {
 	specCmd.Flags().StringVarP(&debugEnvFile, "env-file", "e", "", "--env-file config/.my-env")
 	specCmd.Flags().StringVarP(&debugFile, "output", "o", "", "--file my-example-spec.json")
@@ -182,6 +485,32 @@ func init() {
 	// Debug spec
 	debugCmd.AddCommand(specCmd)
 
+	graphCmd.Flags().StringVarP(&debugEnvFile, "env-file", "e", "", "--env-file config/.my-env")
+	graphCmd.Flags().StringVarP(&debugFile, "output", "o", "", "--output my-graph.dot")
+	graphCmd.Flags().VarP(pflagx.NewStringEnumVar(&debugGraphFormat, graph.Formats, string(graph.Format_Dot)), "format", "f", fmt.Sprintf("output format, one of %s", strings.Join(graph.Formats, ", ")))
+	graphCmd.Flags().BoolVar(&noBuilder, "no-builder", false, "don't create a buildx container")
+
+	// Debug graph
+	debugCmd.AddCommand(graphCmd)
+
+	policiesCmd.Flags().StringVarP(&debugEnvFile, "env-file", "e", "", "--env-file config/.my-env")
+	policiesCmd.Flags().StringVarP(&debugFile, "output", "o", "", "--output policy-report.json")
+	policiesCmd.Flags().StringVar(&debugFailOn, "fail-on", "", "--fail-on policy-rules.yaml")
+	policiesCmd.Flags().BoolVar(&noBuilder, "no-builder", false, "don't create a buildx container")
+
+	// Debug policies
+	debugCmd.AddCommand(policiesCmd)
+
+	openApiCmd.Flags().StringVarP(&debugEnvFile, "env-file", "e", "", "--env-file config/.my-env")
+	openApiCmd.Flags().StringVarP(&debugFile, "output", "o", "", "directory to write the documents to, defaults to the current directory")
+	openApiCmd.Flags().StringVar(&debugApiName, "api", "", "only output the document for the named api")
+	openApiCmd.Flags().VarP(pflagx.NewStringEnumVar(&debugOpenApiFormat, []string{"json", "yaml"}, "json"), "format", "f", "output format, one of json, yaml")
+	openApiCmd.Flags().BoolVar(&debugNoExamples, "no-examples", false, "don't add examples from the dashboard's api history")
+	openApiCmd.Flags().BoolVar(&noBuilder, "no-builder", false, "don't create a buildx container")
+
+	// Debug openapi
+	debugCmd.AddCommand(openApiCmd)
+
 	// Add Stack Commands
 	rootCmd.AddCommand(debugCmd)
 
@@ -0,0 +1,129 @@
+// Copyright Nitric Pty Ltd.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/spf13/cobra"
+
+	"github.com/nitrictech/cli/pkg/cloud/secrets"
+	"github.com/nitrictech/cli/pkg/env"
+	"github.com/nitrictech/cli/pkg/pflagx"
+	"github.com/nitrictech/cli/pkg/view/tui"
+)
+
+var (
+	envPrintStack  string
+	envPrintFile   string
+	envPrintReveal bool
+	envPrintFormat string
+)
+
+const maskedEnvSecret = "********"
+
+var envCmd = &cobra.Command{
+	Use:   "env",
+	Short: "Inspect the env variables of your project",
+	Long: `Inspect the env variables of your project.
+
+Env files are layered, later files override variables from earlier files:
+
+  .env
+  .env.local
+  .env.<stack>  (stack commands only)
+  --env-file
+
+Values can reference variables from earlier files with ${NAME}, and secrets in the local secret store with secret://name or secret://name/<version>.
+Secret references are resolved by nitric run and nitric start, stack and debug commands reject them.`,
+}
+
+var envPrintCmd = &cobra.Command{
+	Use:   "print",
+	Short: "Print the final value of each env variable and the file it came from",
+	Example: `nitric env print
+nitric env print --stack staging --env-file config/.my-env
+nitric env print --reveal --format json`,
+	Run: func(cmd *cobra.Command, args []string) {
+		additionalEnvFiles := []string{}
+
+		if envPrintFile != "" {
+			additionalEnvFiles = append(additionalEnvFiles, envPrintFile)
+		}
+
+		variables, err := env.ReadLayeredEnv(envPrintStack, additionalEnvFiles...)
+		tui.CheckErr(err)
+
+		secretService, err := secrets.NewSecretService()
+		tui.CheckErr(err)
+
+		for key, variable := range variables {
+			name, version, ok := env.ParseSecretRef(variable.Value)
+			if !ok {
+				continue
+			}
+
+			value, err := secretService.ResolveEnvRef(name, version)
+			if err != nil {
+				tui.Warning.Printfln("unable to resolve %s for %s: %s", variable.Value, key, err)
+				continue
+			}
+
+			if envPrintReveal {
+				variable.Value = value
+			} else {
+				variable.Value = maskedEnvSecret
+			}
+
+			variables[key] = variable
+		}
+
+		if envPrintFormat == "json" {
+			variablesJson, err := json.MarshalIndent(variables, "", "  ")
+			tui.CheckErr(err)
+
+			fmt.Println(string(variablesJson))
+
+			return
+		}
+
+		keys := make([]string, 0, len(variables))
+		for key := range variables {
+			keys = append(keys, key)
+		}
+
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			fmt.Printf("%s=%s %s\n", key, variables[key].Value, lipgloss.NewStyle().Foreground(tui.Colors.TextMuted).Render(fmt.Sprintf("(%s)", variables[key].Source)))
+		}
+	},
+	Args: cobra.ExactArgs(0),
+}
+
+func init() {
+	envPrintCmd.Flags().StringVarP(&envPrintStack, "stack", "s", "", "include the env file of a stack, e.g. --stack staging reads .env.staging")
+	envPrintCmd.Flags().StringVarP(&envPrintFile, "env-file", "e", "", "--env-file config/.my-env")
+	envPrintCmd.Flags().BoolVar(&envPrintReveal, "reveal", false, "print the values of referenced secrets instead of masking them")
+	envPrintCmd.Flags().VarP(pflagx.NewStringEnumVar(&envPrintFormat, []string{"text", "json"}, "text"), "format", "f", "output format, one of text, json")
+
+	envCmd.AddCommand(envPrintCmd)
+	rootCmd.AddCommand(envCmd)
+}
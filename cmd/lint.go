@@ -0,0 +1,125 @@
+// Copyright Nitric Pty Ltd.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+
+	"github.com/nitrictech/cli/pkg/lint"
+	"github.com/nitrictech/cli/pkg/pflagx"
+	"github.com/nitrictech/cli/pkg/project"
+	"github.com/nitrictech/cli/pkg/view/tui"
+	"github.com/nitrictech/cli/pkg/view/tui/components/view"
+	"github.com/nitrictech/cli/pkg/view/tui/fragments"
+)
+
+var (
+	lintFormat    string
+	lintListRules bool
+)
+
+var lintSeverityColors = map[lint.Severity]lipgloss.CompleteAdaptiveColor{
+	lint.Severity_Info:    tui.Colors.Blue,
+	lint.Severity_Warning: tui.Colors.Orange,
+	lint.Severity_Error:   tui.Colors.Red,
+}
+
+func printLintRules() {
+	v := view.New()
+
+	for _, rule := range lint.Rules() {
+		v.Add(rule.Name).WithStyle(lipgloss.NewStyle().Bold(true).Foreground(tui.Colors.Blue))
+		v.Addln(" [%s]", rule.DefaultSeverity).WithStyle(lipgloss.NewStyle().Foreground(tui.Colors.TextMuted))
+		v.Addln(rule.Description).WithStyle(lipgloss.NewStyle().MarginLeft(2))
+	}
+
+	fmt.Println(v.Render())
+}
+
+var lintCmd = &cobra.Command{
+	Use:   "lint",
+	Short: "Check your project for common issues",
+	Long: `Check your project for common issues, such as topics with no subscribers or unused secrets.
+
+Rule severities can be configured in nitric.yaml:
+
+  lint:
+    rules:
+      api-no-security: error
+      topic-no-subscribers: off
+
+Exits with a non-zero status if any issues with error severity are found.`,
+	Example: `nitric lint
+nitric lint --format json
+nitric lint --list`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if lintListRules {
+			printLintRules()
+			return
+		}
+
+		fs := afero.NewOsFs()
+
+		config, err := project.ConfigurationFromFile(fs, "")
+		tui.CheckErr(err)
+
+		proj, err := project.FromFile(fs, "")
+		tui.CheckErr(err)
+
+		spec, _, _ := collectProjectSpec(fs, proj)
+
+		issues, err := lint.Run(&lint.Context{
+			Config: config,
+			Spec:   spec,
+		})
+		tui.CheckErr(err)
+
+		if lintFormat == "json" {
+			issuesJson, err := json.MarshalIndent(issues, "", "  ")
+			tui.CheckErr(err)
+
+			fmt.Println(string(issuesJson))
+		} else {
+			for _, issue := range issues {
+				fmt.Println(fragments.CustomTag(string(issue.Severity), tui.Colors.White, lintSeverityColors[issue.Severity]), issue.Message, lipgloss.NewStyle().Foreground(tui.Colors.TextMuted).Render(fmt.Sprintf("(%s)", issue.Rule)))
+			}
+
+			if len(issues) == 0 {
+				fmt.Println("No issues found")
+			}
+		}
+
+		if lint.HasErrors(issues) {
+			tui.CheckErr(fmt.Errorf("lint found issues with error severity"))
+		}
+	},
+	Args: cobra.ExactArgs(0),
+}
+
+func init() {
+	lintCmd.Flags().StringVarP(&debugEnvFile, "env-file", "e", "", "--env-file config/.my-env")
+	lintCmd.Flags().VarP(pflagx.NewStringEnumVar(&lintFormat, []string{"text", "json"}, "text"), "format", "f", "output format, one of text, json")
+	lintCmd.Flags().BoolVar(&lintListRules, "list", false, "list the available lint rules")
+	lintCmd.Flags().BoolVar(&noBuilder, "no-builder", false, "don't create a buildx container")
+
+	rootCmd.AddCommand(lintCmd)
+}
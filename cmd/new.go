@@ -27,17 +27,35 @@ import (
 	"github.com/nitrictech/cli/pkg/view/tui/teax"
 )
 
-var force bool
+var (
+	force           bool
+	newPlaceholders map[string]string
+)
 
 var newCmd = &cobra.Command{
 	Use:   "new [projectName] [templateName]",
 	Short: "Create a new project",
-	Long:  `Creates a new Nitric project from a template.`,
+	Long: `Creates a new Nitric project from a template.
+
+In addition to the official templates, templates can be sourced from git repositories, local directories and tarballs
+configured in template-sources.yaml in the nitric config directory, e.g.
+
+  sources:
+    - name: acme
+      location: git::https://github.com/acme/nitric-templates.git?ref=main
+
+Each source must contain a cli-templates.yaml index, its templates are named <source>/<template>.
+Placeholders like {{project_name}} are substituted in the files and file names of the new project.
+Placeholders without a --placeholder value are prompted for when a template is chosen interactively, otherwise their defaults are used.
+Sources that can't be reached are skipped with a warning.`,
 	Example: `# For an interactive command that will ask the required questions
 nitric new
 
 # For a non-interactive command use the arguments.
-nitric new hello-world "official/TypeScript - Starter" `,
+nitric new hello-world "official/TypeScript - Starter"
+
+# Create a project from a custom template source, overriding a placeholder
+nitric new hello-world "acme/Go - API" --placeholder service_name=orders`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		projectName := ""
 		if len(args) >= 1 {
@@ -57,6 +75,7 @@ nitric new hello-world "official/TypeScript - Starter" `,
 			ProjectName:  projectName,
 			TemplateName: templateName,
 			Force:        force,
+			Placeholders: newPlaceholders,
 		})
 		tui.CheckErr(err)
 
@@ -72,5 +91,6 @@ nitric new hello-world "official/TypeScript - Starter" `,
 
 func init() {
 	newCmd.Flags().BoolVarP(&force, "force", "f", false, "force project creation, even in non-empty directories.")
+	newCmd.Flags().StringToStringVar(&newPlaceholders, "placeholder", map[string]string{}, "set a template placeholder value, e.g. --placeholder service_name=orders")
 	rootCmd.AddCommand(newCmd)
 }
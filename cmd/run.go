@@ -17,6 +17,7 @@
 package cmd
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
 	"os/signal"
@@ -31,6 +32,7 @@ import (
 
 	"github.com/nitrictech/cli/pkg/cloud"
 	"github.com/nitrictech/cli/pkg/cloud/gateway"
+	"github.com/nitrictech/cli/pkg/cloud/secrets"
 	"github.com/nitrictech/cli/pkg/dashboard"
 	docker "github.com/nitrictech/cli/pkg/docker"
 	"github.com/nitrictech/cli/pkg/env"
@@ -44,7 +46,43 @@ import (
 	"github.com/nitrictech/cli/pkg/view/tui/teax"
 )
 
-var runNoBrowser bool
+var (
+	runNoBrowser     bool
+	runDebugServices []string
+)
+
+// printDebugTargets adds attach configurations for the debugged services to the project's VS Code launch.json,
+// printing them instead when the existing launch.json can't be updated
+func printDebugTargets(fs afero.Fs, proj *project.Project, debugTargets []*project.DebugTarget) {
+	if len(debugTargets) == 0 {
+		return
+	}
+
+	for _, target := range debugTargets {
+		for _, port := range target.HostPorts {
+			tui.Info.Printfln("debugging %s with %s on localhost:%d", target.ServicePath, target.Debugger.Type, port)
+		}
+	}
+
+	launchFile := paths.VSCodeLaunchFile(proj.Directory)
+
+	err := project.WriteLaunchConfigurations(fs, launchFile, debugTargets)
+	if err == nil {
+		tui.Info.Printfln("added attach configurations to %s", launchFile)
+		return
+	}
+
+	tui.Warning.Printfln("%s, add these attach configurations manually:", err)
+
+	configurations := lo.FlatMap(debugTargets, func(target *project.DebugTarget, _ int) []map[string]any {
+		return target.LaunchConfigurations()
+	})
+
+	b, err := json.MarshalIndent(configurations, "", "  ")
+	tui.CheckErr(err)
+
+	fmt.Println(string(b))
+}
 
 var runCmd = &cobra.Command{
 	Use:         "run",
@@ -72,6 +110,11 @@ var runCmd = &cobra.Command{
 			tui.CheckErr(err)
 		}
 
+		secretService, err := secrets.NewSecretService()
+		tui.CheckErr(err)
+
+		tui.CheckErr(env.ResolveSecrets(loadEnv, secretService.ResolveEnvRef))
+
 		var tlsCredentials *gateway.TLSCredentials
 		if enableHttps {
 			createTlsCredentialsIfNotPresent(fs, proj.Directory)
@@ -91,6 +134,11 @@ var runCmd = &cobra.Command{
 		// Initialize the system service log logger
 		system.InitializeServiceLogger(proj.Directory)
 
+		debugTargets, err := proj.DebugTargets(runDebugServices, project.LaunchConfigurationPorts(fs, paths.VSCodeLaunchFile(proj.Directory)))
+		tui.CheckErr(err)
+
+		printDebugTargets(fs, proj, debugTargets)
+
 		teaOptions := []tea.ProgramOption{}
 		if isNonInteractive() {
 			teaOptions = append(teaOptions, tea.WithoutRenderer(), tea.WithInput(nil))
@@ -102,11 +150,13 @@ var runCmd = &cobra.Command{
 		go func() {
 			// Start the local cloud service analogues
 			localCloud, err = cloud.New(proj.Name, cloud.LocalCloudOptions{
-				TLSCredentials:  tlsCredentials,
-				LogWriter:       logWriter,
-				LocalConfig:     proj.LocalConfig,
-				MigrationRunner: project.BuildAndRunMigrations,
-				LocalCloudMode:  cloud.RunMode,
+				TLSCredentials:    tlsCredentials,
+				LogWriter:         logWriter,
+				LocalConfig:       proj.LocalConfig,
+				ProjectDirectory:  proj.Directory,
+				MigrationRunner:   project.BuildAndRunMigrations,
+				LocalCloudMode:    cloud.RunMode,
+				ValidateContracts: validateContracts,
 			})
 			tui.CheckErr(err)
 			runView.Send(local.LocalCloudStartStatusMsg{Status: local.Done})
@@ -115,6 +165,8 @@ var runCmd = &cobra.Command{
 		_, err = runView.Run()
 		tui.CheckErr(err)
 
+		warnSqlVersionMismatch(fs, proj, localCloud)
+
 		// Start dashboard
 		dash, err := dashboard.New(startNoBrowser, localCloud, proj)
 		tui.CheckErr(err)
@@ -199,8 +251,10 @@ var runCmd = &cobra.Command{
 			}
 		}()
 
+		serviceEnv := withContainerEnv(localCloud, loadEnv)
+
 		go func() {
-			err := proj.RunServices(localCloud, stopChan, updatesChan, loadEnv)
+			err := proj.RunServices(localCloud, stopChan, updatesChan, serviceEnv, debugTargets)
 			if err != nil {
 				localCloud.Stop()
 
@@ -209,7 +263,7 @@ var runCmd = &cobra.Command{
 		}()
 
 		go func() {
-			err := proj.RunBatches(localCloud, stopChan, updatesChan, loadEnv)
+			err := proj.RunBatches(localCloud, stopChan, updatesChan, serviceEnv)
 			if err != nil {
 				localCloud.Stop()
 
@@ -239,7 +293,7 @@ var runCmd = &cobra.Command{
 			}
 		})
 
-		allUpdates := lo.FanIn(10, updatesChan, systemChan)
+		allUpdates := lo.FanIn(10, updatesChan, systemChan, containerLogUpdates(localCloud))
 
 		// non-interactive environment
 		if isNonInteractive() {
@@ -267,7 +321,7 @@ var runCmd = &cobra.Command{
 					// Write log to file
 					level := logrus.InfoLevel
 
-					if update.Status == project.ServiceRunStatus_Error {
+					if update.Status == project.ServiceRunStatus_Error || update.Status == project.ServiceRunStatus_OOMKilled {
 						level = logrus.ErrorLevel
 					}
 
@@ -293,7 +347,9 @@ var runCmd = &cobra.Command{
 func init() {
 	runCmd.Flags().StringVarP(&envFile, "env-file", "e", "", "--env-file config/.my-env")
 	runCmd.Flags().BoolVar(&enableHttps, "https-preview", false, "enable https support for local APIs (preview feature)")
+	runCmd.Flags().BoolVar(&validateContracts, "validate-contracts", false, "validate API requests and responses against their OpenAPI contracts")
 	runCmd.Flags().BoolVar(&noBuilder, "no-builder", false, "don't create a buildx container")
+	runCmd.Flags().StringSliceVar(&runDebugServices, "debug", []string{}, "run a service with a debugger attachable on a published port, by service name, file path or glob, e.g. --debug services/api.ts")
 	runCmd.PersistentFlags().BoolVar(
 		&runNoBrowser,
 		"no-browser",
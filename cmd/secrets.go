@@ -0,0 +1,287 @@
+// Copyright Nitric Pty Ltd.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/samber/lo"
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+
+	"github.com/nitrictech/cli/pkg/cloud/secrets"
+	"github.com/nitrictech/cli/pkg/pflagx"
+	"github.com/nitrictech/cli/pkg/project"
+	"github.com/nitrictech/cli/pkg/view/tui"
+	secretspb "github.com/nitrictech/nitric/core/pkg/proto/secrets/v1"
+)
+
+var (
+	secretsGetVersion      string
+	secretsDeleteVersion   string
+	secretsRollbackVersion string
+	secretsFormat          string
+)
+
+var secretsCmd = &cobra.Command{
+	Use:     "secrets",
+	Aliases: []string{"secret"},
+	Short:   "Manage the local secret versions of your project",
+	Long:    `Manage the secret versions used by nitric start and nitric run, without the local dashboard.`,
+}
+
+// localSecretService returns the secret service of the project in the current directory
+func localSecretService() *secrets.DevSecretService {
+	_, err := project.ConfigurationFromFile(afero.NewOsFs(), "")
+	tui.CheckErr(err)
+
+	secretService, err := secrets.NewSecretService()
+	tui.CheckErr(err)
+
+	return secretService
+}
+
+// printSecretsJson prints the value as JSON when the json format is selected, returning false otherwise
+func printSecretsJson(value any) bool {
+	if secretsFormat != "json" {
+		return false
+	}
+
+	valueJson, err := json.MarshalIndent(value, "", "  ")
+	tui.CheckErr(err)
+
+	fmt.Println(string(valueJson))
+
+	return true
+}
+
+type secretVersionOutput struct {
+	Secret  string `json:"secret"`
+	Version string `json:"version"`
+	Value   string `json:"value,omitempty"`
+}
+
+// secretVersionListOutput is a listed version of a secret, values are left out so listings can't leak them, use secrets get to print a value
+type secretVersionListOutput struct {
+	Version   string               `json:"version"`
+	Latest    bool                 `json:"latest"`
+	CreatedAt string               `json:"createdAt"`
+	State     secrets.VersionState `json:"state"`
+}
+
+var secretsPutCmd = &cobra.Command{
+	Use:   "put [secretName] [value]",
+	Short: "Store a new version of a secret",
+	Long: `Store a new version of a secret and make it the latest version.
+
+The value is read from stdin when it isn't provided or is -, a single trailing newline is removed.`,
+	Example: `nitric secrets put api-key my-value
+echo my-value | nitric secrets put api-key
+nitric secrets put api-key - < key.txt`,
+	Run: func(cmd *cobra.Command, args []string) {
+		secretService := localSecretService()
+
+		var value []byte
+
+		if len(args) == 2 && args[1] != "-" {
+			value = []byte(args[1])
+		} else {
+			stdin, err := io.ReadAll(os.Stdin)
+			tui.CheckErr(err)
+
+			value = []byte(strings.TrimSuffix(strings.TrimSuffix(string(stdin), "\n"), "\r"))
+		}
+
+		resp, err := secretService.Put(context.Background(), &secretspb.SecretPutRequest{
+			Secret: &secretspb.Secret{Name: args[0]},
+			Value:  value,
+		})
+		tui.CheckErr(err)
+
+		output := secretVersionOutput{Secret: args[0], Version: resp.SecretVersion.Version}
+
+		if !printSecretsJson(output) {
+			fmt.Printf("Stored version %s of secret %s\n", output.Version, output.Secret)
+		}
+	},
+	Args: cobra.RangeArgs(1, 2),
+}
+
+var secretsGetCmd = &cobra.Command{
+	Use:   "get [secretName]",
+	Short: "Print the value of a secret version",
+	Example: `nitric secrets get api-key
+nitric secrets get api-key --version 6b7c8d9e-...`,
+	Run: func(cmd *cobra.Command, args []string) {
+		secretService := localSecretService()
+
+		resp, err := secretService.Access(context.Background(), &secretspb.SecretAccessRequest{
+			SecretVersion: &secretspb.SecretVersion{
+				Secret:  &secretspb.Secret{Name: args[0]},
+				Version: secretsGetVersion,
+			},
+		})
+		tui.CheckErr(err)
+
+		output := secretVersionOutput{Secret: args[0], Version: resp.SecretVersion.Version, Value: string(resp.Value)}
+
+		if !printSecretsJson(output) {
+			fmt.Println(output.Value)
+		}
+	},
+	Args: cobra.ExactArgs(1),
+}
+
+var secretsListCmd = &cobra.Command{
+	Use:   "list [secretName]",
+	Short: "List the secrets of the project, or the versions of a secret",
+	Long: `List the secrets of the project, or the versions of a secret.
+
+Values aren't listed, use nitric secrets get to print the value of a version.`,
+	Example: `nitric secrets list
+nitric secrets list api-key --format json`,
+	Run: func(cmd *cobra.Command, args []string) {
+		secretService := localSecretService()
+
+		if len(args) == 0 {
+			names, err := secretService.Secrets()
+			tui.CheckErr(err)
+
+			if !printSecretsJson(names) {
+				for _, name := range names {
+					fmt.Println(name)
+				}
+			}
+
+			return
+		}
+
+		versions, err := secretService.List(context.Background(), args[0])
+		tui.CheckErr(err)
+
+		output := lo.Map(versions, func(version secrets.SecretVersion, _ int) secretVersionListOutput {
+			return secretVersionListOutput{Version: version.Version, Latest: version.Latest, CreatedAt: version.CreatedAt, State: version.State}
+		})
+
+		if printSecretsJson(output) {
+			return
+		}
+
+		for _, version := range versions {
+			latest := ""
+			if version.Latest {
+				latest = lipgloss.NewStyle().Foreground(tui.Colors.Green).Render(" latest")
+			}
+
+			if version.State != secrets.VersionState_Enabled {
+				latest = lipgloss.NewStyle().Foreground(tui.Colors.Red).Render(" " + string(version.State))
+			}
+
+			fmt.Printf("%s %s%s\n", version.Version, lipgloss.NewStyle().Foreground(tui.Colors.TextMuted).Render(version.CreatedAt), latest)
+		}
+	},
+	Args: cobra.MaximumNArgs(1),
+}
+
+var secretsDeleteCmd = &cobra.Command{
+	Use:     "delete [secretName]",
+	Short:   "Delete a version of a secret",
+	Long:    `Delete a version of a secret, if it is the latest version the newest enabled version becomes the latest.`,
+	Example: `nitric secrets delete api-key --version 6b7c8d9e-...`,
+	Run: func(cmd *cobra.Command, args []string) {
+		secretService := localSecretService()
+
+		tui.CheckErr(secretService.Delete(context.Background(), args[0], secretsDeleteVersion))
+
+		if !printSecretsJson(secretVersionOutput{Secret: args[0], Version: secretsDeleteVersion}) {
+			fmt.Printf("Deleted version %s of secret %s\n", secretsDeleteVersion, args[0])
+		}
+	},
+	Args: cobra.ExactArgs(1),
+}
+
+// newSecretsStateCmd returns a command that changes the state of a secret version
+func newSecretsStateCmd(use string, short string, long string, state secrets.VersionState) *cobra.Command {
+	var version string
+
+	cmd := &cobra.Command{
+		Use:     use + " [secretName]",
+		Short:   short,
+		Long:    long,
+		Example: fmt.Sprintf("nitric secrets %s api-key --version 6b7c8d9e-...", use),
+		Run: func(cmd *cobra.Command, args []string) {
+			secretService := localSecretService()
+
+			tui.CheckErr(secretService.SetState(context.Background(), args[0], version, state))
+
+			if !printSecretsJson(secretVersionOutput{Secret: args[0], Version: version}) {
+				fmt.Printf("Version %s of secret %s is %s\n", version, args[0], state)
+			}
+		},
+		Args: cobra.ExactArgs(1),
+	}
+
+	cmd.Flags().StringVar(&version, "version", "", "the version of the secret")
+	tui.CheckErr(cmd.MarkFlagRequired("version"))
+
+	return cmd
+}
+
+var (
+	secretsEnableCmd  = newSecretsStateCmd("enable", "Enable a disabled secret version", `Enable a disabled secret version so it can be accessed again, destroyed versions can't be enabled.`, secrets.VersionState_Enabled)
+	secretsDisableCmd = newSecretsStateCmd("disable", "Disable a secret version", `Disable a secret version so it can't be accessed, if it is the latest version the newest enabled version becomes the latest.`, secrets.VersionState_Disabled)
+	secretsDestroyCmd = newSecretsStateCmd("destroy", "Destroy the value of a secret version", `Permanently remove the value of a secret version, the version is kept in the version history.`, secrets.VersionState_Destroyed)
+)
+
+var secretsRollbackCmd = &cobra.Command{
+	Use:   "rollback [secretName]",
+	Short: "Make a previous version of a secret the latest version",
+	Long:  `Make a previous version of a secret the latest version, the newest enabled version before the current latest version is used unless --version is provided.`,
+	Example: `nitric secrets rollback api-key
+nitric secrets rollback api-key --version 6b7c8d9e-...`,
+	Run: func(cmd *cobra.Command, args []string) {
+		secretService := localSecretService()
+
+		version, err := secretService.Rollback(context.Background(), args[0], secretsRollbackVersion)
+		tui.CheckErr(err)
+
+		if !printSecretsJson(secretVersionOutput{Secret: args[0], Version: version}) {
+			fmt.Printf("Version %s is now the latest version of secret %s\n", version, args[0])
+		}
+	},
+	Args: cobra.ExactArgs(1),
+}
+
+func init() {
+	for _, cmd := range []*cobra.Command{secretsPutCmd, secretsGetCmd, secretsListCmd, secretsDeleteCmd, secretsEnableCmd, secretsDisableCmd, secretsDestroyCmd, secretsRollbackCmd} {
+		cmd.Flags().VarP(pflagx.NewStringEnumVar(&secretsFormat, []string{"text", "json"}, "text"), "format", "f", "output format, one of text, json")
+		secretsCmd.AddCommand(cmd)
+	}
+
+	secretsGetCmd.Flags().StringVar(&secretsGetVersion, "version", "latest", "the version of the secret to get")
+	secretsDeleteCmd.Flags().StringVar(&secretsDeleteVersion, "version", "", "the version of the secret to delete")
+	tui.CheckErr(secretsDeleteCmd.MarkFlagRequired("version"))
+	secretsRollbackCmd.Flags().StringVar(&secretsRollbackVersion, "version", "", "the version of the secret to make the latest version")
+
+	rootCmd.AddCommand(secretsCmd)
+}
@@ -233,7 +233,7 @@ var stackUpdateCmd = &cobra.Command{
 			additionalEnvFiles = append(additionalEnvFiles, envFile)
 		}
 
-		envVariables, err := env.ReadLocalEnv(additionalEnvFiles...)
+		envVariables, err := env.ReadStackEnv(stackConfig.Name, additionalEnvFiles...)
 		if err != nil && os.IsNotExist(err) {
 			if !os.IsNotExist(err) {
 				tui.CheckErr(err)
@@ -242,6 +242,8 @@ var stackUpdateCmd = &cobra.Command{
 			envVariables = map[string]string{}
 		}
 
+		tui.CheckErr(env.RejectSecretRefs(envVariables))
+
 		// Allow Beta providers to be run if 'beta-providers' is enabled in preview flags
 		if slices.Contains(proj.Preview, preview.Feature_BetaProviders) {
 			envVariables["NITRIC_BETA_PROVIDERS"] = "true"
@@ -489,7 +491,7 @@ nitric stack down -s aws -y`,
 			additionalEnvFiles = append(additionalEnvFiles, envFile)
 		}
 
-		envVariables, err := env.ReadLocalEnv(additionalEnvFiles...)
+		envVariables, err := env.ReadStackEnv(stackConfig.Name, additionalEnvFiles...)
 		if err != nil && os.IsNotExist(err) {
 			if !os.IsNotExist(err) {
 				tui.CheckErr(err)
@@ -498,6 +500,8 @@ nitric stack down -s aws -y`,
 			envVariables = map[string]string{}
 		}
 
+		tui.CheckErr(env.RejectSecretRefs(envVariables))
+
 		// Allow Beta providers to be run if 'beta-providers' is enabled in preview flags
 		if slices.Contains(proj.Preview, preview.Feature_BetaProviders) {
 			envVariables["NITRIC_BETA_PROVIDERS"] = "true"
@@ -39,7 +39,9 @@ import (
 	"github.com/spf13/cobra"
 
 	"github.com/nitrictech/cli/pkg/cloud"
+	"github.com/nitrictech/cli/pkg/cloud/containers"
 	"github.com/nitrictech/cli/pkg/cloud/gateway"
+	"github.com/nitrictech/cli/pkg/cloud/secrets"
 	"github.com/nitrictech/cli/pkg/dashboard"
 	"github.com/nitrictech/cli/pkg/env"
 	"github.com/nitrictech/cli/pkg/paths"
@@ -53,8 +55,9 @@ import (
 )
 
 var (
-	startNoBrowser bool
-	enableHttps    bool
+	startNoBrowser    bool
+	enableHttps       bool
+	validateContracts bool
 )
 
 // generateSelfSignedCert generates a self-signed X.509 certificate and returns the PEM-encoded certificate and private key
@@ -137,6 +140,29 @@ func runInGoroutine(
 	}()
 }
 
+// withContainerEnv returns the environment of services with the connection details of the project's auxiliary containers,
+// variables from env files take precedence
+func withContainerEnv(localCloud *cloud.LocalCloud, env map[string]string) map[string]string {
+	return lo.Assign(localCloud.Containers.ServiceEnv(), env)
+}
+
+// containerLogUpdates forwards the output of the project's auxiliary containers as run updates, so it's shown and logged with the service output
+func containerLogUpdates(localCloud *cloud.LocalCloud) chan project.ServiceRunUpdate {
+	updates := make(chan project.ServiceRunUpdate)
+
+	localCloud.Containers.SubscribeToLogs(func(log containers.ContainerLog) {
+		// many images log to stderr, so it isn't treated as an error
+		updates <- project.ServiceRunUpdate{
+			ServiceName: log.Name,
+			Label:       "container:" + log.Name,
+			Status:      project.ServiceRunStatus_Running,
+			Message:     log.Message,
+		}
+	})
+
+	return updates
+}
+
 var startCmd = &cobra.Command{
 	Use:         "start",
 	Short:       "Run nitric services locally for development and testing",
@@ -167,6 +193,11 @@ var startCmd = &cobra.Command{
 			tui.CheckErr(err)
 		}
 
+		secretService, err := secrets.NewSecretService()
+		tui.CheckErr(err)
+
+		tui.CheckErr(env.ResolveSecrets(localEnv, secretService.ResolveEnvRef))
+
 		var tlsCredentials *gateway.TLSCredentials
 		if enableHttps {
 			createTlsCredentialsIfNotPresent(fs, proj.Directory)
@@ -197,11 +228,13 @@ var startCmd = &cobra.Command{
 		go func() {
 			// Start the local cloud service analogues
 			localCloud, err = cloud.New(proj.Name, cloud.LocalCloudOptions{
-				TLSCredentials:  tlsCredentials,
-				LogWriter:       logWriter,
-				LocalConfig:     proj.LocalConfig,
-				MigrationRunner: project.BuildAndRunMigrations,
-				LocalCloudMode:  cloud.StartMode,
+				TLSCredentials:    tlsCredentials,
+				LogWriter:         logWriter,
+				LocalConfig:       proj.LocalConfig,
+				ProjectDirectory:  proj.Directory,
+				MigrationRunner:   project.BuildAndRunMigrations,
+				LocalCloudMode:    cloud.StartMode,
+				ValidateContracts: validateContracts,
 			})
 			tui.CheckErr(err)
 			runView.Send(local.LocalCloudStartStatusMsg{Status: local.Done})
@@ -210,6 +243,8 @@ var startCmd = &cobra.Command{
 		_, err = runView.Run()
 		tui.CheckErr(err)
 
+		warnSqlVersionMismatch(fs, proj, localCloud)
+
 		// Start dashboard
 		dash, err := dashboard.New(startNoBrowser, localCloud, proj)
 		tui.CheckErr(err)
@@ -236,9 +271,11 @@ var startCmd = &cobra.Command{
 			}
 		}()
 
-		runInGoroutine(proj.RunServicesWithCommand, localCloud, stopChan, updatesChan, localEnv)
+		serviceEnv := withContainerEnv(localCloud, localEnv)
+
+		runInGoroutine(proj.RunServicesWithCommand, localCloud, stopChan, updatesChan, serviceEnv)
 
-		runInGoroutine(proj.RunBatchesWithCommand, localCloud, stopChan, updatesChan, localEnv)
+		runInGoroutine(proj.RunBatchesWithCommand, localCloud, stopChan, updatesChan, serviceEnv)
 
 		runInGoroutine(proj.RunWebsitesWithCommand, localCloud, stopChan, updatesChan, localEnv)
 
@@ -263,7 +300,7 @@ var startCmd = &cobra.Command{
 			}
 		})
 
-		allUpdates := lo.FanIn(10, updatesChan, systemChan)
+		allUpdates := lo.FanIn(10, updatesChan, systemChan, containerLogUpdates(localCloud))
 
 		// non-interactive environment
 		if isNonInteractive() {
@@ -291,7 +328,7 @@ var startCmd = &cobra.Command{
 					// Write log to file
 					level := logrus.InfoLevel
 
-					if update.Status == project.ServiceRunStatus_Error {
+					if update.Status == project.ServiceRunStatus_Error || update.Status == project.ServiceRunStatus_OOMKilled {
 						level = logrus.ErrorLevel
 					}
 
@@ -319,6 +356,7 @@ var startCmd = &cobra.Command{
 func init() {
 	startCmd.Flags().StringVarP(&envFile, "env-file", "e", "", "--env-file config/.my-env")
 	startCmd.Flags().BoolVar(&enableHttps, "https-preview", false, "enable https support for local APIs (preview feature)")
+	startCmd.Flags().BoolVar(&validateContracts, "validate-contracts", false, "validate API requests and responses against their OpenAPI contracts")
 	startCmd.PersistentFlags().BoolVar(
 		&startNoBrowser,
 		"no-browser",
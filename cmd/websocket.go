@@ -0,0 +1,159 @@
+// Copyright Nitric Pty Ltd.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+
+	"github.com/nitrictech/cli/pkg/paths"
+	"github.com/nitrictech/cli/pkg/project"
+	"github.com/nitrictech/cli/pkg/view/tui"
+	"github.com/nitrictech/cli/pkg/view/tui/fragments"
+	"github.com/nitrictech/cli/pkg/wssession"
+)
+
+var websocketUrl string
+
+var websocketCmd = &cobra.Command{
+	Use:     "websocket",
+	Aliases: []string{"ws"},
+	Short:   "Run scripted websocket sessions",
+	Long: fmt.Sprintf(`Run scripted websocket sessions against a running local project.
+
+Sessions are saved from the dashboard, or written by hand, to the %s directory of the project, e.g.
+
+  socket: chat
+  query:
+    user: test
+  steps:
+    - send: hello
+      repeat: 3
+    - expect: hello
+      match: contains
+      repeat: 3
+      timeout: 2s`, paths.NitricWebsocketSessionsDir("")),
+}
+
+// websocketSessionAddress resolves the gateway address of a session's socket, sockets must have a fixed port in local.nitric.yaml unless --url is provided for the session
+func websocketSessionAddress(proj *project.Project, session *wssession.Session) (string, error) {
+	if websocketUrl != "" {
+		return websocketUrl, nil
+	}
+
+	if config, ok := proj.LocalConfig.Websockets[session.Socket]; ok && config.Port != 0 {
+		return fmt.Sprintf("ws://localhost:%d", config.Port), nil
+	}
+
+	return "", fmt.Errorf("socket %s has no port configured in local.nitric.yaml, provide the socket address with --url", session.Socket)
+}
+
+var websocketRunCmd = &cobra.Command{
+	Use:   "run [session...]",
+	Short: "Run saved websocket sessions, or all sessions if none are provided",
+	Long:  `Run saved websocket sessions against the sockets of a project running with nitric start or nitric run.`,
+	Example: `nitric websocket run
+nitric websocket run chat-echo --url ws://localhost:4001`,
+	Run: func(cmd *cobra.Command, args []string) {
+		fs := afero.NewOsFs()
+
+		proj, err := project.FromFile(fs, "")
+		tui.CheckErr(err)
+
+		if websocketUrl != "" && len(args) != 1 {
+			tui.CheckErr(fmt.Errorf("--url can only be used when running a single named session"))
+		}
+
+		sessions := []*wssession.Session{}
+
+		if len(args) == 0 {
+			sessions, err = wssession.List(fs, proj.Directory)
+			tui.CheckErr(err)
+		}
+
+		for _, name := range args {
+			session, err := wssession.Load(fs, proj.Directory, name)
+			tui.CheckErr(err)
+
+			sessions = append(sessions, session)
+		}
+
+		if len(sessions) == 0 {
+			tui.Warning.Printfln("No websocket sessions found in %s", paths.NitricWebsocketSessionsDir(proj.Directory))
+			return
+		}
+
+		failed := 0
+
+		for _, session := range sessions {
+			address, err := websocketSessionAddress(proj, session)
+			tui.CheckErr(err)
+
+			result, err := wssession.Run(address, session)
+			tui.CheckErr(err)
+
+			tag := fragments.CustomTag("pass", tui.Colors.White, tui.Colors.Green)
+			if !result.Passed {
+				tag = fragments.CustomTag("fail", tui.Colors.White, tui.Colors.Red)
+				failed++
+			}
+
+			fmt.Println(tag, session.Name, lipgloss.NewStyle().Foreground(tui.Colors.TextMuted).Render(fmt.Sprintf("(%s)", result.Duration.Round(time.Millisecond))))
+
+			for _, step := range result.Steps {
+				if !step.Passed {
+					fmt.Printf("  %s: %s\n", step.Step, step.Error)
+				}
+			}
+		}
+
+		if failed > 0 {
+			tui.CheckErr(fmt.Errorf("%d of %d websocket sessions failed", failed, len(sessions)))
+		}
+	},
+}
+
+var websocketListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the saved websocket sessions of the project",
+	Run: func(cmd *cobra.Command, args []string) {
+		fs := afero.NewOsFs()
+
+		proj, err := project.FromFile(fs, "")
+		tui.CheckErr(err)
+
+		sessions, err := wssession.List(fs, proj.Directory)
+		tui.CheckErr(err)
+
+		for _, session := range sessions {
+			fmt.Printf("%s %s\n", session.Name, lipgloss.NewStyle().Foreground(tui.Colors.TextMuted).Render(fmt.Sprintf("(socket: %s, %d steps)", session.Socket, len(session.Steps))))
+		}
+	},
+	Args: cobra.ExactArgs(0),
+}
+
+func init() {
+	websocketRunCmd.Flags().StringVar(&websocketUrl, "url", "", "the websocket address of the named session's socket, e.g. ws://localhost:4001")
+
+	websocketCmd.AddCommand(websocketRunCmd)
+	websocketCmd.AddCommand(websocketListCmd)
+	rootCmd.AddCommand(websocketCmd)
+}
@@ -0,0 +1,63 @@
+// Copyright Nitric Pty Ltd.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/nitrictech/cli/pkg/project"
+	"github.com/nitrictech/cli/pkg/project/localconfig"
+	"github.com/nitrictech/cli/pkg/project/stack"
+	"github.com/nitrictech/cli/pkg/project/yamlschema"
+)
+
+// writes the JSON Schemas of the nitric configuration files to the given directory
+func main() {
+	if len(os.Args) != 2 {
+		log.Fatal("usage: schemagen <output directory>")
+	}
+
+	outDir := os.Args[1]
+
+	schemas := map[string]*yamlschema.Schema{
+		"nitric.schema.json":       project.ConfigurationSchema(),
+		"local.nitric.schema.json": localconfig.LocalConfigurationSchema(),
+	}
+
+	for _, provider := range stack.ProviderNames() {
+		schemas["stack."+strings.TrimPrefix(provider, "nitric/")+".schema.json"] = stack.ConfigSchema(provider)
+	}
+
+	if err := os.MkdirAll(outDir, 0o755); err != nil {
+		log.Fatal(err)
+	}
+
+	for name, schema := range schemas {
+		schemaJson, err := json.MarshalIndent(schema, "", "  ")
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		if err := os.WriteFile(filepath.Join(outDir, name), append(schemaJson, '\n'), 0o600); err != nil {
+			log.Fatal(err)
+		}
+	}
+}
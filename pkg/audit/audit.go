@@ -0,0 +1,294 @@
+// Copyright Nitric Pty Ltd.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audit
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/samber/lo"
+
+	deploymentspb "github.com/nitrictech/nitric/core/pkg/proto/deployments/v1"
+	resourcespb "github.com/nitrictech/nitric/core/pkg/proto/resources/v1"
+)
+
+type AccessType string
+
+const (
+	AccessType_Read  AccessType = "read"
+	AccessType_Write AccessType = "write"
+)
+
+// actionAccess classifies each policy action as granting read or write access to its resource
+var actionAccess = map[resourcespb.Action]AccessType{
+	resourcespb.Action_BucketFileList:      AccessType_Read,
+	resourcespb.Action_BucketFileGet:       AccessType_Read,
+	resourcespb.Action_BucketFilePut:       AccessType_Write,
+	resourcespb.Action_BucketFileDelete:    AccessType_Write,
+	resourcespb.Action_TopicPublish:        AccessType_Write,
+	resourcespb.Action_KeyValueStoreRead:   AccessType_Read,
+	resourcespb.Action_KeyValueStoreWrite:  AccessType_Write,
+	resourcespb.Action_KeyValueStoreDelete: AccessType_Write,
+	resourcespb.Action_SecretPut:           AccessType_Write,
+	resourcespb.Action_SecretAccess:        AccessType_Read,
+	resourcespb.Action_WebsocketManage:     AccessType_Write,
+	resourcespb.Action_QueueEnqueue:        AccessType_Write,
+	resourcespb.Action_QueueDequeue:        AccessType_Read,
+	resourcespb.Action_JobSubmit:           AccessType_Write,
+}
+
+// destructiveActions are actions that remove data, which are considered broad when granted on every resource of a type
+var destructiveActions = []resourcespb.Action{
+	resourcespb.Action_BucketFileDelete,
+	resourcespb.Action_KeyValueStoreDelete,
+}
+
+// Access returns whether an action grants read or write access
+func Access(action resourcespb.Action) AccessType {
+	if access, ok := actionAccess[action]; ok {
+		return access
+	}
+
+	// treat unknown actions as writes, so they're never silently approved
+	return AccessType_Write
+}
+
+// Grant is the set of actions a single principal may perform on a single resource
+type Grant struct {
+	Principal     string
+	PrincipalType resourcespb.ResourceType
+	Resource      string
+	ResourceType  resourcespb.ResourceType
+	Actions       []resourcespb.Action
+}
+
+func (g *Grant) MarshalJSON() ([]byte, error) {
+	return json.Marshal(map[string]any{
+		"principal":     g.Principal,
+		"principalType": typeName(g.PrincipalType),
+		"resource":      g.Resource,
+		"resourceType":  typeName(g.ResourceType),
+		"actions":       g.ActionNames(),
+	})
+}
+
+// ActionNames returns the names of the granted actions
+func (g *Grant) ActionNames() []string {
+	return lo.Map(g.Actions, func(action resourcespb.Action, _ int) string {
+		return action.String()
+	})
+}
+
+// WriteActions returns the granted actions that provide write access
+func (g *Grant) WriteActions() []resourcespb.Action {
+	return lo.Filter(g.Actions, func(action resourcespb.Action, _ int) bool {
+		return Access(action) == AccessType_Write
+	})
+}
+
+type FindingType string
+
+const (
+	FindingType_BroadGrant      FindingType = "broad-grant"
+	FindingType_NoReaders       FindingType = "no-readers"
+	FindingType_NoWriters       FindingType = "no-writers"
+	FindingType_UnreviewedGrant FindingType = "unreviewed-grant"
+)
+
+// Finding is a potential issue identified in the application's policies
+type Finding struct {
+	Type         FindingType `json:"type"`
+	Principal    string      `json:"principal,omitempty"`
+	Resource     string      `json:"resource,omitempty"`
+	ResourceType string      `json:"resourceType,omitempty"`
+	Message      string      `json:"message"`
+}
+
+// Report is a least-privilege audit of the policies in a deployment spec
+type Report struct {
+	Grants   []*Grant   `json:"grants"`
+	Findings []*Finding `json:"findings"`
+}
+
+func typeName(t resourcespb.ResourceType) string {
+	return strings.ToLower(t.String())
+}
+
+func (r *Report) grant(principal *resourcespb.ResourceIdentifier, resource *resourcespb.ResourceIdentifier) *Grant {
+	grant, exists := lo.Find(r.Grants, func(g *Grant) bool {
+		return g.Principal == principal.Name && g.PrincipalType == principal.Type && g.Resource == resource.Name && g.ResourceType == resource.Type
+	})
+
+	if !exists {
+		grant = &Grant{
+			Principal:     principal.Name,
+			PrincipalType: principal.Type,
+			Resource:      resource.Name,
+			ResourceType:  resource.Type,
+			Actions:       []resourcespb.Action{},
+		}
+		r.Grants = append(r.Grants, grant)
+	}
+
+	return grant
+}
+
+// checkBroadGrants flags principals that can perform destructive actions on every resource of a type
+func (r *Report) checkBroadGrants(resourcesByType map[resourcespb.ResourceType][]string) {
+	// principals of different types may share a name, e.g. a service and a batch
+	grantsByPrincipal := lo.GroupBy(r.Grants, func(g *Grant) string {
+		return typeName(g.PrincipalType) + "/" + g.Principal
+	})
+
+	principals := lo.Keys(grantsByPrincipal)
+	slices.Sort(principals)
+
+	for _, principal := range principals {
+		for _, action := range destructiveActions {
+			grantedOn := lo.FilterMap(grantsByPrincipal[principal], func(g *Grant, _ int) (*Grant, bool) {
+				return g, lo.Contains(g.Actions, action)
+			})
+
+			if len(grantedOn) == 0 {
+				continue
+			}
+
+			resourceType := grantedOn[0].ResourceType
+			allOfType := resourcesByType[resourceType]
+
+			// a single resource of a type isn't a broad grant, there's nothing else it could be scoped down to
+			if len(allOfType) > 1 && len(grantedOn) == len(allOfType) {
+				r.Findings = append(r.Findings, &Finding{
+					Type:         FindingType_BroadGrant,
+					Principal:    grantedOn[0].Principal,
+					ResourceType: typeName(resourceType),
+					Message:      fmt.Sprintf("%s '%s' can %s on every %s (%d)", typeName(grantedOn[0].PrincipalType), grantedOn[0].Principal, action, typeName(resourceType), len(allOfType)),
+				})
+			}
+		}
+	}
+}
+
+// checkUnusedResources flags resources that are written but never read, or read but never written
+func (r *Report) checkUnusedResources(spec *deploymentspb.Spec) {
+	for _, res := range spec.Resources {
+		readers := 0
+		writers := 0
+
+		for _, grant := range r.Grants {
+			if grant.Resource != res.Id.Name || grant.ResourceType != res.Id.Type {
+				continue
+			}
+
+			if len(grant.WriteActions()) > 0 {
+				writers++
+			}
+
+			if len(grant.WriteActions()) < len(grant.Actions) {
+				readers++
+			}
+		}
+
+		switch res.Id.Type {
+		case resourcespb.ResourceType_Topic:
+			// topics are read by their subscribers rather than through policies
+			readers += len(res.GetTopic().GetSubscriptions())
+		case resourcespb.ResourceType_Bucket, resourcespb.ResourceType_KeyValueStore, resourcespb.ResourceType_Queue, resourcespb.ResourceType_Secret:
+		default:
+			continue
+		}
+
+		if readers == 0 {
+			r.Findings = append(r.Findings, &Finding{
+				Type:         FindingType_NoReaders,
+				Resource:     res.Id.Name,
+				ResourceType: typeName(res.Id.Type),
+				Message:      fmt.Sprintf("%s '%s' is never read", typeName(res.Id.Type), res.Id.Name),
+			})
+		}
+
+		// secret values are commonly set outside of the application, e.g. by the cloud provider console
+		if writers == 0 && res.Id.Type != resourcespb.ResourceType_Secret {
+			r.Findings = append(r.Findings, &Finding{
+				Type:         FindingType_NoWriters,
+				Resource:     res.Id.Name,
+				ResourceType: typeName(res.Id.Type),
+				Message:      fmt.Sprintf("%s '%s' is never written", typeName(res.Id.Type), res.Id.Name),
+			})
+		}
+	}
+}
+
+// FromSpec builds a principal x resource x action matrix from the policies in a deployment spec and checks it for overly broad or unused access
+func FromSpec(spec *deploymentspb.Spec) *Report {
+	report := &Report{
+		Grants:   []*Grant{},
+		Findings: []*Finding{},
+	}
+
+	resourcesByType := map[resourcespb.ResourceType][]string{}
+
+	for _, res := range spec.Resources {
+		if res.Id.Type == resourcespb.ResourceType_Policy {
+			continue
+		}
+
+		resourcesByType[res.Id.Type] = append(resourcesByType[res.Id.Type], res.Id.Name)
+	}
+
+	for _, res := range spec.Resources {
+		policy := res.GetPolicy()
+		if policy == nil {
+			continue
+		}
+
+		for _, principal := range policy.Principals {
+			for _, resource := range policy.Resources {
+				grant := report.grant(principal.Id, resource.Id)
+
+				for _, action := range policy.Actions {
+					if !lo.Contains(grant.Actions, action) {
+						grant.Actions = append(grant.Actions, action)
+					}
+				}
+			}
+		}
+	}
+
+	for _, grant := range report.Grants {
+		slices.Sort(grant.Actions)
+	}
+
+	slices.SortFunc(report.Grants, func(a, b *Grant) int {
+		if c := strings.Compare(a.Principal, b.Principal); c != 0 {
+			return c
+		}
+
+		if c := strings.Compare(typeName(a.ResourceType), typeName(b.ResourceType)); c != 0 {
+			return c
+		}
+
+		return strings.Compare(a.Resource, b.Resource)
+	})
+
+	report.checkBroadGrants(resourcesByType)
+	report.checkUnusedResources(spec)
+
+	return report
+}
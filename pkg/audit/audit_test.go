@@ -0,0 +1,174 @@
+// Copyright Nitric Pty Ltd.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audit
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/spf13/afero"
+
+	deploymentspb "github.com/nitrictech/nitric/core/pkg/proto/deployments/v1"
+	resourcespb "github.com/nitrictech/nitric/core/pkg/proto/resources/v1"
+)
+
+func resource(name string, resourceType resourcespb.ResourceType) *deploymentspb.Resource {
+	return &deploymentspb.Resource{Id: &resourcespb.ResourceIdentifier{Name: name, Type: resourceType}}
+}
+
+func policy(principal string, actions []resourcespb.Action, resources ...*deploymentspb.Resource) *deploymentspb.Resource {
+	return &deploymentspb.Resource{
+		Id: &resourcespb.ResourceIdentifier{Name: principal + "-policy", Type: resourcespb.ResourceType_Policy},
+		Config: &deploymentspb.Resource_Policy{
+			Policy: &deploymentspb.Policy{
+				Principals: []*deploymentspb.Resource{resource(principal, resourcespb.ResourceType_Service)},
+				Actions:    actions,
+				Resources:  resources,
+			},
+		},
+	}
+}
+
+func TestAudit(t *testing.T) {
+	images := resource("images", resourcespb.ResourceType_Bucket)
+	uploads := resource("uploads", resourcespb.ResourceType_Bucket)
+
+	spec := &deploymentspb.Spec{
+		Resources: []*deploymentspb.Resource{
+			images,
+			uploads,
+			policy("api", []resourcespb.Action{resourcespb.Action_BucketFileDelete, resourcespb.Action_BucketFilePut}, images, uploads),
+			policy("worker", []resourcespb.Action{resourcespb.Action_BucketFileGet}, uploads),
+		},
+	}
+
+	report := FromSpec(spec)
+
+	if len(report.Grants) != 3 {
+		t.Fatalf("expected 3 grants, got %d", len(report.Grants))
+	}
+
+	findings := []FindingType{}
+
+	for _, finding := range report.Findings {
+		findings = append(findings, finding.Type)
+	}
+
+	if diff := cmp.Diff([]FindingType{FindingType_BroadGrant, FindingType_NoReaders}, findings); diff != "" {
+		t.Errorf("unexpected findings (-want +got):\n%s", diff)
+	}
+
+	rules := &Rules{
+		FailOn: []FindingType{FindingType_UnreviewedGrant},
+		Reviewed: []ReviewedGrant{
+			{Principal: "api", Resource: "*", ResourceType: "bucket", Actions: []string{"BucketFilePut"}},
+			// a review of another type of resource with the same name doesn't approve the bucket
+			{Principal: "api", Resource: "images", ResourceType: "topic", Actions: []string{"*"}},
+		},
+	}
+
+	failures := rules.Apply(report)
+
+	if len(failures) != 2 {
+		t.Fatalf("expected 2 unreviewed grants, got %d", len(failures))
+	}
+
+	if failures[0].Message != "service 'api' has unreviewed write access to bucket 'images': BucketFileDelete" {
+		t.Errorf("unexpected finding message: %s", failures[0].Message)
+	}
+}
+
+func TestReviewedPrincipalType(t *testing.T) {
+	images := resource("images", resourcespb.ResourceType_Bucket)
+
+	// a batch with the same name as the service
+	batchPolicy := policy("reports", []resourcespb.Action{resourcespb.Action_BucketFilePut}, images)
+	batchPolicy.Id.Name = "reports-batch-policy"
+	batchPolicy.GetPolicy().Principals = []*deploymentspb.Resource{resource("reports", resourcespb.ResourceType_Batch)}
+
+	spec := &deploymentspb.Spec{
+		Resources: []*deploymentspb.Resource{
+			images,
+			policy("reports", []resourcespb.Action{resourcespb.Action_BucketFilePut}, images),
+			batchPolicy,
+		},
+	}
+
+	for _, tt := range []struct {
+		name          string
+		principalType string
+		want          []string
+	}{
+		{
+			name:          "service only",
+			principalType: "service",
+			want:          []string{"batch 'reports' has unreviewed write access to bucket 'images': BucketFilePut"},
+		},
+		{
+			name:          "batch only",
+			principalType: "batch",
+			want:          []string{"service 'reports' has unreviewed write access to bucket 'images': BucketFilePut"},
+		},
+		{
+			name: "any type by default",
+			want: []string{},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			rules := &Rules{
+				FailOn: []FindingType{FindingType_UnreviewedGrant},
+				Reviewed: []ReviewedGrant{
+					{Principal: "reports", PrincipalType: tt.principalType, Resource: "images", ResourceType: "bucket", Actions: []string{"BucketFilePut"}},
+				},
+			}
+
+			got := []string{}
+			for _, finding := range rules.Apply(FromSpec(spec)) {
+				got = append(got, finding.Message)
+			}
+
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("Apply() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestRulesFromFile(t *testing.T) {
+	for _, tt := range []struct {
+		name    string
+		yaml    string
+		wantErr bool
+	}{
+		{name: "valid", yaml: "reviewed:\n  - principal: api\n    resource: uploads\n    resourceType: bucket\n    actions: [BucketFilePut]\n"},
+		{name: "any resource type", yaml: "reviewed:\n  - principal: api\n    resource: uploads\n    resourceType: \"*\"\n    actions: [\"*\"]\n"},
+		{name: "missing resource type", yaml: "reviewed:\n  - principal: api\n    resource: uploads\n    actions: [BucketFilePut]\n", wantErr: true},
+		{name: "unknown action", yaml: "reviewed:\n  - principal: api\n    resource: uploads\n    resourceType: bucket\n    actions: [BucketFileBurn]\n", wantErr: true},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			fs := afero.NewMemMapFs()
+
+			if err := afero.WriteFile(fs, "rules.yaml", []byte(tt.yaml), 0o644); err != nil {
+				t.Fatal(err)
+			}
+
+			if _, err := RulesFromFile(fs, "rules.yaml"); (err != nil) != tt.wantErr {
+				t.Errorf("RulesFromFile() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,136 @@
+// Copyright Nitric Pty Ltd.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audit
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/samber/lo"
+	"github.com/spf13/afero"
+	"gopkg.in/yaml.v3"
+
+	resourcespb "github.com/nitrictech/nitric/core/pkg/proto/resources/v1"
+)
+
+// ReviewedGrant is a grant that has been reviewed and approved, the names and types of the principal and resource may contain glob patterns
+type ReviewedGrant struct {
+	Principal string `yaml:"principal"`
+	// The type of the principal, e.g. service or batch, defaults to * so the review applies to principals of any type
+	PrincipalType string `yaml:"principalType,omitempty"`
+	Resource      string `yaml:"resource"`
+	// The type of the resource, e.g. bucket, so a review of one resource doesn't approve another type of resource with the same name
+	ResourceType string   `yaml:"resourceType"`
+	Actions      []string `yaml:"actions"`
+}
+
+// Rules determine which audit findings should fail the audit, e.g. in CI
+type Rules struct {
+	// The finding types that will fail the audit, defaults to unreviewed-grant
+	FailOn []FindingType `yaml:"failOn"`
+	// Write permissions that have been reviewed, any other write permissions are reported as unreviewed
+	Reviewed []ReviewedGrant `yaml:"reviewed"`
+}
+
+var allFindingTypes = []FindingType{FindingType_BroadGrant, FindingType_NoReaders, FindingType_NoWriters, FindingType_UnreviewedGrant}
+
+// RulesFromFile loads audit rules from a yaml file
+func RulesFromFile(fs afero.Fs, filePath string) (*Rules, error) {
+	contents, err := afero.ReadFile(fs, filePath)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read audit rules file: %w", err)
+	}
+
+	rules := &Rules{}
+
+	if err := yaml.Unmarshal(contents, rules); err != nil {
+		return nil, fmt.Errorf("unable to parse audit rules file %s: %w", filePath, err)
+	}
+
+	if len(rules.FailOn) == 0 {
+		rules.FailOn = []FindingType{FindingType_UnreviewedGrant}
+	}
+
+	for _, findingType := range rules.FailOn {
+		if !lo.Contains(allFindingTypes, findingType) {
+			return nil, fmt.Errorf("unknown finding type '%s' in audit rules file %s, expected one of %s", findingType, filePath, strings.Join(lo.Map(allFindingTypes, func(t FindingType, _ int) string { return string(t) }), ", "))
+		}
+	}
+
+	for _, reviewed := range rules.Reviewed {
+		if reviewed.ResourceType == "" {
+			return nil, fmt.Errorf("reviewed grant for %s on %s is missing a resourceType in audit rules file %s, use '*' to match any type", reviewed.Principal, reviewed.Resource, filePath)
+		}
+
+		for _, action := range reviewed.Actions {
+			if _, ok := resourcespb.Action_value[action]; !ok && action != "*" {
+				return nil, fmt.Errorf("unknown action '%s' in audit rules file %s", action, filePath)
+			}
+		}
+	}
+
+	return rules, nil
+}
+
+func globMatch(pattern string, name string) bool {
+	matched, err := path.Match(pattern, name)
+
+	return err == nil && matched
+}
+
+// isReviewed returns true if the action on the grant has been reviewed
+func (r *Rules) isReviewed(grant *Grant, action resourcespb.Action) bool {
+	return lo.ContainsBy(r.Reviewed, func(reviewed ReviewedGrant) bool {
+		principalType := reviewed.PrincipalType
+		if principalType == "" {
+			principalType = "*"
+		}
+
+		return globMatch(reviewed.Principal, grant.Principal) &&
+			globMatch(principalType, typeName(grant.PrincipalType)) &&
+			globMatch(reviewed.Resource, grant.Resource) &&
+			globMatch(reviewed.ResourceType, typeName(grant.ResourceType)) &&
+			(lo.Contains(reviewed.Actions, "*") || lo.Contains(reviewed.Actions, action.String()))
+	})
+}
+
+// Apply adds findings for any unreviewed write permissions to the report, returning the findings that fail the audit
+func (r *Rules) Apply(report *Report) []*Finding {
+	for _, grant := range report.Grants {
+		unreviewed := lo.Filter(grant.WriteActions(), func(action resourcespb.Action, _ int) bool {
+			return !r.isReviewed(grant, action)
+		})
+
+		if len(unreviewed) == 0 {
+			continue
+		}
+
+		report.Findings = append(report.Findings, &Finding{
+			Type:         FindingType_UnreviewedGrant,
+			Principal:    grant.Principal,
+			Resource:     grant.Resource,
+			ResourceType: typeName(grant.ResourceType),
+			Message: fmt.Sprintf("%s '%s' has unreviewed write access to %s '%s': %s", typeName(grant.PrincipalType), grant.Principal, typeName(grant.ResourceType), grant.Resource,
+				strings.Join(lo.Map(unreviewed, func(action resourcespb.Action, _ int) string { return action.String() }), ", ")),
+		})
+	}
+
+	return lo.Filter(report.Findings, func(finding *Finding, _ int) bool {
+		return lo.Contains(r.FailOn, finding.Type)
+	})
+}
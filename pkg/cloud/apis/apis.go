@@ -24,8 +24,11 @@ import (
 	"sync"
 
 	"github.com/asaskevich/EventBus"
+	"github.com/samber/lo"
 	"github.com/valyala/fasthttp"
 
+	"github.com/nitrictech/cli/pkg/cloud/health"
+	"github.com/nitrictech/cli/pkg/cloud/replicas"
 	"github.com/nitrictech/cli/pkg/grpcx"
 	apispb "github.com/nitrictech/nitric/core/pkg/proto/apis/v1"
 	"github.com/nitrictech/nitric/core/pkg/workers/apis"
@@ -43,9 +46,12 @@ type ApiRequestState struct {
 	Api      string
 	ReqCtx   *fasthttp.RequestCtx
 	HttpResp *apispb.HttpResponse
+	// Violations of the API contract, only populated when contract validation is enabled
+	Violations []string
 }
 type LocalApiGatewayService struct {
-	*apis.RouteWorkerManager
+	workers *replicas.Pool[*apis.RouteWorkerManager]
+	isReady health.IsReady
 
 	apiRegLock    sync.RWMutex
 	state         State
@@ -167,7 +173,47 @@ func (l *LocalApiGatewayService) Serve(stream apispb.Api_ServeServer) error {
 
 	defer l.unregisterApiWorker(serviceName, firstRequest.GetRegistrationRequest())
 
-	return l.RouteWorkerManager.Serve(peekableStream)
+	return l.workers.Manager(serviceName, grpcx.GetServiceReplicaFromStream(stream)).Serve(peekableStream)
+}
+
+// isNoWorkerError returns true when a replica has no worker for the requested api or route, so another replica or service can handle the request
+func isNoWorkerError(err error) bool {
+	return strings.HasPrefix(err.Error(), "no routes registered") || strings.HasPrefix(err.Error(), "no worker registered")
+}
+
+// HandleRequest routes a request to a worker of the first service handling the route, load balancing across the ready replicas of that service.
+// If no ready replica handles the route but replicas of services registered with the api are still starting, health.ErrNotReady is returned
+func (l *LocalApiGatewayService) HandleRequest(apiName string, request *apispb.ServerMessage) (*apispb.ClientMessage, error) {
+	state := l.GetState()
+	waiting := false
+
+	for _, serviceName := range l.workers.Services() {
+		for _, replica := range l.workers.Replicas(serviceName) {
+			if !l.isReady(serviceName, replica.Index) {
+				waiting = waiting || state[apiName][serviceName] != nil
+				continue
+			}
+
+			resp, err := replica.Manager.HandleRequest(apiName, request)
+			if err != nil && isNoWorkerError(err) {
+				continue
+			}
+
+			return resp, err
+		}
+	}
+
+	if waiting {
+		return nil, fmt.Errorf("%w, Api %s is waiting for its services to pass their health checks", health.ErrNotReady, apiName)
+	}
+
+	return nil, fmt.Errorf("no worker registered for Api %s on route: %s - %s", apiName, request.GetHttpRequest().GetMethod(), request.GetHttpRequest().GetPath())
+}
+
+func (l *LocalApiGatewayService) WorkerCount() int {
+	return lo.SumBy(l.workers.All(), func(manager *apis.RouteWorkerManager) int {
+		return manager.WorkerCount()
+	})
 }
 
 func (a *LocalApiGatewayService) ApiDetails(ctx context.Context, req *apispb.ApiDetailsRequest) (*apispb.ApiDetailsResponse, error) {
@@ -176,11 +222,12 @@ func (a *LocalApiGatewayService) ApiDetails(ctx context.Context, req *apispb.Api
 	}, nil
 }
 
-func NewLocalApiGatewayService(getApiAddress GetApiAddress) *LocalApiGatewayService {
+func NewLocalApiGatewayService(getApiAddress GetApiAddress, isReady health.IsReady) *LocalApiGatewayService {
 	return &LocalApiGatewayService{
-		RouteWorkerManager: apis.New(),
-		state:              State{},
-		bus:                EventBus.New(),
-		getApiAddress:      getApiAddress,
+		workers:       replicas.NewPool(apis.New),
+		isReady:       isReady,
+		state:         State{},
+		bus:           EventBus.New(),
+		getApiAddress: getApiAddress,
 	}
 }
@@ -21,12 +21,15 @@ import (
 	"io"
 	"sync"
 
+	"github.com/samber/lo"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/reflection"
 
 	"github.com/nitrictech/cli/pkg/cloud/apis"
 	"github.com/nitrictech/cli/pkg/cloud/batch"
+	"github.com/nitrictech/cli/pkg/cloud/containers"
 	"github.com/nitrictech/cli/pkg/cloud/gateway"
+	"github.com/nitrictech/cli/pkg/cloud/health"
 	"github.com/nitrictech/cli/pkg/cloud/http"
 	"github.com/nitrictech/cli/pkg/cloud/keyvalue"
 	"github.com/nitrictech/cli/pkg/cloud/queues"
@@ -64,7 +67,7 @@ const (
 
 type LocalCloud struct {
 	serverLock sync.Mutex
-	servers    map[ServiceName]*server.NitricServer
+	servers    map[ServiceName][]*server.NitricServer
 	mode       Mode
 
 	Apis       *apis.LocalApiGatewayService
@@ -81,6 +84,8 @@ type LocalCloud struct {
 	Websites   *websites.LocalWebsiteService
 	Queues     *queues.LocalQueuesService
 	Databases  *sql.LocalSqlServer
+	Containers *containers.LocalContainersService
+	Health     *health.LocalHealthService
 }
 
 func (lc *LocalCloud) GetMode() Mode {
@@ -89,8 +94,10 @@ func (lc *LocalCloud) GetMode() Mode {
 
 // StartLocalNitric - starts the Nitric Server, including plugins and their local dependencies (e.g. local versions of cloud services)
 func (lc *LocalCloud) Stop() {
-	for _, m := range lc.servers {
-		m.Stop()
+	for _, replicas := range lc.servers {
+		for _, m := range replicas {
+			m.Stop()
+		}
 	}
 
 	err := lc.Gateway.Stop()
@@ -102,6 +109,11 @@ func (lc *LocalCloud) Stop() {
 	if err != nil {
 		logger.Errorf("Error stopping databases: %s", err.Error())
 	}
+
+	err = lc.Containers.Stop()
+	if err != nil {
+		logger.Errorf("Error stopping containers: %s", err.Error())
+	}
 }
 
 func (lc *LocalCloud) AddBatch(batchName string) (int, error) {
@@ -168,46 +180,28 @@ func (lc *LocalCloud) AddBatch(batchName string) (int, error) {
 		}
 	}()
 
-	lc.servers[batchName] = nitricRuntimeServer
+	lc.servers[batchName] = []*server.NitricServer{nitricRuntimeServer}
 
 	return ports[0], nil
 }
 
-func (lc *LocalCloud) AddService(serviceName string) (int, error) {
+// AddService starts a nitric server for each replica of a service, returning the port of each replica's server
+func (lc *LocalCloud) AddService(serviceName string, replicas int) ([]int, error) {
 	lc.serverLock.Lock()
 	defer lc.serverLock.Unlock()
 
 	if _, ok := lc.servers[serviceName]; ok {
-		return 0, fmt.Errorf("service %s already started", serviceName)
+		return nil, fmt.Errorf("service %s already started", serviceName)
 	}
 
-	// get an available port
-	ports, err := netx.TakePort(1)
+	replicas = max(replicas, 1)
+
+	// get an available port for each replica
+	ports, err := netx.TakePort(replicas)
 	if err != nil {
-		return 0, err
+		return nil, err
 	}
 
-	nitricRuntimeServer, _ := server.New(
-		server.WithBatchPlugin(lc.Batch),
-		server.WithResourcesPlugin(lc.Resources),
-		server.WithApiPlugin(lc.Apis),
-		server.WithHttpPlugin(lc.Http),
-		server.WithSchedulesPlugin(lc.Schedules),
-		server.WithTopicsListenerPlugin(lc.Topics),
-		server.WithTopicsPlugin(lc.Topics),
-		server.WithStorageListenerPlugin(lc.Storage),
-		server.WithWebsocketListenerPlugin(lc.Websockets),
-		server.WithSqlPlugin(lc.Databases),
-		server.WithServiceAddress(fmt.Sprintf("0.0.0.0:%d", ports[0])),
-		server.WithSecretManagerPlugin(lc.Secrets),
-		server.WithStoragePlugin(lc.Storage),
-		server.WithKeyValuePlugin(lc.KeyValue),
-		server.WithGatewayPlugin(lc.Gateway),
-		server.WithWebsocketPlugin(lc.Websockets),
-		server.WithQueuesPlugin(lc.Queues),
-		server.WithMinWorkers(0),
-		server.WithChildCommand([]string{}))
-
 	// Create a watcher that clears old resources when the service is restarted
 	_, err = resources.NewServiceResourceRefresher(serviceName, resources.NewServiceResourceRefresherArgs{
 		Resources:  lc.Resources,
@@ -221,29 +215,52 @@ func (lc *LocalCloud) AddService(serviceName string) (int, error) {
 		BatchJobs:  lc.Batch,
 	})
 	if err != nil {
-		return 0, err
+		return nil, err
 	}
 
-	go func() {
-		interceptor, streamInterceptor := grpcx.CreateServiceNameInterceptor(serviceName)
-
-		srv := grpc.NewServer(
-			grpc.UnaryInterceptor(interceptor),
-			grpc.StreamInterceptor(streamInterceptor),
-		)
-
-		// Enable reflection on the gRPC server for local testing
-		reflection.Register(srv)
-
-		err := nitricRuntimeServer.Start(server.WithGrpcServer(srv))
-		if err != nil {
-			logger.Errorf("Error starting nitric server: %s", err.Error())
-		}
-	}()
-
-	lc.servers[serviceName] = nitricRuntimeServer
+	for replica, port := range ports {
+		nitricRuntimeServer, _ := server.New(
+			server.WithBatchPlugin(lc.Batch),
+			server.WithResourcesPlugin(lc.Resources),
+			server.WithApiPlugin(lc.Apis),
+			server.WithHttpPlugin(lc.Http),
+			server.WithSchedulesPlugin(lc.Schedules),
+			server.WithTopicsListenerPlugin(lc.Topics),
+			server.WithTopicsPlugin(lc.Topics),
+			server.WithStorageListenerPlugin(lc.Storage),
+			server.WithWebsocketListenerPlugin(lc.Websockets),
+			server.WithSqlPlugin(lc.Databases),
+			server.WithServiceAddress(fmt.Sprintf("0.0.0.0:%d", port)),
+			server.WithSecretManagerPlugin(lc.Secrets),
+			server.WithStoragePlugin(lc.Storage),
+			server.WithKeyValuePlugin(lc.KeyValue),
+			server.WithGatewayPlugin(lc.Gateway),
+			server.WithWebsocketPlugin(lc.Websockets),
+			server.WithQueuesPlugin(lc.Queues),
+			server.WithMinWorkers(0),
+			server.WithChildCommand([]string{}))
+
+		go func() {
+			interceptor, streamInterceptor := grpcx.CreateServiceReplicaInterceptor(serviceName, replica)
+
+			srv := grpc.NewServer(
+				grpc.UnaryInterceptor(interceptor),
+				grpc.StreamInterceptor(streamInterceptor),
+			)
+
+			// Enable reflection on the gRPC server for local testing
+			reflection.Register(srv)
+
+			err := nitricRuntimeServer.Start(server.WithGrpcServer(srv))
+			if err != nil {
+				logger.Errorf("Error starting nitric server: %s", err.Error())
+			}
+		}()
+
+		lc.servers[serviceName] = append(lc.servers[serviceName], nitricRuntimeServer)
+	}
 
-	return ports[0], nil
+	return ports, nil
 }
 
 type LocalCloudOptions struct {
@@ -252,15 +269,28 @@ type LocalCloudOptions struct {
 	LocalConfig     localconfig.LocalConfiguration
 	MigrationRunner sql.MigrationRunner
 	LocalCloudMode  Mode
+	// ValidateContracts enables validation of API traffic against the API OpenAPI contracts
+	ValidateContracts bool
+	// ProjectDirectory is the directory of the project, local state like database snapshots is stored in its .nitric directory
+	ProjectDirectory string
 }
 
-func New(projectName string, opts LocalCloudOptions) (*LocalCloud, error) {
-	localTopics, err := topics.NewLocalTopicsService()
+func New(projectName string, opts LocalCloudOptions) (lc *LocalCloud, err error) {
+	localHealth := health.NewLocalHealthService()
+
+	localTopics, err := topics.NewLocalTopicsService(localHealth.IsReady)
 	if err != nil {
 		return nil, err
 	}
 
-	localWebsockets, err := websockets.NewLocalWebsocketService()
+	binarySockets := lo.Keys(lo.PickBy(opts.LocalConfig.Websockets, func(_ string, config localconfig.LocalWebsocketConfiguration) bool {
+		return config.Binary
+	}))
+
+	localWebsockets, err := websockets.NewLocalWebsocketService(websockets.WebsocketOptions{
+		BinarySockets: binarySockets,
+		IsReady:       localHealth.IsReady,
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -275,20 +305,23 @@ func New(projectName string, opts LocalCloudOptions) (*LocalCloud, error) {
 
 	localResources := resources.NewLocalResourcesService()
 	localBatch := batch.NewLocalBatchService()
-	localSchedules := schedules.NewLocalSchedulesService(localResources.LogServiceError)
-	localHttpProxy := http.NewLocalHttpProxyService()
+	localSchedules := schedules.NewLocalSchedulesService(localResources.LogServiceError, localHealth.IsReady)
+	localHttpProxy := http.NewLocalHttpProxyService(localHealth.IsReady)
 
 	localGateway, err := gateway.NewGateway(gateway.NewGatewayOpts{
-		TLSCredentials: opts.TLSCredentials,
-		LogWriter:      opts.LogWriter,
-		LocalConfig:    opts.LocalConfig,
-		BatchPlugin:    localBatch,
+		TLSCredentials:    opts.TLSCredentials,
+		LogWriter:         opts.LogWriter,
+		LocalConfig:       opts.LocalConfig,
+		ProjectDirectory:  opts.ProjectDirectory,
+		BatchPlugin:       localBatch,
+		ValidateContracts: opts.ValidateContracts,
+		ErrorLogger:       localResources.LogServiceError,
 	})
 	if err != nil {
 		return nil, err
 	}
 
-	localApis := apis.NewLocalApiGatewayService(localGateway.GetApiAddress)
+	localApis := apis.NewLocalApiGatewayService(localGateway.GetApiAddress, localHealth.IsReady)
 
 	localSecrets, err := secrets.NewSecretService()
 	if err != nil {
@@ -316,7 +349,28 @@ func New(projectName string, opts LocalCloudOptions) (*LocalCloud, error) {
 		connectionStringHost = dockerhost.GetInternalDockerHost()
 	}
 
-	localDatabaseService, err := sql.NewLocalSqlServer(projectName, localResources, opts.MigrationRunner, connectionStringHost)
+	localContainers, err := containers.NewLocalContainersService(projectName, containers.LocalContainersOptions{
+		Host:             connectionStringHost,
+		ProjectDirectory: opts.ProjectDirectory,
+		Containers:       opts.LocalConfig.Containers,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		// don't leave the auxiliary containers running if the rest of the local cloud fails to start
+		if err != nil {
+			_ = localContainers.Stop()
+		}
+	}()
+
+	localDatabaseService, err := sql.NewLocalSqlServer(projectName, localResources, sql.LocalSqlServerOptions{
+		MigrationRunner:      opts.MigrationRunner,
+		ConnectionStringHost: connectionStringHost,
+		ProjectDirectory:     opts.ProjectDirectory,
+		Config:               opts.LocalConfig.Sql,
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -324,7 +378,7 @@ func New(projectName string, opts LocalCloudOptions) (*LocalCloud, error) {
 	localWebsites := websites.NewLocalWebsitesService(localGateway.GetApiAddress, localGateway.GetWebsocketAddress, opts.LocalCloudMode == StartMode)
 
 	return &LocalCloud{
-		servers:    make(map[string]*server.NitricServer),
+		servers:    make(map[string][]*server.NitricServer),
 		mode:       opts.LocalCloudMode,
 		Apis:       localApis,
 		Batch:      localBatch,
@@ -340,5 +394,7 @@ func New(projectName string, opts LocalCloudOptions) (*LocalCloud, error) {
 		KeyValue:   keyvalueService,
 		Queues:     localQueueService,
 		Databases:  localDatabaseService,
+		Containers: localContainers,
+		Health:     localHealth,
 	}, nil
 }
@@ -0,0 +1,535 @@
+// Copyright Nitric Pty Ltd.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package containers
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"maps"
+	"net"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/asaskevich/EventBus"
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/mount"
+	"github.com/docker/docker/pkg/stdcopy"
+	"github.com/docker/go-connections/nat"
+	"github.com/samber/lo"
+
+	"github.com/nitrictech/cli/pkg/docker"
+	"github.com/nitrictech/cli/pkg/netx"
+	"github.com/nitrictech/cli/pkg/project/localconfig"
+	"github.com/nitrictech/nitric/core/pkg/logger"
+)
+
+type ContainerStatus string
+
+const (
+	ContainerStatus_Starting  ContainerStatus = "starting"
+	ContainerStatus_Running   ContainerStatus = "running"
+	ContainerStatus_Healthy   ContainerStatus = "healthy"
+	ContainerStatus_Unhealthy ContainerStatus = "unhealthy"
+	ContainerStatus_Error     ContainerStatus = "error"
+)
+
+type Container struct {
+	Name   string
+	Image  string
+	Status ContainerStatus
+	// StatusMessage is the reason the container is in an error state
+	StatusMessage string
+	// Ports maps each container port to the host port it is published on
+	Ports map[int]int
+
+	containerId string
+	config      localconfig.LocalContainerConfiguration
+}
+
+type (
+	ContainerName = string
+	State         = map[ContainerName]*Container
+)
+
+// ContainerLog is a line of output of an auxiliary container
+type ContainerLog struct {
+	Name    string
+	Message string
+	Stderr  bool
+}
+
+type LocalContainersService struct {
+	projectName      string
+	projectDirectory string
+	// host is the address services use to connect to the published ports of the containers
+	host string
+
+	containers     State
+	containersLock sync.RWMutex
+
+	bus EventBus.Bus
+}
+
+const localContainersTopic = "local_containers"
+
+func (l *LocalContainersService) publishState() {
+	l.bus.Publish(localContainersTopic, l.GetState())
+}
+
+func (l *LocalContainersService) SubscribeToState(fn func(State)) {
+	// ignore the error, it's only returned if the fn param isn't a function
+	_ = l.bus.Subscribe(localContainersTopic, fn)
+}
+
+// GetState - Returns a copy of the state of the containers
+func (l *LocalContainersService) GetState() State {
+	l.containersLock.RLock()
+	defer l.containersLock.RUnlock()
+
+	state := State{}
+
+	for name, c := range l.containers {
+		copied := *c
+		copied.Ports = maps.Clone(c.Ports)
+		state[name] = &copied
+	}
+
+	return state
+}
+
+func (l *LocalContainersService) setStatus(name string, status ContainerStatus, message string) {
+	l.containersLock.Lock()
+	l.containers[name].Status = status
+	l.containers[name].StatusMessage = message
+	l.containersLock.Unlock()
+
+	l.publishState()
+}
+
+func (l *LocalContainersService) containerName(name string) string {
+	return fmt.Sprintf("nitric-%s-%s", l.projectName, name)
+}
+
+var envNameReplacer = regexp.MustCompile(`[^A-Z0-9]+`)
+
+// envPrefix returns the prefix of the connection environment variables of a container, e.g. MOCK_OAUTH for mock-oauth
+func envPrefix(name string) string {
+	return strings.Trim(envNameReplacer.ReplaceAllString(strings.ToUpper(name), "_"), "_")
+}
+
+// connectionEnv returns the connection details of a container, the host and the host port of each of its ports
+func connectionEnv(host string, c *Container) map[string]string {
+	env := map[string]string{
+		"HOST": host,
+	}
+
+	for i, port := range c.config.Ports {
+		hostPort := fmt.Sprint(c.Ports[port])
+
+		if i == 0 {
+			env["PORT"] = hostPort
+		}
+
+		env[fmt.Sprintf("PORT_%d", port)] = hostPort
+	}
+
+	return env
+}
+
+// ServiceEnv - returns the environment variables services use to connect to the containers.
+// Each container provides <NAME>_HOST, <NAME>_PORT for its first port and <NAME>_PORT_<port> for each port,
+// as well as its serviceEnv with ${HOST}, ${PORT} and ${PORT_<port>} replaced.
+func (l *LocalContainersService) ServiceEnv() map[string]string {
+	l.containersLock.RLock()
+	defer l.containersLock.RUnlock()
+
+	env := map[string]string{}
+
+	for name, c := range l.containers {
+		connection := connectionEnv(l.host, c)
+
+		for key, value := range connection {
+			env[fmt.Sprintf("%s_%s", envPrefix(name), key)] = value
+		}
+
+		for key, value := range c.config.ServiceEnv {
+			env[key] = os.Expand(value, func(variable string) string {
+				if value, ok := connection[variable]; ok {
+					return value
+				}
+
+				return fmt.Sprintf("${%s}", variable)
+			})
+		}
+	}
+
+	return env
+}
+
+// mounts returns the mounts of the volumes of a container, named volumes are scoped to the project
+func (l *LocalContainersService) mounts(c *Container) []mount.Mount {
+	mounts := []mount.Mount{}
+
+	for _, volume := range c.config.Volumes {
+		parts := strings.Split(volume, ":")
+
+		m := mount.Mount{
+			Type:     mount.TypeVolume,
+			Source:   fmt.Sprintf("%s-%s", l.projectName, parts[0]),
+			Target:   parts[1],
+			ReadOnly: len(parts) == 3 && parts[2] == "ro",
+		}
+
+		if strings.HasPrefix(parts[0], ".") || strings.HasPrefix(parts[0], "/") {
+			m.Type = mount.TypeBind
+			m.Source = parts[0]
+
+			if !filepath.IsAbs(m.Source) {
+				m.Source = filepath.Join(l.projectDirectory, m.Source)
+			}
+		}
+
+		mounts = append(mounts, m)
+	}
+
+	return mounts
+}
+
+func healthConfig(check *localconfig.LocalContainerHealthCheck) *container.HealthConfig {
+	if check == nil {
+		return nil
+	}
+
+	// durations are validated when the local configuration is loaded
+	interval, _ := time.ParseDuration(check.Interval)
+	timeout, _ := time.ParseDuration(check.Timeout)
+
+	return &container.HealthConfig{
+		Test:     append([]string{"CMD"}, check.Command...),
+		Interval: interval,
+		Timeout:  timeout,
+		Retries:  check.Retries,
+	}
+}
+
+// reservePorts finds a free host port for each container port, starting from the same port number.
+// Listeners stay open until every port is reserved, so containers publishing the same port get different host ports
+func (l *LocalContainersService) reservePorts() error {
+	listeners := []net.Listener{}
+
+	defer func() {
+		for _, lis := range listeners {
+			_ = lis.Close()
+		}
+	}()
+
+	for _, name := range slices.Sorted(maps.Keys(l.containers)) {
+		c := l.containers[name]
+
+		for _, port := range c.config.Ports {
+			lis, err := netx.GetNextListener(netx.MinPort(port), netx.MaxPort(port+1000))
+			if err != nil {
+				return fmt.Errorf("unable to find a free port for port %d of container %s: %w", port, name, err)
+			}
+
+			listeners = append(listeners, lis)
+			c.Ports[port] = lis.Addr().(*net.TCPAddr).Port
+		}
+	}
+
+	return nil
+}
+
+// start starts a container with its reserved ports, returning its id
+func (l *LocalContainersService) start(dockerClient *docker.Docker, c *Container) (string, error) {
+	err := dockerClient.ImagePull(c.Image, types.ImagePullOptions{
+		All: false,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	// remove a container left running by a previous run of the project
+	_ = dockerClient.ContainerRemove(context.Background(), l.containerName(c.Name), container.RemoveOptions{Force: true})
+
+	exposedPorts := nat.PortSet{}
+	portBindings := nat.PortMap{}
+
+	for port, hostPort := range c.Ports {
+		containerPort := nat.Port(fmt.Sprintf("%d/tcp", port))
+		exposedPorts[containerPort] = struct{}{}
+		portBindings[containerPort] = []nat.PortBinding{
+			{
+				HostPort: fmt.Sprint(hostPort),
+			},
+		}
+	}
+
+	env := []string{}
+	for key, value := range c.config.Env {
+		env = append(env, key+"="+value)
+	}
+
+	slices.Sort(env)
+
+	containerId, err := dockerClient.ContainerCreate(&container.Config{
+		Image:        c.Image,
+		Cmd:          c.config.Command,
+		Env:          env,
+		ExposedPorts: exposedPorts,
+		Healthcheck:  healthConfig(c.config.HealthCheck),
+	}, &container.HostConfig{
+		// containers are removed when they're stopped, so the logs of a container that exits remain available
+		Mounts:       l.mounts(c),
+		PortBindings: portBindings,
+	}, nil, l.containerName(c.Name))
+	if err != nil {
+		return "", err
+	}
+
+	return containerId, dockerClient.ContainerStart(context.Background(), containerId, container.StartOptions{})
+}
+
+// exitedError returns the reason a container exited with the last lines of its output
+func exitedError(dockerClient *docker.Docker, c *Container, containerId string, exitCode int) error {
+	err := fmt.Errorf("container %s exited with status %d", c.Name, exitCode)
+
+	logReader, logErr := dockerClient.ContainerLogs(context.Background(), containerId, container.LogsOptions{ShowStdout: true, ShowStderr: true, Tail: "20"})
+	if logErr != nil {
+		return err
+	}
+
+	defer logReader.Close()
+
+	var logs strings.Builder
+	if _, logErr := stdcopy.StdCopy(&logs, &logs, logReader); logErr != nil || logs.Len() == 0 {
+		return err
+	}
+
+	return fmt.Errorf("%w\n%s", err, strings.TrimSpace(logs.String()))
+}
+
+// waitForHealthy blocks until a container with a health check is healthy, containers without a health check are ready once started.
+// A container that exits while starting is an error
+func (l *LocalContainersService) waitForHealthy(ctx context.Context, dockerClient *docker.Docker, c *Container, containerId string) (ContainerStatus, error) {
+	for {
+		inspect, err := dockerClient.ContainerInspect(ctx, containerId)
+		if err != nil {
+			return ContainerStatus_Error, err
+		}
+
+		if inspect.State != nil && !inspect.State.Running && !inspect.State.Restarting {
+			return ContainerStatus_Error, exitedError(dockerClient, c, containerId, inspect.State.ExitCode)
+		}
+
+		if c.config.HealthCheck == nil {
+			return ContainerStatus_Running, nil
+		}
+
+		if inspect.State != nil && inspect.State.Health != nil {
+			switch inspect.State.Health.Status {
+			case types.Healthy:
+				return ContainerStatus_Healthy, nil
+			case types.Unhealthy:
+				return ContainerStatus_Unhealthy, fmt.Errorf("container %s is unhealthy", c.Name)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return ContainerStatus_Unhealthy, fmt.Errorf("timed out waiting for container %s to be healthy", c.Name)
+		case <-time.After(500 * time.Millisecond):
+		}
+	}
+}
+
+// SubscribeToLogs - follows the output of the containers, from when they started, until they stop
+func (l *LocalContainersService) SubscribeToLogs(fn func(ContainerLog)) {
+	dockerClient, err := docker.New()
+	if err != nil {
+		logger.Errorf("unable to follow container logs: %s", err.Error())
+		return
+	}
+
+	for _, c := range l.GetState() {
+		if c.containerId == "" {
+			continue
+		}
+
+		go func(c *Container) {
+			logReader, err := dockerClient.ContainerLogs(context.Background(), c.containerId, container.LogsOptions{
+				ShowStdout: true,
+				ShowStderr: true,
+				Follow:     true,
+			})
+			if err != nil {
+				logger.Errorf("unable to follow logs of container %s: %s", c.Name, err.Error())
+				return
+			}
+
+			defer logReader.Close()
+
+			stdout, stdoutWriter := io.Pipe()
+			stderr, stderrWriter := io.Pipe()
+
+			go forwardLines(stdout, c.Name, false, fn)
+			go forwardLines(stderr, c.Name, true, fn)
+
+			_, _ = stdcopy.StdCopy(stdoutWriter, stderrWriter, logReader)
+
+			stdoutWriter.Close()
+			stderrWriter.Close()
+		}(c)
+	}
+}
+
+func forwardLines(reader io.Reader, name string, stderr bool, fn func(ContainerLog)) {
+	scanner := bufio.NewScanner(reader)
+
+	for scanner.Scan() {
+		fn(ContainerLog{Name: name, Message: scanner.Text() + "\n", Stderr: stderr})
+	}
+}
+
+// Stop - stops and removes the containers
+func (l *LocalContainersService) Stop() error {
+	dockerClient, err := docker.New()
+	if err != nil {
+		return err
+	}
+
+	l.containersLock.Lock()
+	defer l.containersLock.Unlock()
+
+	errs := []error{}
+
+	for _, c := range l.containers {
+		if c.containerId == "" {
+			continue
+		}
+
+		if err := dockerClient.ContainerStop(context.Background(), c.containerId, container.StopOptions{}); err != nil {
+			errs = append(errs, fmt.Errorf("unable to stop container %s: %w", c.Name, err))
+		}
+
+		if err := dockerClient.ContainerRemove(context.Background(), c.containerId, container.RemoveOptions{Force: true}); err != nil {
+			errs = append(errs, fmt.Errorf("unable to remove container %s: %w", c.Name, err))
+		}
+
+		c.containerId = ""
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("%v", errs)
+	}
+
+	return nil
+}
+
+type LocalContainersOptions struct {
+	// Host is the address services use to connect to the containers
+	Host string
+	// ProjectDirectory is the directory relative bind mounts are resolved from
+	ProjectDirectory string
+	// Containers is the container configuration from local.nitric.yaml
+	Containers map[string]localconfig.LocalContainerConfiguration
+}
+
+// NewLocalContainersService - starts the auxiliary containers of the project, waiting for those with a health check to be healthy.
+// A container that fails to start or becomes unhealthy is reported in its state without stopping the other containers.
+func NewLocalContainersService(projectName string, opts LocalContainersOptions) (*LocalContainersService, error) {
+	opts.Host, _ = lo.Coalesce(opts.Host, "localhost")
+
+	l := &LocalContainersService{
+		projectName:      projectName,
+		projectDirectory: opts.ProjectDirectory,
+		host:             opts.Host,
+		containers:       State{},
+		bus:              EventBus.New(),
+	}
+
+	if len(opts.Containers) == 0 {
+		return l, nil
+	}
+
+	for name, config := range opts.Containers {
+		l.containers[name] = &Container{
+			Name:   name,
+			Image:  config.Image,
+			Status: ContainerStatus_Starting,
+			Ports:  map[int]int{},
+			config: config,
+		}
+	}
+
+	if err := l.reservePorts(); err != nil {
+		return nil, err
+	}
+
+	dockerClient, err := docker.New()
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+
+	wg := sync.WaitGroup{}
+
+	for _, c := range l.containers {
+		wg.Add(1)
+
+		go func(c *Container) {
+			defer wg.Done()
+
+			containerId, err := l.start(dockerClient, c)
+
+			l.containersLock.Lock()
+			c.containerId = containerId
+			l.containersLock.Unlock()
+
+			if err != nil {
+				logger.Errorf("unable to start container %s: %s", c.Name, err.Error())
+				l.setStatus(c.Name, ContainerStatus_Error, err.Error())
+
+				return
+			}
+
+			status, err := l.waitForHealthy(ctx, dockerClient, c, containerId)
+			if err != nil {
+				logger.Errorf("%s", err.Error())
+				l.setStatus(c.Name, status, err.Error())
+
+				return
+			}
+
+			l.setStatus(c.Name, status, "")
+		}(c)
+	}
+
+	wg.Wait()
+
+	return l, nil
+}
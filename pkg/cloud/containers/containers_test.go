@@ -0,0 +1,83 @@
+// Copyright Nitric Pty Ltd.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package containers
+
+import (
+	"testing"
+
+	"github.com/docker/docker/api/types/mount"
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/nitrictech/cli/pkg/project/localconfig"
+)
+
+func TestServiceEnv(t *testing.T) {
+	l := &LocalContainersService{
+		projectName: "my-project",
+		host:        "host.docker.internal",
+		containers: State{
+			"redis-cache": {
+				Name:  "redis-cache",
+				Ports: map[int]int{6379: 16379, 8001: 18001},
+				config: localconfig.LocalContainerConfiguration{
+					Image: "redis/redis-stack",
+					Ports: []int{6379, 8001},
+					ServiceEnv: map[string]string{
+						"REDIS_URL":   "redis://${HOST}:${PORT}",
+						"INSIGHT_URL": "http://${HOST}:${PORT_8001}/${UNKNOWN}",
+					},
+				},
+			},
+		},
+	}
+
+	want := map[string]string{
+		"REDIS_CACHE_HOST":      "host.docker.internal",
+		"REDIS_CACHE_PORT":      "16379",
+		"REDIS_CACHE_PORT_6379": "16379",
+		"REDIS_CACHE_PORT_8001": "18001",
+		"REDIS_URL":             "redis://host.docker.internal:16379",
+		"INSIGHT_URL":           "http://host.docker.internal:18001/${UNKNOWN}",
+	}
+
+	if diff := cmp.Diff(want, l.ServiceEnv()); diff != "" {
+		t.Errorf("ServiceEnv() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestMounts(t *testing.T) {
+	l := &LocalContainersService{
+		projectName:      "my-project",
+		projectDirectory: "/home/me/my-project",
+	}
+
+	c := &Container{
+		config: localconfig.LocalContainerConfiguration{
+			Volumes: []string{"data:/data", "./seed:/seed:ro", "/tmp/cache:/cache"},
+		},
+	}
+
+	want := []mount.Mount{
+		{Type: mount.TypeVolume, Source: "my-project-data", Target: "/data"},
+		{Type: mount.TypeBind, Source: "/home/me/my-project/seed", Target: "/seed", ReadOnly: true},
+		{Type: mount.TypeBind, Source: "/tmp/cache", Target: "/cache"},
+	}
+
+	if diff := cmp.Diff(want, l.mounts(c)); diff != "" {
+		t.Errorf("mounts() mismatch (-want +got):\n%s", diff)
+	}
+}
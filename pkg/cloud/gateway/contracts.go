@@ -0,0 +1,260 @@
+// Copyright Nitric Pty Ltd.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gateway
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"slices"
+	"sync"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/getkin/kin-openapi/routers/gorillamux"
+	"github.com/samber/lo"
+	"github.com/valyala/fasthttp"
+	"github.com/valyala/fasthttp/fasthttpadaptor"
+
+	"github.com/nitrictech/cli/pkg/cloud/apis"
+	"github.com/nitrictech/cli/pkg/collector"
+	"github.com/nitrictech/cli/pkg/project/localconfig"
+	apispb "github.com/nitrictech/nitric/core/pkg/proto/apis/v1"
+)
+
+// contractValidator checks API requests and responses against the OpenAPI contract of each API.
+// The contract is either generated from the registered routes or supplied in local.nitric.yaml.
+type contractValidator struct {
+	lock    sync.RWMutex
+	routers map[string]routers.Router
+	// targets route requests to the generated contract of each API, to find the service handling a request
+	targets map[string]routers.Router
+	// services maps API names to the services registering its routes
+	services map[string][]string
+
+	// contracts maps API names to user supplied OpenAPI documents
+	contracts map[string]string
+}
+
+// contractPaths returns the contract files of the APIs in local.nitric.yaml, relative paths are resolved against the project directory
+func contractPaths(apiConfigs map[string]localconfig.LocalApiConfiguration, projectDirectory string) map[string]string {
+	return lo.MapValues(apiConfigs, func(config localconfig.LocalApiConfiguration, _ string) string {
+		if config.Contract == "" || filepath.IsAbs(config.Contract) {
+			return config.Contract
+		}
+
+		return filepath.Join(projectDirectory, config.Contract)
+	})
+}
+
+func newContractValidator(contracts map[string]string) *contractValidator {
+	return &contractValidator{
+		routers:   map[string]routers.Router{},
+		targets:   map[string]routers.Router{},
+		services:  map[string][]string{},
+		contracts: contracts,
+	}
+}
+
+func (c *contractValidator) loadContract(apiName string, registrations map[apis.ServiceName][]*apispb.RegistrationRequest) (*openapi3.T, error) {
+	if contractFile := c.contracts[apiName]; contractFile != "" {
+		doc, err := openapi3.NewLoader().LoadFromFile(contractFile)
+		if err != nil {
+			return nil, fmt.Errorf("unable to load contract %s for api %s: %w", contractFile, apiName, err)
+		}
+
+		return doc, nil
+	}
+
+	return collector.ApiToOpenApiSpec(registrations, nil, &collector.ProjectErrors{})
+}
+
+// refresh rebuilds the contract routers for the current set of APIs
+func (c *contractValidator) refresh(apiState apis.State) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.routers = map[string]routers.Router{}
+	c.targets = map[string]routers.Router{}
+	c.services = map[string][]string{}
+
+	errs := []error{}
+
+	for apiName, registrations := range apiState {
+		c.services[apiName] = lo.Keys(registrations)
+		slices.Sort(c.services[apiName])
+
+		if generated, err := collector.ApiToOpenApiSpec(registrations, nil, &collector.ProjectErrors{}); err == nil {
+			generated.Servers = nil
+
+			if target, err := gorillamux.NewRouter(generated); err == nil {
+				c.targets[apiName] = target
+			}
+		}
+
+		doc, err := c.loadContract(apiName, registrations)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		// requests are routed by path alone, the servers of the contract aren't the local gateway addresses
+		doc.Servers = nil
+
+		// authentication isn't enforced locally, so only the shape of the traffic is validated
+		doc.Security = nil
+
+		for _, pathItem := range doc.Paths {
+			for _, op := range pathItem.Operations() {
+				op.Security = nil
+			}
+		}
+
+		router, err := gorillamux.NewRouter(doc)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("unable to route contract for api %s: %w", apiName, err))
+			continue
+		}
+
+		c.routers[apiName] = router
+	}
+
+	return errors.Join(errs...)
+}
+
+// check converts a request to an API once, returning the ways it or its response break the API contract and the services
+// the violations are reported against
+func (c *contractValidator) check(apiName string, ctx *fasthttp.RequestCtx, resp *apispb.HttpResponse) ([]string, []string) {
+	req := &http.Request{}
+
+	if err := fasthttpadaptor.ConvertRequest(ctx, req, true); err != nil {
+		return []string{fmt.Sprintf("unable to read request: %s", err.Error())}, c.serviceNames(apiName, nil)
+	}
+
+	violations := c.validate(apiName, req, resp)
+	if len(violations) == 0 {
+		return nil, nil
+	}
+
+	return violations, c.serviceNames(apiName, req)
+}
+
+// serviceNames returns the service handling a request to an API, or every service of the API if the request doesn't match one of its routes
+func (c *contractValidator) serviceNames(apiName string, req *http.Request) []string {
+	c.lock.RLock()
+	target, ok := c.targets[apiName]
+	services := c.services[apiName]
+	c.lock.RUnlock()
+
+	if ok && req != nil {
+		if route, _, err := target.FindRoute(req); err == nil {
+			if nitricTarget, ok := route.Operation.Extensions["x-nitric-target"].(map[string]any); ok {
+				if name, ok := nitricTarget["name"].(string); ok {
+					return []string{name}
+				}
+			}
+		}
+	}
+
+	return services
+}
+
+// validate returns a description of each way the request or response breaks the API contract
+func (c *contractValidator) validate(apiName string, req *http.Request, resp *apispb.HttpResponse) []string {
+	c.lock.RLock()
+	router, ok := c.routers[apiName]
+	c.lock.RUnlock()
+
+	if !ok {
+		return nil
+	}
+
+	method := req.Method
+	path := req.URL.Path
+
+	route, pathParams, err := router.FindRoute(req)
+	if err != nil {
+		switch {
+		case errors.Is(err, routers.ErrMethodNotAllowed):
+			return []string{fmt.Sprintf("method %s is not declared for path %s", method, path)}
+		case errors.Is(err, routers.ErrPathNotFound):
+			return []string{fmt.Sprintf("path %s is not declared", path)}
+		default:
+			return []string{err.Error()}
+		}
+	}
+
+	options := &openapi3filter.Options{
+		MultiError:          true,
+		SkipSettingDefaults: true,
+	}
+	options.WithCustomSchemaErrorFunc(func(err *openapi3.SchemaError) string {
+		return err.Reason
+	})
+
+	requestInput := &openapi3filter.RequestValidationInput{
+		Request:    req,
+		PathParams: pathParams,
+		Route:      route,
+		Options:    options,
+	}
+
+	violations := []string{}
+
+	err = openapi3filter.ValidateRequest(context.Background(), requestInput)
+	violations = append(violations, violationMessages("request", err)...)
+
+	header := http.Header{}
+	for k, v := range resp.Headers {
+		header[http.CanonicalHeaderKey(k)] = v.Value
+	}
+
+	responseInput := &openapi3filter.ResponseValidationInput{
+		RequestValidationInput: requestInput,
+		Status:                 int(resp.Status),
+		Header:                 header,
+		Options:                options,
+	}
+	responseInput.SetBodyBytes(resp.Body)
+
+	err = openapi3filter.ValidateResponse(context.Background(), responseInput)
+	violations = append(violations, violationMessages("response", err)...)
+
+	return violations
+}
+
+func violationMessages(kind string, err error) []string {
+	if err == nil {
+		return nil
+	}
+
+	var multiErr openapi3.MultiError
+	if !errors.As(err, &multiErr) {
+		multiErr = openapi3.MultiError{err}
+	}
+
+	messages := []string{}
+
+	for _, e := range multiErr {
+		messages = append(messages, fmt.Sprintf("%s: %s", kind, e.Error()))
+	}
+
+	return messages
+}
@@ -0,0 +1,204 @@
+// Copyright Nitric Pty Ltd.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gateway
+
+import (
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/valyala/fasthttp"
+
+	"github.com/nitrictech/cli/pkg/cloud/apis"
+	"github.com/nitrictech/cli/pkg/project/localconfig"
+	apispb "github.com/nitrictech/nitric/core/pkg/proto/apis/v1"
+)
+
+const testContract = `openapi: 3.0.1
+info:
+  title: main
+  version: v1
+servers:
+  - url: https://api.example.com/v1
+paths:
+  /users/{id}:
+    parameters:
+      - name: id
+        in: path
+        required: true
+        schema:
+          type: integer
+    post:
+      requestBody:
+        content:
+          application/json:
+            schema:
+              type: object
+              required: [name]
+              properties:
+                name:
+                  type: string
+      responses:
+        "200":
+          description: ok
+          content:
+            application/json:
+              schema:
+                type: object
+                required: [id]
+                properties:
+                  id:
+                    type: integer
+`
+
+func TestContractValidator(t *testing.T) {
+	contractFile := filepath.Join(t.TempDir(), "main.yaml")
+
+	err := os.WriteFile(contractFile, []byte(testContract), 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	validator := newContractValidator(map[string]string{"main": contractFile})
+
+	err = validator.refresh(apis.State{"main": {}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name           string
+		method         string
+		path           string
+		body           string
+		responseBody   string
+		wantViolations int
+	}{
+		{
+			name:           "valid request and response",
+			method:         "POST",
+			path:           "/users/1",
+			body:           `{"name":"test"}`,
+			responseBody:   `{"id":1}`,
+			wantViolations: 0,
+		},
+		{
+			name:           "undeclared method",
+			method:         "DELETE",
+			path:           "/users/1",
+			wantViolations: 1,
+		},
+		{
+			name:           "undeclared path",
+			method:         "POST",
+			path:           "/orders",
+			wantViolations: 1,
+		},
+		{
+			name:           "invalid path param",
+			method:         "POST",
+			path:           "/users/abc",
+			body:           `{"name":"test"}`,
+			responseBody:   `{"id":1}`,
+			wantViolations: 1,
+		},
+		{
+			name:           "invalid request and response bodies",
+			method:         "POST",
+			path:           "/users/1",
+			body:           `{}`,
+			responseBody:   `{"id":"one"}`,
+			wantViolations: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := &fasthttp.RequestCtx{}
+			ctx.Request.Header.SetMethod(tt.method)
+			ctx.Request.SetRequestURI(tt.path)
+			ctx.Request.Header.SetContentType("application/json")
+			ctx.Request.SetBodyString(tt.body)
+
+			resp := &apispb.HttpResponse{
+				Status:  200,
+				Headers: map[string]*apispb.HeaderValue{"content-type": {Value: []string{"application/json"}}},
+				Body:    []byte(tt.responseBody),
+			}
+
+			violations, _ := validator.check("main", ctx, resp)
+			if len(violations) != tt.wantViolations {
+				t.Errorf("check() = %v, want %d violations", violations, tt.wantViolations)
+			}
+		})
+	}
+}
+
+func TestContractServiceNames(t *testing.T) {
+	validator := newContractValidator(map[string]string{})
+
+	err := validator.refresh(apis.State{
+		"main": {
+			"users":  {{Api: "main", Path: "/users/:id", Methods: []string{"POST"}}},
+			"orders": {{Api: "main", Path: "/orders", Methods: []string{"GET"}}},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		want   []string
+	}{
+		{name: "users route", method: "POST", path: "/users/1", want: []string{"users"}},
+		{name: "orders route", method: "GET", path: "/orders", want: []string{"orders"}},
+		{name: "unknown route", method: "GET", path: "/products", want: []string{"orders", "users"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+
+			if diff := cmp.Diff(tt.want, validator.serviceNames("main", req)); diff != "" {
+				t.Errorf("serviceNames() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestContractPaths(t *testing.T) {
+	apiConfigs := map[string]localconfig.LocalApiConfiguration{
+		"relative":  {Contract: "contracts/main.yaml"},
+		"absolute":  {Contract: "/contracts/main.yaml"},
+		"generated": {},
+	}
+
+	want := map[string]string{
+		"relative":  filepath.Join("/project", "contracts/main.yaml"),
+		"absolute":  "/contracts/main.yaml",
+		"generated": "",
+	}
+
+	if diff := cmp.Diff(want, contractPaths(apiConfigs, "/project")); diff != "" {
+		t.Errorf("contractPaths() mismatch (-want +got):\n%s", diff)
+	}
+}
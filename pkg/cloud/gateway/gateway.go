@@ -19,6 +19,7 @@ package gateway
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
@@ -39,6 +40,8 @@ import (
 
 	"github.com/nitrictech/cli/pkg/cloud/apis"
 	"github.com/nitrictech/cli/pkg/cloud/batch"
+	"github.com/nitrictech/cli/pkg/cloud/errorsx"
+	"github.com/nitrictech/cli/pkg/cloud/health"
 	"github.com/nitrictech/cli/pkg/cloud/http"
 	"github.com/nitrictech/cli/pkg/cloud/schedules"
 	"github.com/nitrictech/cli/pkg/cloud/topics"
@@ -97,6 +100,11 @@ type LocalGatewayService struct {
 
 	localConfig localconfig.LocalConfiguration
 
+	// contracts is only set when API contract validation is enabled
+	contracts *contractValidator
+	// errorLogger reports contract violations against the services handling the requests
+	errorLogger errorsx.ServiceErrorLogger
+
 	logWriter io.Writer
 
 	ApiTlsCredentials *TLSCredentials
@@ -207,6 +215,18 @@ func (s *LocalGatewayService) GetWebsocketAddresses() map[string]string {
 	return addresses
 }
 
+// respondNotReady responds with 503 if a request was held back because the services handling it haven't passed their health checks
+func respondNotReady(ctx *fasthttp.RequestCtx, err error) bool {
+	if !errors.Is(err, health.ErrNotReady) {
+		return false
+	}
+
+	ctx.Response.Header.Set("Retry-After", "1")
+	ctx.Error(fmt.Sprintf("Service Unavailable: %v", err), fasthttp.StatusServiceUnavailable)
+
+	return true
+}
+
 func (s *LocalGatewayService) handleHttpProxyRequest(idx int) fasthttp.RequestHandler {
 	return func(ctx *fasthttp.RequestCtx) {
 		port := s.httpWorkers[idx]
@@ -218,6 +238,10 @@ func (s *LocalGatewayService) handleHttpProxyRequest(idx int) fasthttp.RequestHa
 		// TODO: Need to support multiple HTTP handlers
 		// so a plugin wrapper will be required for this
 		resp, err := s.options.HttpPlugin.HandleRequest(requestCopy)
+		if respondNotReady(ctx, err) {
+			return
+		}
+
 		if err != nil {
 			ctx.Error(fmt.Sprintf("Error handling HTTP Request: %v", err), 500)
 			return
@@ -275,6 +299,10 @@ func (s *LocalGatewayService) handleApiHttpRequest(apiName string) fasthttp.Requ
 		}
 
 		resp, err := s.options.ApiPlugin.HandleRequest(apiName, apiEvent)
+		if respondNotReady(ctx, err) {
+			return
+		}
+
 		if err != nil {
 			ctx.Error(fmt.Sprintf("Error handling HTTP Request: %v", err), 500)
 			return
@@ -293,11 +321,28 @@ func (s *LocalGatewayService) handleApiHttpRequest(apiName string) fasthttp.Requ
 			ctx.Response.SetStatusCode(int(http.Status))
 			ctx.Response.SetBody(resp.GetHttpResponse().GetBody())
 
+			var violations []string
+
+			if s.contracts != nil {
+				var serviceNames []string
+
+				violations, serviceNames = s.contracts.check(apiName, ctx, http)
+
+				if len(violations) > 0 && s.errorLogger != nil {
+					for _, serviceName := range serviceNames {
+						for _, violation := range violations {
+							s.errorLogger(serviceName, fmt.Errorf("contract violation in api %s on %s %s, %s", apiName, ctx.Method(), path, violation))
+						}
+					}
+				}
+			}
+
 			// publish ctx for history
 			s.apisPlugin.PublishActionState(apis.ApiRequestState{
-				Api:      apiName,
-				ReqCtx:   ctx,
-				HttpResp: http,
+				Api:        apiName,
+				ReqCtx:     ctx,
+				HttpResp:   http,
+				Violations: violations,
 			})
 
 			return
@@ -308,7 +353,6 @@ func (s *LocalGatewayService) handleApiHttpRequest(apiName string) fasthttp.Requ
 }
 
 // websocket request handler
-// TODO: Add broadcast capability
 func (s *LocalGatewayService) handleWebsocketRequest(socketName string) func(ctx *fasthttp.RequestCtx) {
 	return func(ctx *fasthttp.RequestCtx) {
 		upgrader.CheckOrigin = func(ctx *fasthttp.RequestCtx) bool {
@@ -343,6 +387,7 @@ func (s *LocalGatewayService) handleWebsocketRequest(socketName string) func(ctx
 			},
 		})
 		if err != nil {
+			respondNotReady(ctx, err)
 			return
 		}
 
@@ -366,7 +411,9 @@ func (s *LocalGatewayService) handleWebsocketRequest(socketName string) func(ctx
 				}
 			}()
 
-			err = s.websocketPlugin.RegisterConnection(socketName, connectionId, ws)
+			err = s.websocketPlugin.RegisterConnection(socketName, connectionId, ws, lo.MapValues(query, func(v *websocketspb.QueryValue, _ string) []string {
+				return v.Value
+			}))
 			if err != nil {
 				system.Logf("Websocket error: %s", err.Error())
 				return
@@ -479,6 +526,10 @@ func (s *LocalGatewayService) handleSchedulesTrigger(ctx *fasthttp.RequestCtx) {
 	}
 
 	_, err := s.schedulesPlugin.HandleRequest(msg)
+	if respondNotReady(ctx, err) {
+		return
+	}
+
 	if err != nil {
 		ctx.Error(fmt.Sprintf("Error handling schedule trigger: %v", err), 500)
 		return
@@ -556,6 +607,13 @@ func (s *LocalGatewayService) refreshApis(apiState apis.State) {
 	if err != nil {
 		system.Log(fmt.Sprintf("error creating api servers: %s", err.Error()))
 	}
+
+	if s.contracts != nil {
+		err := s.contracts.refresh(apiState)
+		if err != nil {
+			system.Log(fmt.Sprintf("error loading api contracts: %s", err.Error()))
+		}
+	}
 }
 
 func (s *LocalGatewayService) refreshHttpWorkers(state http.State) {
@@ -653,7 +711,7 @@ func (s *LocalGatewayService) createApiServers() error {
 			continue
 		}
 
-		lis, err := getListener(s.localConfig.Apis, apiName)
+		lis, err := getListener(apiName, s.localConfig.Apis[apiName].Port)
 		if err != nil {
 			return err
 		}
@@ -700,16 +758,14 @@ func (s *LocalGatewayService) apiServerExists(apiName string) bool {
 	})
 }
 
-func getListener(mapping map[string]localconfig.LocalResourceConfiguration, name string) (net.Listener, error) {
-	if config, exists := mapping[name]; exists {
-		if config.Port != 0 {
-			list, err := net.Listen("tcp", fmt.Sprintf(":%d", config.Port))
-			if err != nil {
-				return nil, fmt.Errorf("error mapping %s to port %d, %s", name, config.Port, err.Error())
-			}
-
-			return list, nil
+func getListener(name string, port int) (net.Listener, error) {
+	if port != 0 {
+		list, err := net.Listen("tcp", fmt.Sprintf(":%d", port))
+		if err != nil {
+			return nil, fmt.Errorf("error mapping %s to port %d, %s", name, port, err.Error())
 		}
+
+		return list, nil
 	}
 
 	return netx.GetNextListener()
@@ -731,7 +787,7 @@ func (s *LocalGatewayService) createWebsocketServers() error {
 				Handler:         s.handleWebsocketRequest(sock),
 			}
 
-			lis, err := getListener(s.localConfig.Websockets, sock)
+			lis, err := getListener(sock, s.localConfig.Websockets[sock].Port)
 			if err != nil {
 				return err
 			}
@@ -932,16 +988,30 @@ type NewGatewayOpts struct {
 	LogWriter      io.Writer
 	LocalConfig    localconfig.LocalConfiguration
 	BatchPlugin    *batch.LocalBatchService
+	// ProjectDirectory is the directory relative contract paths in the local config are resolved against
+	ProjectDirectory string
+	// ValidateContracts enables validation of API requests and responses against their OpenAPI contracts
+	ValidateContracts bool
+	// ErrorLogger reports contract violations against the services handling the requests
+	ErrorLogger errorsx.ServiceErrorLogger
 }
 
 // Create new HTTP gateway
 // XXX: No External Args for function atm (currently the plugin loader does not pass any argument information)
 func NewGateway(opts NewGatewayOpts) (*LocalGatewayService, error) {
+	var contracts *contractValidator
+
+	if opts.ValidateContracts {
+		contracts = newContractValidator(contractPaths(opts.LocalConfig.Apis, opts.ProjectDirectory))
+	}
+
 	return &LocalGatewayService{
 		ApiTlsCredentials: opts.TLSCredentials,
 		bus:               EventBus.New(),
 		logWriter:         opts.LogWriter,
 		localConfig:       opts.LocalConfig,
 		batchPlugin:       opts.BatchPlugin,
+		contracts:         contracts,
+		errorLogger:       opts.ErrorLogger,
 	}, nil
 }
@@ -0,0 +1,135 @@
+// Copyright Nitric Pty Ltd.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package health
+
+import (
+	"errors"
+	"slices"
+	"sync"
+
+	"github.com/asaskevich/EventBus"
+)
+
+type Status string
+
+const (
+	Status_Starting  Status = "starting"
+	Status_Healthy   Status = "healthy"
+	Status_Unhealthy Status = "unhealthy"
+)
+
+// ErrNotReady is returned when a request is held back because the services handling it haven't passed their health check
+var ErrNotReady = errors.New("service is not ready")
+
+type ReplicaHealth struct {
+	Status Status `json:"status"`
+	// Message is the reason of the last failed check
+	Message string `json:"message,omitempty"`
+}
+
+type (
+	ServiceName = string
+	// State holds the health of each replica of the services with a health check
+	State = map[ServiceName][]ReplicaHealth
+)
+
+// IsReady returns true if a replica of a service can handle requests
+type IsReady = func(serviceName string, replica int) bool
+
+type LocalHealthService struct {
+	stateLock sync.RWMutex
+	state     State
+
+	bus EventBus.Bus
+}
+
+const localHealthTopic = "local_health"
+
+func (l *LocalHealthService) publishState() {
+	l.bus.Publish(localHealthTopic, l.GetState())
+}
+
+func (l *LocalHealthService) SubscribeToState(fn func(State)) {
+	// ignore the error, it's only returned if the fn param isn't a function
+	_ = l.bus.Subscribe(localHealthTopic, fn)
+}
+
+// GetState - Returns a copy of the health of the services
+func (l *LocalHealthService) GetState() State {
+	l.stateLock.RLock()
+	defer l.stateLock.RUnlock()
+
+	state := State{}
+
+	for serviceName, replicas := range l.state {
+		state[serviceName] = slices.Clone(replicas)
+	}
+
+	return state
+}
+
+// Register - marks the replicas of a service with a health check as starting, so they aren't ready until their check passes
+func (l *LocalHealthService) Register(serviceName string, replicas int) {
+	l.stateLock.Lock()
+
+	l.state[serviceName] = make([]ReplicaHealth, replicas)
+	for i := range l.state[serviceName] {
+		l.state[serviceName][i].Status = Status_Starting
+	}
+
+	l.stateLock.Unlock()
+
+	l.publishState()
+}
+
+// SetStatus - updates the health of a replica of a registered service
+func (l *LocalHealthService) SetStatus(serviceName string, replica int, status Status, message string) {
+	l.stateLock.Lock()
+
+	replicas := l.state[serviceName]
+	if replica >= len(replicas) {
+		l.stateLock.Unlock()
+		return
+	}
+
+	replicas[replica] = ReplicaHealth{Status: status, Message: message}
+
+	l.stateLock.Unlock()
+
+	l.publishState()
+}
+
+// IsReady - returns true if a replica of a service can handle requests,
+// replicas of services without a health check are always ready
+func (l *LocalHealthService) IsReady(serviceName string, replica int) bool {
+	l.stateLock.RLock()
+	defer l.stateLock.RUnlock()
+
+	replicas, ok := l.state[serviceName]
+	if !ok || replica >= len(replicas) {
+		return true
+	}
+
+	return replicas[replica].Status == Status_Healthy
+}
+
+func NewLocalHealthService() *LocalHealthService {
+	return &LocalHealthService{
+		state: State{},
+		bus:   EventBus.New(),
+	}
+}
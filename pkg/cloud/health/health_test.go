@@ -0,0 +1,61 @@
+// Copyright Nitric Pty Ltd.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package health
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestIsReady(t *testing.T) {
+	l := NewLocalHealthService()
+
+	l.Register("services/api.ts", 2)
+	l.SetStatus("services/api.ts", 1, Status_Healthy, "")
+
+	for _, tt := range []struct {
+		serviceName string
+		replica     int
+		expected    bool
+	}{
+		{serviceName: "services/api.ts", replica: 0, expected: false},
+		{serviceName: "services/api.ts", replica: 1, expected: true},
+		{serviceName: "services/worker.ts", replica: 0, expected: true},
+	} {
+		if got := l.IsReady(tt.serviceName, tt.replica); got != tt.expected {
+			t.Errorf("IsReady(%s, %d) = %v, want %v", tt.serviceName, tt.replica, got, tt.expected)
+		}
+	}
+
+	l.SetStatus("services/api.ts", 1, Status_Unhealthy, "GET /healthz responded with 500 Internal Server Error")
+
+	want := State{
+		"services/api.ts": {
+			{Status: Status_Starting},
+			{Status: Status_Unhealthy, Message: "GET /healthz responded with 500 Internal Server Error"},
+		},
+	}
+
+	if diff := cmp.Diff(want, l.GetState()); diff != "" {
+		t.Errorf("GetState() mismatch (-want +got):\n%s", diff)
+	}
+
+	if l.IsReady("services/api.ts", 1) {
+		t.Errorf("IsReady() = true for an unhealthy replica")
+	}
+}
@@ -18,12 +18,14 @@ package http
 
 import (
 	"fmt"
-	"maps"
+	"slices"
 	"sync"
 
 	"github.com/asaskevich/EventBus"
+	"github.com/samber/lo"
 	"github.com/valyala/fasthttp"
 
+	"github.com/nitrictech/cli/pkg/cloud/health"
 	"github.com/nitrictech/cli/pkg/grpcx"
 	httppb "github.com/nitrictech/nitric/core/pkg/proto/http/v1"
 	"github.com/nitrictech/nitric/core/pkg/workers/http"
@@ -31,23 +33,34 @@ import (
 
 type HttpProxyService struct {
 	ServiceName string
+	replica     int
 	server      *http.HttpServer
 }
 
 type HostAddress = string
 
+// State maps the host each service registered its http proxy with to the service,
+// the host of the first replica of a service to register is used for all of its replicas
 type State = map[HostAddress]*HttpProxyService
 
+// proxyGroup holds the http proxies of the replicas of a service, requests are load balanced across them
+type proxyGroup struct {
+	replicas []*HttpProxyService
+	next     int
+}
+
 type LocalHttpProxy struct {
-	state          State
+	// groups holds the proxies of each service by host address
+	groups         map[HostAddress]*proxyGroup
 	httpWorkerLock sync.RWMutex
+	isReady        health.IsReady
 	bus            EventBus.Bus
 }
 
 const localHttpProxyTopic = "local_http_proxy"
 
 func (l *LocalHttpProxy) publishState() {
-	l.bus.Publish(localHttpProxyTopic, maps.Clone(l.state))
+	l.bus.Publish(localHttpProxyTopic, l.state())
 }
 
 func (l *LocalHttpProxy) SubscribeToState(fn func(State)) {
@@ -61,45 +74,97 @@ func (h *LocalHttpProxy) WorkerCount() int {
 	h.httpWorkerLock.RLock()
 	defer h.httpWorkerLock.RUnlock()
 
-	return len(h.state)
+	return lo.SumBy(lo.Values(h.groups), func(group *proxyGroup) int {
+		return len(group.replicas)
+	})
+}
+
+// state returns the proxy of the first replica of each service by host. The lock must be held by the caller.
+func (h *LocalHttpProxy) state() State {
+	state := State{}
+
+	for host, group := range h.groups {
+		state[host] = group.replicas[0]
+	}
+
+	return state
 }
 
 func (h *LocalHttpProxy) GetState() State {
 	h.httpWorkerLock.RLock()
 	defer h.httpWorkerLock.RUnlock()
 
-	return h.state
+	return h.state()
 }
 
-// FIXME: Implement http server identification
+// HandleRequest proxies a request to the next ready replica of the service registered with the host of the request
 func (h *LocalHttpProxy) HandleRequest(request *fasthttp.Request) (*fasthttp.Response, error) {
-	h.httpWorkerLock.RLock()
-	defer h.httpWorkerLock.RUnlock()
-
 	host := string(request.Host())
 
-	service, ok := h.state[host]
+	h.httpWorkerLock.Lock()
+
+	group, ok := h.groups[host]
 	if !ok {
+		h.httpWorkerLock.Unlock()
 		return nil, fmt.Errorf("no worker found for host: %s", host)
 	}
 
+	var service *HttpProxyService
+
+	for i := range len(group.replicas) {
+		replica := group.replicas[(group.next+i)%len(group.replicas)]
+
+		if h.isReady(replica.ServiceName, replica.replica) {
+			service = replica
+			group.next = (group.next + i + 1) % len(group.replicas)
+
+			break
+		}
+	}
+
+	serviceName := group.replicas[0].ServiceName
+
+	h.httpWorkerLock.Unlock()
+
+	if service == nil {
+		return nil, fmt.Errorf("%w, service %s is waiting to pass its health check", health.ErrNotReady, serviceName)
+	}
+
 	return service.server.HandleRequest(request)
 }
 
+// registerHttpProxy adds the proxy of a replica, grouping it with the other replicas of its service
 func (h *LocalHttpProxy) registerHttpProxy(host string, service *HttpProxyService) {
 	h.httpWorkerLock.Lock()
 	defer h.httpWorkerLock.Unlock()
 
-	h.state[host] = service
+	group, ok := lo.Find(lo.Values(h.groups), func(group *proxyGroup) bool {
+		return group.replicas[0].ServiceName == service.ServiceName
+	})
+	if !ok {
+		group = &proxyGroup{}
+		h.groups[host] = group
+	}
+
+	group.replicas = append(group.replicas, service)
 
 	h.publishState()
 }
 
-func (h *LocalHttpProxy) unregisterHttpProxy(host string) {
+// unregisterHttpProxy removes the proxy of a replica, removing the group once its last replica has stopped
+func (h *LocalHttpProxy) unregisterHttpProxy(service *HttpProxyService) {
 	h.httpWorkerLock.Lock()
 	defer h.httpWorkerLock.Unlock()
 
-	delete(h.state, host)
+	for host, group := range h.groups {
+		group.replicas = slices.DeleteFunc(group.replicas, func(replica *HttpProxyService) bool {
+			return replica == service
+		})
+
+		if len(group.replicas) == 0 {
+			delete(h.groups, host)
+		}
+	}
 
 	h.publishState()
 }
@@ -124,21 +189,25 @@ func (h *LocalHttpProxy) Proxy(stream httppb.Http_ProxyServer) error {
 	host := firstRequest.Request.GetHost()
 	srv := http.New()
 
-	h.registerHttpProxy(host, &HttpProxyService{
+	service := &HttpProxyService{
 		server:      srv,
 		ServiceName: serviceName,
-	})
-	defer h.unregisterHttpProxy(host)
+		replica:     grpcx.GetServiceReplicaFromStream(stream),
+	}
+
+	h.registerHttpProxy(host, service)
+	defer h.unregisterHttpProxy(service)
 
 	// pass down the the original handler for port watching and management
 	// let the proxy manage the connection
 	return srv.Proxy(peekableStream)
 }
 
-func NewLocalHttpProxyService() *LocalHttpProxy {
+func NewLocalHttpProxyService(isReady health.IsReady) *LocalHttpProxy {
 	return &LocalHttpProxy{
-		state:          make(State),
+		groups:         map[HostAddress]*proxyGroup{},
 		httpWorkerLock: sync.RWMutex{},
+		isReady:        isReady,
 		bus:            EventBus.New(),
 	}
 }
@@ -0,0 +1,64 @@
+// Copyright Nitric Pty Ltd.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package http
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/samber/lo"
+)
+
+func TestRegisterReplicas(t *testing.T) {
+	proxy := NewLocalHttpProxyService(func(string, int) bool { return true })
+
+	first := &HttpProxyService{ServiceName: "services/api.ts", replica: 0}
+	second := &HttpProxyService{ServiceName: "services/api.ts", replica: 1}
+	other := &HttpProxyService{ServiceName: "services/web.ts", replica: 0}
+
+	proxy.registerHttpProxy("localhost:4001", first)
+	proxy.registerHttpProxy("localhost:4002", second)
+	proxy.registerHttpProxy("localhost:4003", other)
+
+	hosts := func() map[string]string {
+		return lo.MapValues(proxy.GetState(), func(service *HttpProxyService, _ string) string {
+			return service.ServiceName
+		})
+	}
+
+	want := map[string]string{"localhost:4001": "services/api.ts", "localhost:4003": "services/web.ts"}
+	if diff := cmp.Diff(want, hosts()); diff != "" {
+		t.Errorf("GetState() mismatch (-want +got):\n%s", diff)
+	}
+
+	if proxy.WorkerCount() != 3 {
+		t.Errorf("WorkerCount() = %d, want 3", proxy.WorkerCount())
+	}
+
+	// stopping one replica keeps the service routed to the remaining replica
+	proxy.unregisterHttpProxy(first)
+
+	if diff := cmp.Diff(want, hosts()); diff != "" {
+		t.Errorf("GetState() after stopping a replica mismatch (-want +got):\n%s", diff)
+	}
+
+	proxy.unregisterHttpProxy(second)
+
+	if diff := cmp.Diff(map[string]string{"localhost:4003": "services/web.ts"}, hosts()); diff != "" {
+		t.Errorf("GetState() after stopping all replicas mismatch (-want +got):\n%s", diff)
+	}
+}
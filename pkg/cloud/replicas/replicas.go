@@ -0,0 +1,101 @@
+// Copyright Nitric Pty Ltd.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package replicas
+
+import (
+	"slices"
+	"sync"
+
+	"github.com/samber/lo"
+)
+
+// Pool holds a worker manager for each replica of each service, so requests can be load balanced across the replicas of a service
+type Pool[M any] struct {
+	lock       sync.Mutex
+	managers   map[string][]M
+	next       map[string]int
+	newManager func() M
+}
+
+// Manager returns the worker manager of a replica of a service, creating it on first use
+func (p *Pool[M]) Manager(serviceName string, replica int) M {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	for len(p.managers[serviceName]) <= replica {
+		p.managers[serviceName] = append(p.managers[serviceName], p.newManager())
+	}
+
+	return p.managers[serviceName][replica]
+}
+
+// Services returns the names of the services with worker managers, sorted by name
+func (p *Pool[M]) Services() []string {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	services := lo.Keys(p.managers)
+	slices.Sort(services)
+
+	return services
+}
+
+// All returns the worker managers of every replica of every service
+func (p *Pool[M]) All() []M {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	return lo.Flatten(lo.Values(p.managers))
+}
+
+// Replica is the worker manager of a replica of a service
+type Replica[M any] struct {
+	Index   int
+	Manager M
+}
+
+// Replicas returns the worker managers of the replicas of a service in round robin order,
+// starting with the replica after the one that started the previous call
+func (p *Pool[M]) Replicas(serviceName string) []Replica[M] {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	managers := p.managers[serviceName]
+	if len(managers) == 0 {
+		return []Replica[M]{}
+	}
+
+	start := p.next[serviceName] % len(managers)
+	p.next[serviceName] = start + 1
+
+	replicas := []Replica[M]{}
+
+	for i := range managers {
+		index := (start + i) % len(managers)
+		replicas = append(replicas, Replica[M]{Index: index, Manager: managers[index]})
+	}
+
+	return replicas
+}
+
+func NewPool[M any](newManager func() M) *Pool[M] {
+	return &Pool[M]{
+		managers:   map[string][]M{},
+		next:       map[string]int{},
+		newManager: newManager,
+	}
+}
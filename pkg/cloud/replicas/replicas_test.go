@@ -0,0 +1,75 @@
+// Copyright Nitric Pty Ltd.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package replicas
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+type manager struct {
+	id int
+}
+
+func TestReplicas(t *testing.T) {
+	created := 0
+
+	pool := NewPool(func() *manager {
+		created++
+		return &manager{id: created}
+	})
+
+	first := pool.Manager("services/api.ts", 0)
+	second := pool.Manager("services/api.ts", 1)
+	pool.Manager("services/worker.ts", 0)
+
+	if pool.Manager("services/api.ts", 0) != first {
+		t.Errorf("Manager() returned a new manager for an existing replica")
+	}
+
+	if diff := cmp.Diff([]string{"services/api.ts", "services/worker.ts"}, pool.Services()); diff != "" {
+		t.Errorf("Services() mismatch (-want +got):\n%s", diff)
+	}
+
+	ids := func(replicas []Replica[*manager]) []int {
+		result := []int{}
+		for _, replica := range replicas {
+			if replica.Manager != pool.Manager("services/api.ts", replica.Index) {
+				t.Errorf("Replicas() returned index %d for the manager of another replica", replica.Index)
+			}
+
+			result = append(result, replica.Manager.id)
+		}
+
+		return result
+	}
+
+	for _, want := range [][]int{{first.id, second.id}, {second.id, first.id}, {first.id, second.id}} {
+		if diff := cmp.Diff(want, ids(pool.Replicas("services/api.ts"))); diff != "" {
+			t.Errorf("Replicas() mismatch (-want +got):\n%s", diff)
+		}
+	}
+
+	if len(pool.Replicas("services/missing.ts")) != 0 {
+		t.Errorf("Replicas() returned managers for an unknown service")
+	}
+
+	if len(pool.All()) != 3 {
+		t.Errorf("All() = %d managers, want 3", len(pool.All()))
+	}
+}
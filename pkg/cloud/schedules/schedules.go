@@ -17,6 +17,7 @@
 package schedules
 
 import (
+	"errors"
 	"fmt"
 	"maps"
 	"strconv"
@@ -27,6 +28,7 @@ import (
 	"github.com/robfig/cron/v3"
 
 	"github.com/nitrictech/cli/pkg/cloud/errorsx"
+	"github.com/nitrictech/cli/pkg/cloud/health"
 	"github.com/nitrictech/cli/pkg/grpcx"
 	"github.com/nitrictech/cli/pkg/validation"
 	"github.com/nitrictech/nitric/core/pkg/logger"
@@ -43,6 +45,10 @@ type (
 type ScheduledService struct {
 	ServiceName serviceName
 	Schedule    *schedulespb.RegistrationRequest
+
+	replica int
+	// released is closed when the replica running the schedule stops, so a standby replica can take it over
+	released chan struct{}
 }
 
 type State = map[scheduleName]*ScheduledService
@@ -59,6 +65,7 @@ type LocalSchedulesService struct {
 	schedulesLock sync.RWMutex
 
 	errorLogger errorsx.ServiceErrorLogger
+	isReady     health.IsReady
 
 	schedules State
 	bus       EventBus.Bus
@@ -95,7 +102,9 @@ func (l *LocalSchedulesService) GetSchedules() State {
 	return l.schedules
 }
 
-func (l *LocalSchedulesService) registerSchedule(serviceName string, registrationRequest *schedulespb.RegistrationRequest) error {
+// registerSchedule registers a schedule for a replica of a service. If another replica of the service already runs the schedule,
+// the schedule isn't registered and the returned channel is closed once that replica stops
+func (l *LocalSchedulesService) registerSchedule(serviceName string, replica int, registrationRequest *schedulespb.RegistrationRequest) (<-chan struct{}, error) {
 	l.schedulesLock.Lock()
 	defer l.schedulesLock.Unlock()
 
@@ -105,39 +114,54 @@ func (l *LocalSchedulesService) registerSchedule(serviceName string, registratio
 			fmt.Errorf("invalid name: \"%s\" for %s resource", registrationRequest.ScheduleName, resourcespb.ResourceType_Schedule),
 		)
 
-		return nil
+		return nil, nil
 	}
 
 	if l.schedules[registrationRequest.ScheduleName] != nil {
 		existing := l.schedules[registrationRequest.ScheduleName]
 
-		return fmt.Errorf("conflict: schedule \"%s\" already taken by service %s", existing.Schedule.ScheduleName, existing.ServiceName)
+		if existing.ServiceName == serviceName {
+			return existing.released, nil
+		}
+
+		return nil, fmt.Errorf("conflict: schedule \"%s\" already taken by service %s", existing.Schedule.ScheduleName, existing.ServiceName)
 	}
 
 	l.schedules[registrationRequest.ScheduleName] = &ScheduledService{
 		ServiceName: serviceName,
 		Schedule:    registrationRequest,
+		replica:     replica,
+		released:    make(chan struct{}),
 	}
 
 	l.publishState()
 
-	return nil
+	return nil, nil
 }
 
 func (l *LocalSchedulesService) unregisterSchedule(serviceName string, registrationRequest *schedulespb.RegistrationRequest) {
 	l.schedulesLock.Lock()
 	defer l.schedulesLock.Unlock()
 
+	if existing := l.schedules[registrationRequest.ScheduleName]; existing != nil {
+		close(existing.released)
+	}
+
 	delete(l.schedules, registrationRequest.ScheduleName)
 
 	l.publishState()
 }
 
+// HandleRequest runs a schedule, returning health.ErrNotReady if the service replica running it hasn't passed its health check
 func (l *LocalSchedulesService) HandleRequest(request *schedulespb.ServerMessage) (*schedulespb.ClientMessage, error) {
-	resp, err := l.ScheduleWorkerManager.HandleRequest(request)
-
 	scheduleName := request.GetIntervalRequest().ScheduleName
 
+	if scheduled := l.GetSchedules()[scheduleName]; scheduled != nil && !l.isReady(scheduled.ServiceName, scheduled.replica) {
+		return nil, fmt.Errorf("%w, schedule %s is waiting for service %s to pass its health check", health.ErrNotReady, scheduleName, scheduled.ServiceName)
+	}
+
+	resp, err := l.ScheduleWorkerManager.HandleRequest(request)
+
 	l.publishAction(ActionState{ScheduleName: scheduleName, Success: true})
 
 	return resp, err
@@ -152,7 +176,9 @@ func (l *LocalSchedulesService) createCronSchedule(scheduleName, expression stri
 				},
 			},
 		})
-		if err != nil {
+		if errors.Is(err, health.ErrNotReady) {
+			logger.Debugf("Skipping schedule: %s", err.Error())
+		} else if err != nil {
 			logger.Errorf("Error handling schedule: %s", err.Error())
 		}
 	})
@@ -175,10 +201,24 @@ func (l *LocalSchedulesService) Schedule(stream schedulespb.Schedules_ScheduleSe
 		return fmt.Errorf("first request must be a registration request")
 	}
 
-	err = l.registerSchedule(serviceName, firstRequest.GetRegistrationRequest())
-	if err != nil {
-		l.errorLogger(serviceName, err)
-		return nil
+	for {
+		released, err := l.registerSchedule(serviceName, grpcx.GetServiceReplicaFromStream(stream), firstRequest.GetRegistrationRequest())
+		if err != nil {
+			l.errorLogger(serviceName, err)
+			return nil
+		}
+
+		if released == nil {
+			break
+		}
+
+		// another replica of the service already runs this schedule, keep this one connected as a standby so each schedule runs once,
+		// it takes over the schedule if that replica stops
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case <-released:
+		}
 	}
 
 	defer l.unregisterSchedule(serviceName, firstRequest.GetRegistrationRequest())
@@ -226,9 +266,10 @@ func (l *LocalSchedulesService) Schedule(stream schedulespb.Schedules_ScheduleSe
 	return l.ScheduleWorkerManager.Schedule(peekableStream)
 }
 
-func NewLocalSchedulesService(errorLogger errorsx.ServiceErrorLogger) *LocalSchedulesService {
+func NewLocalSchedulesService(errorLogger errorsx.ServiceErrorLogger, isReady health.IsReady) *LocalSchedulesService {
 	return &LocalSchedulesService{
 		errorLogger:           errorLogger,
+		isReady:               isReady,
 		ScheduleWorkerManager: schedules.New(),
 		cron:                  cron.New(),
 		bus:                   EventBus.New(),
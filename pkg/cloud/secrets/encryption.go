@@ -0,0 +1,96 @@
+// Copyright Nitric Pty Ltd.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package secrets
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// encryptedPrefix marks secret values encrypted with the local secrets key, values without it are legacy base64 plaintext
+const encryptedPrefix = "enc:v1:"
+
+func (s *DevSecretService) gcm() (cipher.AEAD, error) {
+	block, err := aes.NewCipher(s.key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
+
+// encrypt returns the stored form of a secret value
+func (s *DevSecretService) encrypt(value []byte) (string, error) {
+	gcm, err := s.gcm()
+	if err != nil {
+		return "", err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+
+	return encryptedPrefix + base64.StdEncoding.EncodeToString(gcm.Seal(nonce, nonce, value, nil)), nil
+}
+
+// decrypt returns the secret value of a stored value, legacy plaintext values are decoded as base64
+func (s *DevSecretService) decrypt(stored string) ([]byte, error) {
+	encoded, encrypted := strings.CutPrefix(stored, encryptedPrefix)
+
+	data, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil || !encrypted {
+		return data, err
+	}
+
+	gcm, err := s.gcm()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(data) < gcm.NonceSize() {
+		return nil, errors.New("encrypted secret value is too short")
+	}
+
+	value, err := gcm.Open(nil, data[:gcm.NonceSize()], data[gcm.NonceSize():], nil)
+	if err != nil {
+		return nil, fmt.Errorf("unable to decrypt secret value, it may have been encrypted with a different local secrets key: %w", err)
+	}
+
+	return value, nil
+}
+
+// checkNotTracked returns an error if any secret files are tracked by git, it is skipped when git isn't available or the project isn't a repository
+func checkNotTracked(secDir string) error {
+	output, err := exec.Command("git", "ls-files", "--", secDir).Output()
+	if err != nil {
+		return nil
+	}
+
+	tracked := strings.Fields(string(output))
+	if len(tracked) == 0 {
+		return nil
+	}
+
+	return fmt.Errorf("local secret files are tracked in git (%s), remove them with 'git rm -r --cached %s' and add .nitric/ to your .gitignore", strings.Join(tracked, ", "), secDir)
+}
@@ -0,0 +1,198 @@
+// Copyright Nitric Pty Ltd.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package secrets
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"go.etcd.io/bbolt"
+)
+
+// legacyVersion is a secret version stored as a <name>_<version>.txt file by earlier versions of the CLI
+type legacyVersion struct {
+	file    string
+	version string
+	info    os.FileInfo
+	value   []byte
+}
+
+// migrate imports the secret files of earlier versions of the CLI into the secret store, ordering versions by
+// their modification time. Plaintext values are encrypted as they are imported and the files are only removed
+// once the imported versions have been read back from the store.
+func (s *DevSecretService) migrate() error {
+	files, err := filepath.Glob(filepath.Join(s.secDir, "*.txt"))
+	if err != nil || len(files) == 0 {
+		return err
+	}
+
+	secrets := map[string][]legacyVersion{}
+	latest := map[string]string{}
+	migrated := []string{}
+
+	for _, file := range files {
+		// secret names may contain underscores but versions are UUIDs, so the version follows the last underscore
+		base := strings.TrimSuffix(filepath.Base(file), ".txt")
+
+		separator := strings.LastIndex(base, "_")
+		if separator <= 0 {
+			continue
+		}
+
+		name, version := base[:separator], base[separator+1:]
+
+		migrated = append(migrated, file)
+
+		if version == "latest" {
+			content, err := os.ReadFile(file)
+			if err != nil {
+				return err
+			}
+
+			// the latest file holds value,version and the value may contain commas
+			if comma := strings.LastIndex(string(content), ","); comma >= 0 {
+				latest[name] = string(content[comma+1:])
+			}
+
+			continue
+		}
+
+		info, err := os.Stat(file)
+		if err != nil {
+			return err
+		}
+
+		content, err := os.ReadFile(file)
+		if err != nil {
+			return err
+		}
+
+		value, err := s.decrypt(string(content))
+		if err != nil {
+			return fmt.Errorf("unable to migrate secret file %s: %w", file, err)
+		}
+
+		secrets[name] = append(secrets[name], legacyVersion{file: file, version: version, info: info, value: value})
+	}
+
+	latestVersions := map[string]string{}
+
+	err = s.update(func(tx *bbolt.Tx) error {
+		for name, legacyVersions := range secrets {
+			sort.Slice(legacyVersions, func(i, j int) bool {
+				return legacyVersions[i].info.ModTime().Before(legacyVersions[j].info.ModTime())
+			})
+
+			secret, err := createSecretBucket(tx, name)
+			if err != nil {
+				return err
+			}
+
+			// the latest file refers to its version, the newest version is latest if it doesn't
+			latestVersion := legacyVersions[len(legacyVersions)-1].version
+
+			for _, legacy := range legacyVersions {
+				encrypted, err := s.encrypt(legacy.value)
+				if err != nil {
+					return err
+				}
+
+				record := versionRecord{
+					Version:   legacy.version,
+					Value:     encrypted,
+					CreatedAt: legacy.info.ModTime(),
+					State:     VersionState_Enabled,
+				}
+
+				record.Seq, err = secret.Bucket(versionsBucket).NextSequence()
+				if err != nil {
+					return err
+				}
+
+				if err := putVersion(secret, record); err != nil {
+					return err
+				}
+
+				if legacy.version == latest[name] {
+					latestVersion = legacy.version
+				}
+			}
+
+			if err := secret.Put(latestKey, []byte(latestVersion)); err != nil {
+				return err
+			}
+
+			latestVersions[name] = latestVersion
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := s.verifyMigration(secrets, latestVersions); err != nil {
+		return fmt.Errorf("%w, the secret files in %s have been kept", err, s.secDir)
+	}
+
+	for _, file := range migrated {
+		if err := os.Remove(file); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// verifyMigration reads the migrated versions back from the store, checking their values and latest pointers match the legacy files
+func (s *DevSecretService) verifyMigration(secrets map[string][]legacyVersion, latestVersions map[string]string) error {
+	return s.view(func(tx *bbolt.Tx) error {
+		for name, legacyVersions := range secrets {
+			secret := tx.Bucket([]byte(name))
+
+			for _, legacy := range legacyVersions {
+				record, err := findVersion(secret, legacy.version)
+				if err != nil {
+					return err
+				}
+
+				if record == nil {
+					return fmt.Errorf("unable to migrate secret file %s: version %s was not stored", legacy.file, legacy.version)
+				}
+
+				value, err := s.decrypt(record.Value)
+				if err != nil {
+					return fmt.Errorf("unable to migrate secret file %s: %w", legacy.file, err)
+				}
+
+				if !bytes.Equal(value, legacy.value) {
+					return fmt.Errorf("unable to migrate secret file %s: the stored value doesn't match", legacy.file)
+				}
+			}
+
+			if string(secret.Get(latestKey)) != latestVersions[name] {
+				return fmt.Errorf("unable to migrate secret %s: the latest version wasn't stored", name)
+			}
+		}
+
+		return nil
+	})
+}
@@ -17,84 +17,73 @@
 package secrets
 
 import (
-	"bufio"
 	"context"
-	"encoding/base64"
 	"fmt"
-	"io"
 	"os"
-	"path/filepath"
-	"sort"
 	"strings"
 	"sync"
+	"time"
 	"unicode/utf8"
 
 	"github.com/google/uuid"
+	"github.com/spf13/afero"
+	"go.etcd.io/bbolt"
 	"google.golang.org/grpc/codes"
 
 	"github.com/nitrictech/cli/pkg/cloud/env"
+	"github.com/nitrictech/cli/pkg/provider/pulumi"
 	grpc_errors "github.com/nitrictech/nitric/core/pkg/grpc/errors"
 	secretspb "github.com/nitrictech/nitric/core/pkg/proto/secrets/v1"
 )
 
 type DevSecretService struct {
 	secDir string
-	mu     sync.RWMutex
+	// key encrypts secret values at rest, see encryption.go
+	key []byte
+	mu  sync.RWMutex
 }
 
 var _ secretspb.SecretManagerServer = (*DevSecretService)(nil)
 
-func (s *DevSecretService) secretFileName(sec *secretspb.Secret, v string) string {
-	filename := fmt.Sprintf("%s_%s.txt", sec.Name, v)
-	return filepath.Join(s.secDir, filename)
-}
-
 func (s *DevSecretService) Put(ctx context.Context, req *secretspb.SecretPutRequest) (*secretspb.SecretPutResponse, error) {
 	newErr := grpc_errors.ErrorsWithScope(
 		"DevSecretService.Put",
 	)
 
-	versionId := uuid.New().String()
-	// Creates a new file in the form:
-	// DIR/Name_Version.txt
-	file, err := os.Create(s.secretFileName(req.Secret, versionId))
+	sVal, err := s.encrypt(req.Value)
 	if err != nil {
 		return nil, newErr(
-			codes.FailedPrecondition,
-			"error creating secret store",
+			codes.Internal,
+			"error encrypting secret value",
 			err,
 		)
 	}
 
-	sVal := base64.StdEncoding.EncodeToString(req.Value)
-
-	writer := bufio.NewWriter(file)
-
-	_, err = writer.WriteString(sVal)
-	if err != nil {
-		return nil, newErr(
-			codes.FailedPrecondition,
-			"error writing secret value",
-			err,
-		)
+	record := versionRecord{
+		Version:   uuid.New().String(),
+		Value:     sVal,
+		CreatedAt: time.Now(),
+		State:     VersionState_Enabled,
 	}
 
-	writer.Flush()
-	file.Close()
+	// the version and the latest pointer are written in a single transaction
+	err = s.update(func(tx *bbolt.Tx) error {
+		secret, err := createSecretBucket(tx, req.Secret.Name)
+		if err != nil {
+			return err
+		}
 
-	// Creates a new file as latest
-	latestFile, err := os.Create(s.secretFileName(req.Secret, "latest"))
-	if err != nil {
-		return nil, newErr(
-			codes.FailedPrecondition,
-			"error creating latest secret",
-			err,
-		)
-	}
+		record.Seq, err = secret.Bucket(versionsBucket).NextSequence()
+		if err != nil {
+			return err
+		}
 
-	latestWriter := bufio.NewWriter(latestFile)
+		if err := putVersion(secret, record); err != nil {
+			return err
+		}
 
-	_, err = latestWriter.WriteString(sVal + "," + versionId)
+		return secret.Put(latestKey, []byte(record.Version))
+	})
 	if err != nil {
 		return nil, newErr(
 			codes.FailedPrecondition,
@@ -103,36 +92,29 @@ func (s *DevSecretService) Put(ctx context.Context, req *secretspb.SecretPutRequ
 		)
 	}
 
-	latestWriter.Flush()
-	latestFile.Close()
-
 	return &secretspb.SecretPutResponse{
 		SecretVersion: &secretspb.SecretVersion{
 			Secret:  req.Secret,
-			Version: versionId,
+			Version: record.Version,
 		},
 	}, nil
 }
 
 func (s *DevSecretService) Access(ctx context.Context, req *secretspb.SecretAccessRequest) (*secretspb.SecretAccessResponse, error) {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
-
 	newErr := grpc_errors.ErrorsWithScope(
 		"DevSecretService.Access",
 	)
 
-	content, err := os.ReadFile(s.secretFileName(req.SecretVersion.Secret, req.SecretVersion.Version))
-	if err != nil {
-		// If the file is missing it's typically because it hasn't been created yet
-		if os.IsNotExist(err) {
-			return nil, newErr(
-				codes.NotFound,
-				"failed to retrieve secret value, ensure a value has been stored using the `put` method, before attempting to access it",
-				err,
-			)
-		}
+	var record *versionRecord
 
+	err := s.view(func(tx *bbolt.Tx) error {
+		var err error
+
+		record, err = findVersion(tx.Bucket([]byte(req.SecretVersion.Secret.Name)), req.SecretVersion.Version)
+
+		return err
+	})
+	if err != nil {
 		return nil, newErr(
 			codes.Unknown,
 			"error reading secret store",
@@ -140,14 +122,24 @@ func (s *DevSecretService) Access(ctx context.Context, req *secretspb.SecretAcce
 		)
 	}
 
-	splitContent := strings.Split(string(content), ",")
-	version := req.SecretVersion.Version
-	// check whether a version number is stored in the file, this indicates the 'latest' version file.
-	if len(splitContent) == 2 {
-		version = splitContent[1]
+	// If the version is missing it's typically because it hasn't been created yet
+	if record == nil {
+		return nil, newErr(
+			codes.NotFound,
+			"failed to retrieve secret value, ensure a value has been stored using the `put` method, before attempting to access it",
+			nil,
+		)
 	}
 
-	sVal, err := base64.StdEncoding.DecodeString(splitContent[0])
+	if record.State != VersionState_Enabled {
+		return nil, newErr(
+			codes.FailedPrecondition,
+			fmt.Sprintf("secret version %s is %s", record.Version, record.State),
+			nil,
+		)
+	}
+
+	sVal, err := s.decrypt(record.Value)
 	if err != nil {
 		return nil, err
 	}
@@ -155,17 +147,33 @@ func (s *DevSecretService) Access(ctx context.Context, req *secretspb.SecretAcce
 	return &secretspb.SecretAccessResponse{
 		SecretVersion: &secretspb.SecretVersion{
 			Secret:  req.SecretVersion.Secret,
-			Version: version,
+			Version: record.Version,
 		},
 		Value: sVal,
 	}, nil
 }
 
+// ResolveEnvRef returns the value of a version of a secret, it is used to resolve secret:// references in env files
+func (s *DevSecretService) ResolveEnvRef(name string, version string) (string, error) {
+	resp, err := s.Access(context.Background(), &secretspb.SecretAccessRequest{
+		SecretVersion: &secretspb.SecretVersion{
+			Secret:  &secretspb.Secret{Name: name},
+			Version: version,
+		},
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return string(resp.Value), nil
+}
+
 type SecretVersion struct {
-	Version   string `json:"version"`
-	Value     string `json:"value"`
-	Latest    bool   `json:"latest"`
-	CreatedAt string `json:"createdAt"`
+	Version   string       `json:"version"`
+	Value     string       `json:"value"`
+	Latest    bool         `json:"latest"`
+	CreatedAt string       `json:"createdAt"`
+	State     VersionState `json:"state"`
 }
 
 // formatUint8Array formats a byte array as a hexadecimal string with a space between each byte.
@@ -186,193 +194,214 @@ func formatUint8Array(byteArray []byte) string {
 	return strings.ToUpper(result)
 }
 
-// List all secret versions and values for a given secret, used by dashboard
+// List all secret versions and values for a given secret, newest first, used by dashboard
 func (s *DevSecretService) List(ctx context.Context, secretName string) ([]SecretVersion, error) {
 	newErr := grpc_errors.ErrorsWithScope(
 		"DevSecretService.List",
 	)
 
-	// Check whether file exists
-	_, err := os.Stat(s.secDir)
-	if os.IsNotExist(err) {
-		return nil, newErr(codes.NotFound, "secret store not found", err)
-	}
+	var records []versionRecord
 
-	// List all files in the directory
-	files, err := os.ReadDir(s.secDir)
-	if err != nil {
-		return nil, newErr(codes.FailedPrecondition, "error reading secret store", err)
-	}
+	latest := ""
 
-	// Create a response
-	resp := []SecretVersion{}
+	err := s.view(func(tx *bbolt.Tx) error {
+		secret := tx.Bucket([]byte(secretName))
+		if secret == nil {
+			return nil
+		}
 
-	var latestVersion SecretVersion
+		latest = string(secret.Get(latestKey))
 
-	for _, file := range files {
-		// Check whether the file is a secret file
-		if strings.HasSuffix(file.Name(), ".txt") {
-			// Split the file name to get the secret name and version
-			splitName := strings.Split(file.Name(), "_")
-			// Check whether the secret name matches the requested secret
-			if splitName[0] == secretName {
-				version := strings.TrimSuffix(splitName[1], ".txt")
+		var err error
 
-				info, err := file.Info()
-				if err != nil {
-					return nil, newErr(codes.FailedPrecondition, "error reading file info", err)
-				}
+		records, err = versions(secret)
 
-				createdAt := info.ModTime().Format("2006-01-02 15:04:05")
-
-				valueResp, err := s.Access(ctx, &secretspb.SecretAccessRequest{
-					SecretVersion: &secretspb.SecretVersion{
-						Secret:  &secretspb.Secret{Name: secretName},
-						Version: version,
-					},
-				})
-				if err != nil {
-					// check if not found and add blank value
-					if strings.HasPrefix(err.Error(), "rpc error: code = NotFound desc") {
-						resp = append(resp, SecretVersion{
-							Version:   version,
-							Value:     "",
-							CreatedAt: createdAt,
-						})
-
-						continue
-					}
-
-					return nil, newErr(codes.FailedPrecondition, "error reading version value", err)
-				}
+		return err
+	})
+	if err != nil {
+		return nil, newErr(codes.FailedPrecondition, "error reading secret store", err)
+	}
 
-				var value string
+	resp := []SecretVersion{}
 
-				if utf8.Valid(valueResp.Value) {
-					value = string(valueResp.Value)
-				} else {
-					value = formatUint8Array(valueResp.Value)
-				}
+	for i := len(records) - 1; i >= 0; i-- {
+		record := records[i]
 
-				// Check whether the version is the latest
-				if version == "latest" {
-					latestVersion = SecretVersion{
-						Value: value,
-					}
+		var value string
 
-					continue
-				}
+		if record.State != VersionState_Destroyed {
+			decrypted, err := s.decrypt(record.Value)
+			if err != nil {
+				return nil, newErr(codes.FailedPrecondition, "error reading version value", err)
+			}
 
-				// Add the secret to the response
-				resp = append(resp, SecretVersion{
-					Version:   version,
-					Value:     value,
-					CreatedAt: createdAt,
-				})
+			if utf8.Valid(decrypted) {
+				value = string(decrypted)
+			} else {
+				value = formatUint8Array(decrypted)
 			}
 		}
-	}
 
-	if len(resp) > 0 {
-		// sort by created at
-		sort.Slice(resp, func(i, j int) bool {
-			return resp[i].CreatedAt > resp[j].CreatedAt
+		resp = append(resp, SecretVersion{
+			Version:   record.Version,
+			Value:     value,
+			Latest:    record.Version == latest,
+			CreatedAt: record.CreatedAt.Format("2006-01-02 15:04:05"),
+			State:     record.State,
 		})
-
-		// mark latest version
-		if resp[0].Value == latestVersion.Value {
-			resp[0].Latest = true
-		}
 	}
 
 	return resp, nil
 }
 
-// Delete a secret version, used by dashboard
-func (s *DevSecretService) Delete(ctx context.Context, secretName string, version string, latest bool) error {
-	s.mu.Lock()
-	defer s.mu.Unlock()
-
+// Delete a secret version, if it's the latest version the newest enabled version becomes the latest, used by dashboard
+func (s *DevSecretService) Delete(ctx context.Context, secretName string, version string) error {
 	newErr := grpc_errors.ErrorsWithScope(
 		"DevSecretService.Delete",
 	)
 
-	// Check whether file exists
-	_, err := os.Stat(s.secDir)
-	if os.IsNotExist(err) {
-		return newErr(codes.NotFound, "secret store not found", err)
-	}
+	err := s.update(func(tx *bbolt.Tx) error {
+		secret := tx.Bucket([]byte(secretName))
 
-	// delete the version file
-	err = os.Remove(s.secretFileName(&secretspb.Secret{Name: secretName}, version))
+		record, err := findVersion(secret, version)
+		if err != nil {
+			return err
+		}
+
+		if record == nil {
+			return fmt.Errorf("secret %s has no version %s", secretName, version)
+		}
+
+		if err := secret.Bucket(versionsBucket).Delete(seqKey(record.Seq)); err != nil {
+			return err
+		}
+
+		// Stats aren't updated until the transaction commits, so check for remaining versions with a cursor
+		if key, _ := secret.Bucket(versionsBucket).Cursor().First(); key == nil {
+			return tx.DeleteBucket([]byte(secretName))
+		}
+
+		return repointLatest(secret, record.Version)
+	})
 	if err != nil {
 		return newErr(codes.Internal, "error deleting secret version", err)
 	}
 
-	if latest {
-		// delete the latest file
-		err = os.Remove(s.secretFileName(&secretspb.Secret{Name: secretName}, "latest"))
+	return nil
+}
+
+// SetState enables, disables or destroys a secret version. Destroyed versions lose their value and can't be enabled again.
+// If the latest version is disabled or destroyed, the newest enabled version becomes the latest. Used by the CLI.
+func (s *DevSecretService) SetState(ctx context.Context, secretName string, version string, state VersionState) error {
+	return s.update(func(tx *bbolt.Tx) error {
+		secret := tx.Bucket([]byte(secretName))
+
+		record, err := findVersion(secret, version)
 		if err != nil {
-			return newErr(codes.Internal, "error deleting latest secret version", err)
+			return err
 		}
 
-		// get last latest version and create a new latest
-		entries, err := os.ReadDir(s.secDir)
-		if err != nil {
-			return newErr(codes.FailedPrecondition, "error reading secret store", err)
+		if record == nil {
+			return fmt.Errorf("secret %s has no version %s", secretName, version)
 		}
 
-		var files []os.FileInfo
+		if record.State == VersionState_Destroyed && state != VersionState_Destroyed {
+			return fmt.Errorf("secret version %s is destroyed and can't be %s", record.Version, state)
+		}
+
+		record.State = state
 
-		for _, entry := range entries {
-			info, err := entry.Info()
+		if state == VersionState_Destroyed {
+			record.Value = ""
+		}
+
+		if err := putVersion(secret, *record); err != nil {
+			return err
+		}
+
+		if state == VersionState_Enabled && secret.Get(latestKey) == nil {
+			return secret.Put(latestKey, []byte(record.Version))
+		}
+
+		if state != VersionState_Enabled {
+			return repointLatest(secret, record.Version)
+		}
+
+		return nil
+	})
+}
+
+// Rollback makes an existing version the latest version of a secret, the newest enabled version before the current latest version is used when no version is provided.
+// Used by the CLI, it returns the version that is now latest.
+func (s *DevSecretService) Rollback(ctx context.Context, secretName string, version string) (string, error) {
+	err := s.update(func(tx *bbolt.Tx) error {
+		secret := tx.Bucket([]byte(secretName))
+		if secret == nil {
+			return fmt.Errorf("secret %s not found", secretName)
+		}
+
+		if version == "" {
+			current, err := findVersion(secret, "latest")
 			if err != nil {
-				return newErr(codes.FailedPrecondition, "error reading file info", err)
+				return err
 			}
 
-			files = append(files, info)
-		}
+			if current == nil {
+				return fmt.Errorf("secret %s has no latest version", secretName)
+			}
 
-		// sort files by date
-		sort.Slice(files, func(i, j int) bool {
-			return files[i].ModTime().Before(files[j].ModTime())
-		})
+			records, err := versions(secret)
+			if err != nil {
+				return err
+			}
 
-		for _, file := range files {
-			if strings.HasSuffix(file.Name(), ".txt") {
-				splitName := strings.Split(file.Name(), "_")
-				if splitName[0] == secretName {
-					version := strings.TrimSuffix(splitName[1], ".txt")
-
-					// copy file as new latest file with same contents
-					destinationFile, err := os.Create(s.secretFileName(&secretspb.Secret{Name: secretName}, "latest"))
-					if err != nil {
-						return newErr(codes.FailedPrecondition, "error creating latest secret version", err)
-					}
-
-					sourceFile, err := os.Open(s.secretFileName(&secretspb.Secret{Name: secretName}, version))
-					if err != nil {
-						return newErr(codes.FailedPrecondition, "error reading secret version", err)
-					}
-
-					_, err = io.Copy(destinationFile, sourceFile)
-					if err != nil {
-						return newErr(codes.FailedPrecondition, "error copying secret version", err)
-					}
-
-					err = destinationFile.Sync()
-					if err != nil {
-						return newErr(codes.FailedPrecondition, "error syncing latest secret version", err)
-					}
-
-					sourceFile.Close()
-					destinationFile.Close()
+			for _, record := range records {
+				if record.Seq < current.Seq && record.State == VersionState_Enabled {
+					version = record.Version
 				}
 			}
+
+			if version == "" {
+				return fmt.Errorf("secret %s has no enabled version before %s to roll back to", secretName, current.Version)
+			}
+		}
+
+		record, err := findVersion(secret, version)
+		if err != nil {
+			return err
+		}
+
+		if record == nil {
+			return fmt.Errorf("secret %s has no version %s", secretName, version)
 		}
+
+		if record.State != VersionState_Enabled {
+			return fmt.Errorf("secret version %s is %s", record.Version, record.State)
+		}
+
+		version = record.Version
+
+		return secret.Put(latestKey, []byte(record.Version))
+	})
+	if err != nil {
+		return "", err
 	}
 
-	return nil
+	return version, nil
+}
+
+// Secrets returns the names of the secrets in the store, used by the CLI
+func (s *DevSecretService) Secrets() ([]string, error) {
+	names := []string{}
+
+	err := s.view(func(tx *bbolt.Tx) error {
+		return tx.ForEach(func(name []byte, _ *bbolt.Bucket) error {
+			names = append(names, string(name))
+			return nil
+		})
+	})
+
+	return names, err
 }
 
 // Create new secret store
@@ -388,7 +417,23 @@ func NewSecretService() (*DevSecretService, error) {
 		}
 	}
 
-	return &DevSecretService{
+	if err := checkNotTracked(secDir); err != nil {
+		return nil, err
+	}
+
+	key, err := pulumi.GetOrGenerateLocalSecretsKey(afero.NewOsFs())
+	if err != nil {
+		return nil, err
+	}
+
+	secretService := &DevSecretService{
 		secDir: secDir,
-	}, nil
+		key:    key,
+	}
+
+	if err := secretService.migrate(); err != nil {
+		return nil, err
+	}
+
+	return secretService, nil
 }
@@ -0,0 +1,260 @@
+// Copyright Nitric Pty Ltd.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package secrets
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	secretspb "github.com/nitrictech/nitric/core/pkg/proto/secrets/v1"
+)
+
+func TestRollback(t *testing.T) {
+	ctx := context.Background()
+	s := &DevSecretService{secDir: t.TempDir(), key: make([]byte, 32)}
+	secret := &secretspb.Secret{Name: "api-key"}
+
+	versions := []string{}
+
+	for _, value := range []string{"v1", "v2"} {
+		resp, err := s.Put(ctx, &secretspb.SecretPutRequest{Secret: secret, Value: []byte(value)})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		versions = append(versions, resp.SecretVersion.Version)
+	}
+
+	version, err := s.Rollback(ctx, "api-key", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if version != versions[0] {
+		t.Errorf("Rollback() = %s, want %s", version, versions[0])
+	}
+
+	resp, err := s.Access(ctx, &secretspb.SecretAccessRequest{SecretVersion: &secretspb.SecretVersion{Secret: secret, Version: "latest"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(resp.Value) != "v1" || resp.SecretVersion.Version != versions[0] {
+		t.Errorf("latest version = %s (%s), want v1 (%s)", resp.Value, resp.SecretVersion.Version, versions[0])
+	}
+
+	if _, err := s.Rollback(ctx, "api-key", ""); err == nil {
+		t.Errorf("Rollback() expected an error when there is no earlier version")
+	}
+
+	names, err := s.Secrets()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(names) != 1 || names[0] != "api-key" {
+		t.Errorf("Secrets() = %v, want [api-key]", names)
+	}
+}
+
+func TestSetState(t *testing.T) {
+	ctx := context.Background()
+	s := &DevSecretService{secDir: t.TempDir(), key: make([]byte, 32)}
+	secret := &secretspb.Secret{Name: "api-key"}
+
+	versions := []string{}
+
+	for _, value := range []string{"v1", "v2"} {
+		resp, err := s.Put(ctx, &secretspb.SecretPutRequest{Secret: secret, Value: []byte(value)})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		versions = append(versions, resp.SecretVersion.Version)
+	}
+
+	access := func(version string) (*secretspb.SecretAccessResponse, error) {
+		return s.Access(ctx, &secretspb.SecretAccessRequest{SecretVersion: &secretspb.SecretVersion{Secret: secret, Version: version}})
+	}
+
+	if err := s.SetState(ctx, "api-key", versions[1], VersionState_Disabled); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := access(versions[1]); err == nil {
+		t.Errorf("Access() expected an error for a disabled version")
+	}
+
+	resp, err := access("latest")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(resp.Value) != "v1" {
+		t.Errorf("latest version = %s, want v1 after disabling the latest version", resp.Value)
+	}
+
+	if err := s.SetState(ctx, "api-key", versions[1], VersionState_Destroyed); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.SetState(ctx, "api-key", versions[1], VersionState_Enabled); err == nil {
+		t.Errorf("SetState() expected an error when enabling a destroyed version")
+	}
+
+	list, err := s.List(ctx, "api-key")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(list) != 2 || list[0].State != VersionState_Destroyed || list[0].Value != "" {
+		t.Errorf("List() = %+v, want the destroyed version first without a value", list)
+	}
+}
+
+func TestDeleteLastVersion(t *testing.T) {
+	ctx := context.Background()
+	s := &DevSecretService{secDir: t.TempDir(), key: make([]byte, 32)}
+
+	versions := map[string]string{}
+
+	for _, name := range []string{"api-key", "db-password"} {
+		resp, err := s.Put(ctx, &secretspb.SecretPutRequest{Secret: &secretspb.Secret{Name: name}, Value: []byte("value")})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		versions[name] = resp.SecretVersion.Version
+	}
+
+	if err := s.Delete(ctx, "api-key", versions["api-key"]); err != nil {
+		t.Fatal(err)
+	}
+
+	names, err := s.Secrets()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(names) != 1 || names[0] != "db-password" {
+		t.Errorf("Secrets() = %v, want [db-password] after deleting the last version of api-key", names)
+	}
+}
+
+func TestMigrate(t *testing.T) {
+	ctx := context.Background()
+	s := &DevSecretService{secDir: t.TempDir(), key: make([]byte, 32)}
+	secret := &secretspb.Secret{Name: "api-key"}
+
+	// plaintext files written by earlier versions
+	legacy := map[string]string{
+		"api-key_v1.txt":     "c2VjcmV0",
+		"api-key_latest.txt": "c2VjcmV0,v1",
+	}
+
+	for file, content := range legacy {
+		if err := os.WriteFile(filepath.Join(s.secDir, file), []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := s.migrate(); err != nil {
+		t.Fatal(err)
+	}
+
+	for file := range legacy {
+		if _, err := os.Stat(filepath.Join(s.secDir, file)); !os.IsNotExist(err) {
+			t.Errorf("expected %s to be removed after migration", file)
+		}
+	}
+
+	for _, version := range []string{"v1", "latest"} {
+		resp, err := s.Access(ctx, &secretspb.SecretAccessRequest{SecretVersion: &secretspb.SecretVersion{Secret: secret, Version: version}})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if string(resp.Value) != "secret" || resp.SecretVersion.Version != "v1" {
+			t.Errorf("Access(%s) = %s (%s), want secret (v1)", version, resp.Value, resp.SecretVersion.Version)
+		}
+	}
+}
+
+func TestMigrateSeparators(t *testing.T) {
+	ctx := context.Background()
+	s := &DevSecretService{secDir: t.TempDir(), key: make([]byte, 32)}
+	secret := &secretspb.Secret{Name: "api_key"}
+
+	// the name contains an underscore and the latest file's value contains a comma
+	legacy := map[string]string{
+		"api_key_11111111-1111-1111-1111-111111111111.txt": "YSxi",
+		"api_key_22222222-2222-2222-2222-222222222222.txt": "c2VjcmV0",
+		"api_key_latest.txt": "a,b,11111111-1111-1111-1111-111111111111",
+	}
+
+	for file, content := range legacy {
+		if err := os.WriteFile(filepath.Join(s.secDir, file), []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// the newest version isn't latest, so the latest pointer must come from the latest file
+	older := time.Now().Add(-time.Hour)
+	if err := os.Chtimes(filepath.Join(s.secDir, "api_key_11111111-1111-1111-1111-111111111111.txt"), older, older); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.migrate(); err != nil {
+		t.Fatal(err)
+	}
+
+	names, err := s.Secrets()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(names) != 1 || names[0] != "api_key" {
+		t.Errorf("Secrets() = %v, want [api_key]", names)
+	}
+
+	for _, tt := range []struct {
+		version     string
+		wantValue   string
+		wantVersion string
+	}{
+		{version: "latest", wantValue: "a,b", wantVersion: "11111111-1111-1111-1111-111111111111"},
+		{version: "22222222-2222-2222-2222-222222222222", wantValue: "secret", wantVersion: "22222222-2222-2222-2222-222222222222"},
+	} {
+		resp, err := s.Access(ctx, &secretspb.SecretAccessRequest{SecretVersion: &secretspb.SecretVersion{Secret: secret, Version: tt.version}})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if string(resp.Value) != tt.wantValue || resp.SecretVersion.Version != tt.wantVersion {
+			t.Errorf("Access(%s) = %s (%s), want %s (%s)", tt.version, resp.Value, resp.SecretVersion.Version, tt.wantValue, tt.wantVersion)
+		}
+	}
+
+	for file := range legacy {
+		if _, err := os.Stat(filepath.Join(s.secDir, file)); !os.IsNotExist(err) {
+			t.Errorf("expected %s to be removed after migration", file)
+		}
+	}
+}
@@ -0,0 +1,178 @@
+// Copyright Nitric Pty Ltd.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package secrets
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"path/filepath"
+	"time"
+
+	"go.etcd.io/bbolt"
+)
+
+// The secret store is a bolt database with a bucket for each secret, containing the latest version pointer
+// and a versions bucket of version records keyed by their sequence number
+const secretsDbFile = "secrets.db"
+
+var (
+	latestKey      = []byte("latest")
+	versionsBucket = []byte("versions")
+)
+
+type VersionState string
+
+const (
+	VersionState_Enabled   VersionState = "enabled"
+	VersionState_Disabled  VersionState = "disabled"
+	VersionState_Destroyed VersionState = "destroyed"
+)
+
+var VersionStates = []string{string(VersionState_Enabled), string(VersionState_Disabled), string(VersionState_Destroyed)}
+
+type versionRecord struct {
+	Version string `json:"version"`
+	// Seq orders the versions of a secret, it increases with each version put
+	Seq uint64 `json:"seq"`
+	// Value is the encrypted secret value, it is empty once the version is destroyed
+	Value     string       `json:"value,omitempty"`
+	CreatedAt time.Time    `json:"createdAt"`
+	State     VersionState `json:"state"`
+}
+
+// open opens the secret store, the database is locked while open so it is only held for a single transaction
+func (s *DevSecretService) open() (*bbolt.DB, error) {
+	return bbolt.Open(filepath.Join(s.secDir, secretsDbFile), 0o600, &bbolt.Options{Timeout: 1 * time.Second})
+}
+
+func (s *DevSecretService) update(fn func(tx *bbolt.Tx) error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	db, err := s.open()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	return db.Update(fn)
+}
+
+func (s *DevSecretService) view(fn func(tx *bbolt.Tx) error) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	db, err := s.open()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	return db.View(fn)
+}
+
+func createSecretBucket(tx *bbolt.Tx, secretName string) (*bbolt.Bucket, error) {
+	secret, err := tx.CreateBucketIfNotExists([]byte(secretName))
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := secret.CreateBucketIfNotExists(versionsBucket); err != nil {
+		return nil, err
+	}
+
+	return secret, nil
+}
+
+// seqKey returns the key of a version, big endian so versions are iterated in order
+func seqKey(seq uint64) []byte {
+	key := make([]byte, 8)
+	binary.BigEndian.PutUint64(key, seq)
+
+	return key
+}
+
+func putVersion(secret *bbolt.Bucket, record versionRecord) error {
+	recordJson, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	return secret.Bucket(versionsBucket).Put(seqKey(record.Seq), recordJson)
+}
+
+// versions returns the versions of a secret, oldest first
+func versions(secret *bbolt.Bucket) ([]versionRecord, error) {
+	records := []versionRecord{}
+
+	err := secret.Bucket(versionsBucket).ForEach(func(_ []byte, value []byte) error {
+		record := versionRecord{}
+		if err := json.Unmarshal(value, &record); err != nil {
+			return err
+		}
+
+		records = append(records, record)
+
+		return nil
+	})
+
+	return records, err
+}
+
+// findVersion returns a version of a secret, or the version the latest pointer refers to, nil is returned if the secret or version doesn't exist
+func findVersion(secret *bbolt.Bucket, version string) (*versionRecord, error) {
+	if secret == nil {
+		return nil, nil
+	}
+
+	if version == "latest" {
+		version = string(secret.Get(latestKey))
+	}
+
+	records, err := versions(secret)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, record := range records {
+		if record.Version == version {
+			return &record, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// repointLatest moves the latest pointer to the newest enabled version if it refers to a version that was removed or is no longer enabled
+func repointLatest(secret *bbolt.Bucket, version string) error {
+	if string(secret.Get(latestKey)) != version {
+		return nil
+	}
+
+	records, err := versions(secret)
+	if err != nil {
+		return err
+	}
+
+	for i := len(records) - 1; i >= 0; i-- {
+		if records[i].State == VersionState_Enabled && records[i].Version != version {
+			return secret.Put(latestKey, []byte(records[i].Version))
+		}
+	}
+
+	return secret.Delete(latestKey)
+}
@@ -0,0 +1,377 @@
+// Copyright Nitric Pty Ltd.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sql
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	orderedmap "github.com/wk8/go-ordered-map/v2"
+)
+
+type TransactionMode string
+
+const (
+	// TransactionMode_Commit commits the statements of a query
+	TransactionMode_Commit TransactionMode = "commit"
+	// TransactionMode_Rollback rolls back the statements of a query, so writes can be tried without changing the database
+	TransactionMode_Rollback TransactionMode = "rollback"
+	// TransactionMode_ReadOnly runs the statements of a query in a read only transaction, so re-running a query for more of its rows can't write
+	TransactionMode_ReadOnly TransactionMode = "read_only"
+)
+
+// TransactionModes are the valid transaction modes of a query, an empty mode commits
+var TransactionModes = []TransactionMode{TransactionMode_Commit, TransactionMode_Rollback, TransactionMode_ReadOnly}
+
+// Valid returns true if the mode is empty or one of TransactionModes
+func (m TransactionMode) Valid() bool {
+	return m == "" || slices.Contains(TransactionModes, m)
+}
+
+// txOptions returns the options of the transaction a query runs in
+func (m TransactionMode) txOptions() pgx.TxOptions {
+	if m == TransactionMode_ReadOnly {
+		return pgx.TxOptions{AccessMode: pgx.ReadOnly}
+	}
+
+	return pgx.TxOptions{}
+}
+
+type QueryOptions struct {
+	// Params are bound to the placeholders ($1, $2, ...) of a single statement query
+	Params []any
+	// Limit is the maximum number of rows returned, all rows are returned when it is zero
+	Limit int
+	// Offset is the number of rows skipped before the returned rows
+	Offset int
+	// Transaction decides if the query is committed, rolled back or read only, defaults to commit
+	Transaction TransactionMode
+}
+
+type QueryResult struct {
+	Columns      []string                              `json:"columns"`
+	Rows         []*orderedmap.OrderedMap[string, any] `json:"rows"`
+	HasMore      bool                                  `json:"hasMore"`
+	RowsAffected int64                                 `json:"rowsAffected"`
+	RolledBack   bool                                  `json:"rolledBack"`
+}
+
+// PlanNode is a node of a query plan, with the costs estimated by the planner and the actual values measured by EXPLAIN ANALYZE
+type PlanNode struct {
+	NodeType    string      `json:"nodeType"`
+	Relation    string      `json:"relation,omitempty"`
+	Index       string      `json:"index,omitempty"`
+	StartupCost float64     `json:"startupCost"`
+	TotalCost   float64     `json:"totalCost"`
+	PlanRows    float64     `json:"planRows"`
+	ActualTime  float64     `json:"actualTime"`
+	ActualRows  float64     `json:"actualRows"`
+	ActualLoops float64     `json:"actualLoops"`
+	Filter      string      `json:"filter,omitempty"`
+	IndexCond   string      `json:"indexCond,omitempty"`
+	HashCond    string      `json:"hashCond,omitempty"`
+	JoinFilter  string      `json:"joinFilter,omitempty"`
+	Children    []*PlanNode `json:"children"`
+}
+
+type ExplainResult struct {
+	Plan          *PlanNode `json:"plan"`
+	PlanningTime  float64   `json:"planningTime"`
+	ExecutionTime float64   `json:"executionTime"`
+	// Text is the plan rendered as an indented tree
+	Text string `json:"text"`
+}
+
+// pgPlan is a node of the JSON output format of postgres EXPLAIN
+type pgPlan struct {
+	NodeType        string    `json:"Node Type"`
+	RelationName    string    `json:"Relation Name"`
+	IndexName       string    `json:"Index Name"`
+	StartupCost     float64   `json:"Startup Cost"`
+	TotalCost       float64   `json:"Total Cost"`
+	PlanRows        float64   `json:"Plan Rows"`
+	ActualTotalTime float64   `json:"Actual Total Time"`
+	ActualRows      float64   `json:"Actual Rows"`
+	ActualLoops     float64   `json:"Actual Loops"`
+	Filter          string    `json:"Filter"`
+	IndexCond       string    `json:"Index Cond"`
+	HashCond        string    `json:"Hash Cond"`
+	JoinFilter      string    `json:"Join Filter"`
+	Plans           []*pgPlan `json:"Plans"`
+}
+
+type pgExplain struct {
+	Plan          *pgPlan `json:"Plan"`
+	PlanningTime  float64 `json:"Planning Time"`
+	ExecutionTime float64 `json:"Execution Time"`
+}
+
+func (p *pgPlan) toPlanNode() *PlanNode {
+	node := &PlanNode{
+		NodeType:    p.NodeType,
+		Relation:    p.RelationName,
+		Index:       p.IndexName,
+		StartupCost: p.StartupCost,
+		TotalCost:   p.TotalCost,
+		PlanRows:    p.PlanRows,
+		ActualTime:  p.ActualTotalTime,
+		ActualRows:  p.ActualRows,
+		ActualLoops: p.ActualLoops,
+		Filter:      p.Filter,
+		IndexCond:   p.IndexCond,
+		HashCond:    p.HashCond,
+		JoinFilter:  p.JoinFilter,
+		Children:    []*PlanNode{},
+	}
+
+	for _, child := range p.Plans {
+		node.Children = append(node.Children, child.toPlanNode())
+	}
+
+	return node
+}
+
+// paginatableRegex matches statements that can be used as a subquery
+var paginatableRegex = regexp.MustCompile(`(?i)^(select|values|table)\b`)
+
+// lockingClauseRegex matches the locking clause of a select, which can't be used in a subquery
+var lockingClauseRegex = regexp.MustCompile(`(?i)\bfor\s+(update|share|no\s+key\s+update|key\s+share)\b`)
+
+// paginate wraps a statement that returns rows in a subquery limiting its rows, so the database doesn't return rows that aren't read
+func paginate(statement string, limit int, offset int) (string, bool) {
+	statement = strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(statement), ";"))
+
+	if !paginatableRegex.MatchString(statement) {
+		return "", false
+	}
+
+	if lockingClauseRegex.MatchString(statement) {
+		return "", false
+	}
+
+	// the closing paren is on its own line so a trailing -- comment in the statement can't comment it out
+	return fmt.Sprintf("SELECT * FROM (%s\n) AS nitric_query LIMIT %d OFFSET %d", statement, limit, offset), true
+}
+
+func columnNames(rows pgx.Rows) []string {
+	columns := []string{}
+
+	for _, field := range rows.FieldDescriptions() {
+		columns = append(columns, field.Name)
+	}
+
+	return columns
+}
+
+// pool returns the connection pool of a connection string, creating it on first use
+func (l *LocalSqlServer) pool(ctx context.Context, connectionString string) (*pgxpool.Pool, error) {
+	l.poolsLock.Lock()
+	defer l.poolsLock.Unlock()
+
+	if pool, ok := l.pools[connectionString]; ok {
+		return pool, nil
+	}
+
+	pool, err := pgxpool.New(ctx, connectionString)
+	if err != nil {
+		return nil, err
+	}
+
+	l.pools[connectionString] = pool
+
+	return pool, nil
+}
+
+func (l *LocalSqlServer) closePools() {
+	l.poolsLock.Lock()
+	defer l.poolsLock.Unlock()
+
+	for connectionString, pool := range l.pools {
+		pool.Close()
+		delete(l.pools, connectionString)
+	}
+}
+
+// Explain runs EXPLAIN ANALYZE for a single statement query and returns its plan.
+// The statement is executed to measure it, so it always runs in a transaction that is rolled back.
+func (l *LocalSqlServer) Explain(ctx context.Context, connectionString string, query string, params []any) (*ExplainResult, error) {
+	commands := []string{}
+
+	for _, command := range SQLSplit(query) {
+		if command = strings.TrimSpace(command); command != "" {
+			commands = append(commands, command)
+		}
+	}
+
+	if len(commands) != 1 {
+		return nil, fmt.Errorf("explain requires a single statement, the query has %d", len(commands))
+	}
+
+	pool, err := l.pool(ctx, connectionString)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	var output []byte
+
+	statement := strings.TrimSuffix(commands[0], ";")
+
+	if err := tx.QueryRow(ctx, "EXPLAIN (ANALYZE, FORMAT JSON) "+statement, params...).Scan(&output); err != nil {
+		return nil, err
+	}
+
+	return parseExplain(output)
+}
+
+func parseExplain(output []byte) (*ExplainResult, error) {
+	explains := []pgExplain{}
+
+	if err := json.Unmarshal(output, &explains); err != nil {
+		return nil, fmt.Errorf("unable to parse query plan: %w", err)
+	}
+
+	if len(explains) == 0 || explains[0].Plan == nil {
+		return nil, fmt.Errorf("query plan is empty")
+	}
+
+	result := &ExplainResult{
+		Plan:          explains[0].Plan.toPlanNode(),
+		PlanningTime:  explains[0].PlanningTime,
+		ExecutionTime: explains[0].ExecutionTime,
+	}
+
+	result.Text = FormatPlan(result)
+
+	return result, nil
+}
+
+// FormatPlan renders a query plan as an indented tree, similar to the text format of EXPLAIN ANALYZE
+func FormatPlan(result *ExplainResult) string {
+	var sb strings.Builder
+
+	formatPlanNode(&sb, result.Plan, 0)
+
+	fmt.Fprintf(&sb, "Planning Time: %.3f ms\n", result.PlanningTime)
+	fmt.Fprintf(&sb, "Execution Time: %.3f ms\n", result.ExecutionTime)
+
+	return sb.String()
+}
+
+func formatPlanNode(sb *strings.Builder, node *PlanNode, depth int) {
+	indent := ""
+	prefix := ""
+
+	if depth > 0 {
+		indent = strings.Repeat("      ", depth-1) + "  "
+		prefix = "->  "
+	}
+
+	name := node.NodeType
+	if node.Index != "" {
+		name += " using " + node.Index
+	}
+
+	if node.Relation != "" {
+		name += " on " + node.Relation
+	}
+
+	fmt.Fprintf(sb, "%s%s%s  (cost=%.2f..%.2f rows=%.0f) (actual time=%.3f rows=%.0f loops=%.0f)\n",
+		indent, prefix, name, node.StartupCost, node.TotalCost, node.PlanRows, node.ActualTime, node.ActualRows, node.ActualLoops)
+
+	detailIndent := "  "
+	if depth > 0 {
+		detailIndent = indent + "      "
+	}
+
+	for _, detail := range []struct{ label, value string }{
+		{"Hash Cond", node.HashCond},
+		{"Index Cond", node.IndexCond},
+		{"Join Filter", node.JoinFilter},
+		{"Filter", node.Filter},
+	} {
+		if detail.value != "" {
+			fmt.Fprintf(sb, "%s%s: %s\n", detailIndent, detail.label, detail.value)
+		}
+	}
+
+	for _, child := range node.Children {
+		formatPlanNode(sb, child, depth+1)
+	}
+}
+
+// WriteCSV writes the rows of a query result as CSV, with a header row of the column names
+func WriteCSV(w io.Writer, result *QueryResult) error {
+	writer := csv.NewWriter(w)
+
+	if err := writer.Write(result.Columns); err != nil {
+		return err
+	}
+
+	for _, row := range result.Rows {
+		record := make([]string, len(result.Columns))
+
+		for i, column := range result.Columns {
+			value, _ := row.Get(column)
+			record[i] = csvValue(value)
+		}
+
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+
+	return writer.Error()
+}
+
+func csvValue(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case []byte:
+		return string(v)
+	case map[string]any, []any:
+		b, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Sprint(v)
+		}
+
+		return string(b)
+	default:
+		return fmt.Sprint(v)
+	}
+}
@@ -0,0 +1,178 @@
+// Copyright Nitric Pty Ltd.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sql
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/jackc/pgx/v5"
+	orderedmap "github.com/wk8/go-ordered-map/v2"
+)
+
+func TestPaginate(t *testing.T) {
+	for _, tt := range []struct {
+		name      string
+		statement string
+		expected  string
+		ok        bool
+	}{
+		{
+			name:      "select",
+			statement: "SELECT * FROM users;",
+			expected:  "SELECT * FROM (SELECT * FROM users\n) AS nitric_query LIMIT 11 OFFSET 20",
+			ok:        true,
+		},
+		{
+			name:      "values",
+			statement: "values (1), (2)",
+			expected:  "SELECT * FROM (values (1), (2)\n) AS nitric_query LIMIT 11 OFFSET 20",
+			ok:        true,
+		},
+		{
+			name:      "trailing comment",
+			statement: "select * from t -- recent",
+			expected:  "SELECT * FROM (select * from t -- recent\n) AS nitric_query LIMIT 11 OFFSET 20",
+			ok:        true,
+		},
+		{
+			name:      "locking select",
+			statement: "SELECT * FROM users FOR UPDATE;",
+			ok:        false,
+		},
+		{
+			name:      "insert returning",
+			statement: "INSERT INTO users (name) VALUES ('a') RETURNING *;",
+			ok:        false,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := paginate(tt.statement, 11, 20)
+
+			if ok != tt.ok {
+				t.Fatalf("paginate() ok = %v, want %v", ok, tt.ok)
+			}
+
+			if diff := cmp.Diff(tt.expected, got); diff != "" {
+				t.Errorf("paginate() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestTransactionModeTxOptions(t *testing.T) {
+	for _, tt := range []struct {
+		mode     TransactionMode
+		expected pgx.TxAccessMode
+	}{
+		{mode: "", expected: ""},
+		{mode: TransactionMode_Commit, expected: ""},
+		{mode: TransactionMode_Rollback, expected: ""},
+		{mode: TransactionMode_ReadOnly, expected: pgx.ReadOnly},
+	} {
+		t.Run(string(tt.mode), func(t *testing.T) {
+			if got := tt.mode.txOptions().AccessMode; got != tt.expected {
+				t.Errorf("txOptions() access mode = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestTransactionModeValid(t *testing.T) {
+	for _, tt := range []struct {
+		mode     TransactionMode
+		expected bool
+	}{
+		{mode: "", expected: true},
+		{mode: TransactionMode_Commit, expected: true},
+		{mode: TransactionMode_Rollback, expected: true},
+		{mode: TransactionMode_ReadOnly, expected: true},
+		{mode: "readonly", expected: false},
+		{mode: "roll_back", expected: false},
+	} {
+		t.Run(string(tt.mode), func(t *testing.T) {
+			if got := tt.mode.Valid(); got != tt.expected {
+				t.Errorf("Valid() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestParseExplain(t *testing.T) {
+	output := []byte(`[{
+		"Plan": {
+			"Node Type": "Hash Join", "Startup Cost": 1.5, "Total Cost": 30.25, "Plan Rows": 10,
+			"Actual Total Time": 0.5, "Actual Rows": 4, "Actual Loops": 1, "Hash Cond": "(o.user_id = u.id)",
+			"Plans": [
+				{"Node Type": "Seq Scan", "Relation Name": "orders", "Startup Cost": 0, "Total Cost": 20.5, "Plan Rows": 100,
+				 "Actual Total Time": 0.1, "Actual Rows": 100, "Actual Loops": 1, "Filter": "(total > 10)"},
+				{"Node Type": "Index Scan", "Relation Name": "users", "Index Name": "users_pkey", "Startup Cost": 0.15, "Total Cost": 8.17,
+				 "Plan Rows": 1, "Actual Total Time": 0.01, "Actual Rows": 1, "Actual Loops": 4, "Index Cond": "(id = 1)"}
+			]
+		},
+		"Planning Time": 0.123,
+		"Execution Time": 0.789
+	}]`)
+
+	result, err := parseExplain(output)
+	if err != nil {
+		t.Fatalf("parseExplain() error = %v", err)
+	}
+
+	expected := `Hash Join  (cost=1.50..30.25 rows=10) (actual time=0.500 rows=4 loops=1)
+  Hash Cond: (o.user_id = u.id)
+  ->  Seq Scan on orders  (cost=0.00..20.50 rows=100) (actual time=0.100 rows=100 loops=1)
+        Filter: (total > 10)
+  ->  Index Scan using users_pkey on users  (cost=0.15..8.17 rows=1) (actual time=0.010 rows=1 loops=4)
+        Index Cond: (id = 1)
+Planning Time: 0.123 ms
+Execution Time: 0.789 ms
+`
+
+	if diff := cmp.Diff(expected, result.Text); diff != "" {
+		t.Errorf("parseExplain() text mismatch (-want +got):\n%s", diff)
+	}
+
+	if len(result.Plan.Children) != 2 || result.Plan.Children[1].Index != "users_pkey" {
+		t.Errorf("parseExplain() plan children = %+v", result.Plan.Children)
+	}
+}
+
+func TestWriteCSV(t *testing.T) {
+	row := orderedmap.New[string, any]()
+	row.Set("id", int64(1))
+	row.Set("name", "Smith, Jane")
+	row.Set("tags", []any{"a", "b"})
+	row.Set("deleted_at", nil)
+
+	var buf bytes.Buffer
+
+	err := WriteCSV(&buf, &QueryResult{
+		Columns: []string{"id", "name", "tags", "deleted_at"},
+		Rows:    []*orderedmap.OrderedMap[string, any]{row},
+	})
+	if err != nil {
+		t.Fatalf("WriteCSV() error = %v", err)
+	}
+
+	expected := "id,name,tags,deleted_at\n1,\"Smith, Jane\",\"[\"\"a\"\",\"\"b\"\"]\",\n"
+
+	if diff := cmp.Diff(expected, buf.String()); diff != "" {
+		t.Errorf("WriteCSV() mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,527 @@
+// Copyright Nitric Pty Ltd.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sql
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/spf13/afero"
+
+	resourcespb "github.com/nitrictech/nitric/core/pkg/proto/resources/v1"
+)
+
+type Column struct {
+	Name     string  `json:"name"`
+	Position int     `json:"position"`
+	Type     string  `json:"type"`
+	Nullable bool    `json:"nullable"`
+	Default  *string `json:"default"`
+}
+
+type Index struct {
+	Name       string   `json:"name"`
+	Columns    []string `json:"columns"`
+	Unique     bool     `json:"unique"`
+	Primary    bool     `json:"primary"`
+	Definition string   `json:"definition"`
+}
+
+type ForeignKey struct {
+	Name              string   `json:"name"`
+	Columns           []string `json:"columns"`
+	ReferencedSchema  string   `json:"referencedSchema"`
+	ReferencedTable   string   `json:"referencedTable"`
+	ReferencedColumns []string `json:"referencedColumns"`
+	OnUpdate          string   `json:"onUpdate"`
+	OnDelete          string   `json:"onDelete"`
+}
+
+type TableKind string
+
+const (
+	TableKind_Table            TableKind = "table"
+	TableKind_View             TableKind = "view"
+	TableKind_MaterializedView TableKind = "materialized view"
+	TableKind_ForeignTable     TableKind = "foreign table"
+)
+
+type Table struct {
+	Schema string    `json:"schema"`
+	Name   string    `json:"name"`
+	Kind   TableKind `json:"kind"`
+	// RowEstimate is the planner's estimate of the number of rows, it is zero until the table has been analyzed
+	RowEstimate int64         `json:"rowEstimate"`
+	Columns     []*Column     `json:"columns"`
+	Indexes     []*Index      `json:"indexes"`
+	ForeignKeys []*ForeignKey `json:"foreignKeys"`
+}
+
+// QualifiedName returns the schema qualified name of the table
+func (t *Table) QualifiedName() string {
+	return t.Schema + "." + t.Name
+}
+
+type DatabaseSchema struct {
+	Database string   `json:"database"`
+	Schemas  []string `json:"schemas"`
+	Tables   []*Table `json:"tables"`
+}
+
+type SchemaDifferenceKind string
+
+const (
+	// SchemaDifferenceKind_Missing is an object created by the migrations that isn't in the database
+	SchemaDifferenceKind_Missing SchemaDifferenceKind = "missing"
+	// SchemaDifferenceKind_Unexpected is an object in the database that isn't created by the migrations
+	SchemaDifferenceKind_Unexpected SchemaDifferenceKind = "unexpected"
+	// SchemaDifferenceKind_Changed is an object in both with a different definition
+	SchemaDifferenceKind_Changed SchemaDifferenceKind = "changed"
+)
+
+type SchemaDifference struct {
+	Kind SchemaDifferenceKind `json:"kind"`
+	// Object is the type of the object, e.g. table, column or index
+	Object string `json:"object"`
+	Name   string `json:"name"`
+	// Expected is the definition from the migrations, empty when the object is unexpected
+	Expected string `json:"expected,omitempty"`
+	// Actual is the definition in the database, empty when the object is missing
+	Actual string `json:"actual,omitempty"`
+}
+
+type SchemaDrift struct {
+	Database    string              `json:"database"`
+	Drifted     bool                `json:"drifted"`
+	Differences []*SchemaDifference `json:"differences"`
+}
+
+// catalogFilter excludes the system schemas from catalog queries, n is the pg_namespace of the object
+const catalogFilter = `n.nspname NOT IN ('pg_catalog', 'information_schema') AND n.nspname NOT LIKE 'pg\_toast%' AND n.nspname NOT LIKE 'pg\_temp%'`
+
+const schemasQuery = `SELECT n.nspname FROM pg_namespace n WHERE ` + catalogFilter + ` ORDER BY n.nspname`
+
+const tablesQuery = `SELECT n.nspname, c.relname, c.relkind::text, GREATEST(c.reltuples, 0)::bigint
+FROM pg_class c
+JOIN pg_namespace n ON n.oid = c.relnamespace
+WHERE c.relkind IN ('r', 'p', 'v', 'm', 'f') AND ` + catalogFilter + `
+ORDER BY n.nspname, c.relname`
+
+const columnsQuery = `SELECT n.nspname, c.relname, a.attname, a.attnum, format_type(a.atttypid, a.atttypmod), NOT a.attnotnull, pg_get_expr(d.adbin, d.adrelid)
+FROM pg_attribute a
+JOIN pg_class c ON c.oid = a.attrelid
+JOIN pg_namespace n ON n.oid = c.relnamespace
+LEFT JOIN pg_attrdef d ON d.adrelid = a.attrelid AND d.adnum = a.attnum
+WHERE a.attnum > 0 AND NOT a.attisdropped AND c.relkind IN ('r', 'p', 'v', 'm', 'f') AND ` + catalogFilter + `
+ORDER BY n.nspname, c.relname, a.attnum`
+
+const indexesQuery = `SELECT n.nspname, t.relname, i.relname, ix.indisunique, ix.indisprimary, pg_get_indexdef(ix.indexrelid),
+	ARRAY(
+		SELECT COALESCE(a.attname::text, pg_get_indexdef(ix.indexrelid, k.ord::int, true))
+		FROM unnest(ix.indkey::int2[]) WITH ORDINALITY AS k(attnum, ord)
+		LEFT JOIN pg_attribute a ON a.attrelid = t.oid AND a.attnum = k.attnum
+		ORDER BY k.ord
+	)
+FROM pg_index ix
+JOIN pg_class i ON i.oid = ix.indexrelid
+JOIN pg_class t ON t.oid = ix.indrelid
+JOIN pg_namespace n ON n.oid = t.relnamespace
+WHERE ` + catalogFilter + `
+ORDER BY n.nspname, t.relname, i.relname`
+
+const foreignKeysQuery = `SELECT n.nspname, c.relname, con.conname,
+	ARRAY(
+		SELECT a.attname::text FROM unnest(con.conkey) WITH ORDINALITY AS k(attnum, ord)
+		JOIN pg_attribute a ON a.attrelid = con.conrelid AND a.attnum = k.attnum
+		ORDER BY k.ord
+	),
+	fn.nspname, fc.relname,
+	ARRAY(
+		SELECT a.attname::text FROM unnest(con.confkey) WITH ORDINALITY AS k(attnum, ord)
+		JOIN pg_attribute a ON a.attrelid = con.confrelid AND a.attnum = k.attnum
+		ORDER BY k.ord
+	),
+	con.confupdtype::text, con.confdeltype::text
+FROM pg_constraint con
+JOIN pg_class c ON c.oid = con.conrelid
+JOIN pg_namespace n ON n.oid = c.relnamespace
+JOIN pg_class fc ON fc.oid = con.confrelid
+JOIN pg_namespace fn ON fn.oid = fc.relnamespace
+WHERE con.contype = 'f' AND ` + catalogFilter + `
+ORDER BY n.nspname, c.relname, con.conname`
+
+var tableKinds = map[string]TableKind{
+	"r": TableKind_Table,
+	"p": TableKind_Table,
+	"v": TableKind_View,
+	"m": TableKind_MaterializedView,
+	"f": TableKind_ForeignTable,
+}
+
+var foreignKeyActions = map[string]string{
+	"a": "NO ACTION",
+	"r": "RESTRICT",
+	"c": "CASCADE",
+	"n": "SET NULL",
+	"d": "SET DEFAULT",
+}
+
+// querier is implemented by both connections and connection pools
+type querier interface {
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+}
+
+// Schema returns the schemas, tables, columns, indexes and foreign keys of a local database
+func (l *LocalSqlServer) Schema(ctx context.Context, databaseName string) (*DatabaseSchema, error) {
+	pool, err := l.pool(ctx, l.databaseUrl("localhost", databaseName))
+	if err != nil {
+		return nil, err
+	}
+
+	schema, err := readSchema(ctx, pool)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read schema of database %s: %w", databaseName, err)
+	}
+
+	schema.Database = databaseName
+
+	return schema, nil
+}
+
+func readSchema(ctx context.Context, q querier) (*DatabaseSchema, error) {
+	schema := &DatabaseSchema{
+		Schemas: []string{},
+		Tables:  []*Table{},
+	}
+
+	schemaNames, err := queryRows(ctx, q, schemasQuery, func(rows pgx.Rows) (string, error) {
+		var name string
+
+		return name, rows.Scan(&name)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	schema.Schemas = schemaNames
+
+	tables := map[string]*Table{}
+
+	schema.Tables, err = queryRows(ctx, q, tablesQuery, func(rows pgx.Rows) (*Table, error) {
+		table := &Table{
+			Columns:     []*Column{},
+			Indexes:     []*Index{},
+			ForeignKeys: []*ForeignKey{},
+		}
+
+		var kind string
+
+		if err := rows.Scan(&table.Schema, &table.Name, &kind, &table.RowEstimate); err != nil {
+			return nil, err
+		}
+
+		table.Kind = tableKinds[kind]
+		tables[table.QualifiedName()] = table
+
+		return table, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = queryRows(ctx, q, columnsQuery, func(rows pgx.Rows) (*Column, error) {
+		var schemaName, tableName string
+
+		column := &Column{}
+
+		if err := rows.Scan(&schemaName, &tableName, &column.Name, &column.Position, &column.Type, &column.Nullable, &column.Default); err != nil {
+			return nil, err
+		}
+
+		if table, ok := tables[schemaName+"."+tableName]; ok {
+			table.Columns = append(table.Columns, column)
+		}
+
+		return column, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = queryRows(ctx, q, indexesQuery, func(rows pgx.Rows) (*Index, error) {
+		var schemaName, tableName string
+
+		index := &Index{}
+
+		if err := rows.Scan(&schemaName, &tableName, &index.Name, &index.Unique, &index.Primary, &index.Definition, &index.Columns); err != nil {
+			return nil, err
+		}
+
+		if table, ok := tables[schemaName+"."+tableName]; ok {
+			table.Indexes = append(table.Indexes, index)
+		}
+
+		return index, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = queryRows(ctx, q, foreignKeysQuery, func(rows pgx.Rows) (*ForeignKey, error) {
+		var schemaName, tableName, onUpdate, onDelete string
+
+		fk := &ForeignKey{}
+
+		if err := rows.Scan(&schemaName, &tableName, &fk.Name, &fk.Columns, &fk.ReferencedSchema, &fk.ReferencedTable, &fk.ReferencedColumns, &onUpdate, &onDelete); err != nil {
+			return nil, err
+		}
+
+		fk.OnUpdate = foreignKeyActions[onUpdate]
+		fk.OnDelete = foreignKeyActions[onDelete]
+
+		if table, ok := tables[schemaName+"."+tableName]; ok {
+			table.ForeignKeys = append(table.ForeignKeys, fk)
+		}
+
+		return fk, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return schema, nil
+}
+
+func queryRows[T any](ctx context.Context, q querier, query string, scan func(pgx.Rows) (T, error)) ([]T, error) {
+	rows, err := q.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	results := []T{}
+
+	for rows.Next() {
+		result, err := scan(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		results = append(results, result)
+	}
+
+	return results, rows.Err()
+}
+
+// SchemaDrift compares the schema of a local database with a fresh database built from its migrations.
+// The fresh database is initialized like the local database, migrated, then dropped once its schema is read.
+func (l *LocalSqlServer) SchemaDrift(ctx context.Context, fs afero.Fs, databaseName string, resource *resourcespb.SqlDatabaseResource, useBuilder bool) (*SchemaDrift, error) {
+	if resource.GetMigrations() == nil {
+		return nil, fmt.Errorf("database %s has no migrations to compare with", databaseName)
+	}
+
+	if l.migrationRunner == nil {
+		return nil, fmt.Errorf("migrations can't be run for database %s", databaseName)
+	}
+
+	freshDatabaseName := fmt.Sprintf("nitric_drift_%s", strings.ReplaceAll(uuid.NewString(), "-", "")[:12])
+
+	if _, err := l.createDatabase(freshDatabaseName); err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		_ = l.DropDatabase(context.Background(), freshDatabaseName)
+	}()
+
+	conn, err := pgx.Connect(ctx, l.databaseUrl("localhost", freshDatabaseName))
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close(ctx)
+
+	if err := l.initialize(ctx, conn, databaseName, true); err != nil {
+		return nil, err
+	}
+
+	servers := map[string]*DatabaseServer{
+		databaseName: {
+			DatabaseName:     databaseName,
+			ConnectionString: l.databaseUrl(l.connectionStringHost, freshDatabaseName),
+		},
+	}
+
+	err = l.migrationRunner(fs, servers, map[string]*resourcespb.SqlDatabaseResource{databaseName: resource}, useBuilder, func(string, string) {})
+	if err != nil {
+		return nil, fmt.Errorf("unable to migrate a fresh database for %s: %w", databaseName, err)
+	}
+
+	expected, err := readSchema(ctx, conn)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read schema of the migrated database: %w", err)
+	}
+
+	actual, err := l.Schema(ctx, databaseName)
+	if err != nil {
+		return nil, err
+	}
+
+	differences := DiffSchemas(expected, actual)
+
+	return &SchemaDrift{
+		Database:    databaseName,
+		Drifted:     len(differences) > 0,
+		Differences: differences,
+	}, nil
+}
+
+// DiffSchemas returns the differences between the schema expected from migrations and the actual schema of a database.
+// Row estimates aren't compared as they depend on the data.
+func DiffSchemas(expected *DatabaseSchema, actual *DatabaseSchema) []*SchemaDifference {
+	differences := []*SchemaDifference{}
+
+	for _, name := range expected.Schemas {
+		if !slices.Contains(actual.Schemas, name) {
+			differences = append(differences, &SchemaDifference{Kind: SchemaDifferenceKind_Missing, Object: "schema", Name: name})
+		}
+	}
+
+	for _, name := range actual.Schemas {
+		if !slices.Contains(expected.Schemas, name) {
+			differences = append(differences, &SchemaDifference{Kind: SchemaDifferenceKind_Unexpected, Object: "schema", Name: name})
+		}
+	}
+
+	actualTables := map[string]*Table{}
+	for _, table := range actual.Tables {
+		actualTables[table.QualifiedName()] = table
+	}
+
+	expectedTables := map[string]*Table{}
+	for _, table := range expected.Tables {
+		expectedTables[table.QualifiedName()] = table
+	}
+
+	for _, table := range expected.Tables {
+		actualTable, ok := actualTables[table.QualifiedName()]
+		if !ok {
+			differences = append(differences, &SchemaDifference{Kind: SchemaDifferenceKind_Missing, Object: string(table.Kind), Name: table.QualifiedName()})
+			continue
+		}
+
+		if table.Kind != actualTable.Kind {
+			differences = append(differences, &SchemaDifference{
+				Kind:     SchemaDifferenceKind_Changed,
+				Object:   "table",
+				Name:     table.QualifiedName(),
+				Expected: string(table.Kind),
+				Actual:   string(actualTable.Kind),
+			})
+		}
+
+		differences = append(differences, diffTable(table, actualTable)...)
+	}
+
+	for _, table := range actual.Tables {
+		if _, ok := expectedTables[table.QualifiedName()]; !ok {
+			differences = append(differences, &SchemaDifference{Kind: SchemaDifferenceKind_Unexpected, Object: string(table.Kind), Name: table.QualifiedName()})
+		}
+	}
+
+	return differences
+}
+
+func diffTable(expected *Table, actual *Table) []*SchemaDifference {
+	differences := []*SchemaDifference{}
+
+	differences = append(differences, diffObjects(expected.QualifiedName(), "column", expected.Columns, actual.Columns,
+		func(c *Column) string { return c.Name }, columnDefinition)...)
+
+	differences = append(differences, diffObjects(expected.QualifiedName(), "index", expected.Indexes, actual.Indexes,
+		func(i *Index) string { return i.Name }, func(i *Index) string { return i.Definition })...)
+
+	differences = append(differences, diffObjects(expected.QualifiedName(), "foreign key", expected.ForeignKeys, actual.ForeignKeys,
+		func(fk *ForeignKey) string { return fk.Name }, foreignKeyDefinition)...)
+
+	return differences
+}
+
+// diffObjects compares objects of a table by name, objects with the same name are compared by their definition
+func diffObjects[T any](tableName string, object string, expected []T, actual []T, name func(T) string, definition func(T) string) []*SchemaDifference {
+	differences := []*SchemaDifference{}
+
+	actualByName := map[string]T{}
+	for _, o := range actual {
+		actualByName[name(o)] = o
+	}
+
+	expectedByName := map[string]T{}
+	for _, o := range expected {
+		expectedByName[name(o)] = o
+	}
+
+	for _, o := range expected {
+		qualifiedName := tableName + "." + name(o)
+
+		actualObject, ok := actualByName[name(o)]
+		if !ok {
+			differences = append(differences, &SchemaDifference{Kind: SchemaDifferenceKind_Missing, Object: object, Name: qualifiedName, Expected: definition(o)})
+			continue
+		}
+
+		if definition(o) != definition(actualObject) {
+			differences = append(differences, &SchemaDifference{
+				Kind:     SchemaDifferenceKind_Changed,
+				Object:   object,
+				Name:     qualifiedName,
+				Expected: definition(o),
+				Actual:   definition(actualObject),
+			})
+		}
+	}
+
+	for _, o := range actual {
+		if _, ok := expectedByName[name(o)]; !ok {
+			differences = append(differences, &SchemaDifference{Kind: SchemaDifferenceKind_Unexpected, Object: object, Name: tableName + "." + name(o), Actual: definition(o)})
+		}
+	}
+
+	return differences
+}
+
+func columnDefinition(c *Column) string {
+	definition := c.Type
+
+	if !c.Nullable {
+		definition += " NOT NULL"
+	}
+
+	if c.Default != nil {
+		definition += " DEFAULT " + *c.Default
+	}
+
+	return definition
+}
+
+func foreignKeyDefinition(fk *ForeignKey) string {
+	return fmt.Sprintf("(%s) REFERENCES %s.%s(%s) ON UPDATE %s ON DELETE %s",
+		strings.Join(fk.Columns, ", "), fk.ReferencedSchema, fk.ReferencedTable, strings.Join(fk.ReferencedColumns, ", "), fk.OnUpdate, fk.OnDelete)
+}
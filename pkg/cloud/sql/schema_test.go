@@ -0,0 +1,95 @@
+// Copyright Nitric Pty Ltd.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sql
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func usersTable() *Table {
+	idDefault := "nextval('users_id_seq'::regclass)"
+
+	return &Table{
+		Schema:      "public",
+		Name:        "users",
+		Kind:        TableKind_Table,
+		RowEstimate: 10,
+		Columns: []*Column{
+			{Name: "id", Position: 1, Type: "integer", Default: &idDefault},
+			{Name: "email", Position: 2, Type: "text"},
+		},
+		Indexes: []*Index{
+			{Name: "users_pkey", Columns: []string{"id"}, Unique: true, Primary: true, Definition: "CREATE UNIQUE INDEX users_pkey ON public.users USING btree (id)"},
+		},
+		ForeignKeys: []*ForeignKey{},
+	}
+}
+
+func TestDiffSchemas(t *testing.T) {
+	expected := &DatabaseSchema{
+		Schemas: []string{"public"},
+		Tables: []*Table{
+			usersTable(),
+			{Schema: "public", Name: "orders", Kind: TableKind_Table},
+		},
+	}
+
+	t.Run("matching schemas", func(t *testing.T) {
+		actual := &DatabaseSchema{
+			Schemas: []string{"public"},
+			Tables: []*Table{
+				usersTable(),
+				{Schema: "public", Name: "orders", Kind: TableKind_Table, RowEstimate: 1000},
+			},
+		}
+
+		if diff := cmp.Diff([]*SchemaDifference{}, DiffSchemas(expected, actual)); diff != "" {
+			t.Errorf("DiffSchemas() mismatch (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("drifted schemas", func(t *testing.T) {
+		users := usersTable()
+		users.Columns[1].Type = "character varying(255)"
+		users.Columns[1].Nullable = true
+		users.Columns = append(users.Columns, &Column{Name: "nickname", Position: 3, Type: "text", Nullable: true})
+		users.Indexes = []*Index{}
+
+		actual := &DatabaseSchema{
+			Schemas: []string{"public", "scratch"},
+			Tables: []*Table{
+				users,
+				{Schema: "scratch", Name: "tmp", Kind: TableKind_Table},
+			},
+		}
+
+		want := []*SchemaDifference{
+			{Kind: SchemaDifferenceKind_Unexpected, Object: "schema", Name: "scratch"},
+			{Kind: SchemaDifferenceKind_Changed, Object: "column", Name: "public.users.email", Expected: "text NOT NULL", Actual: "character varying(255)"},
+			{Kind: SchemaDifferenceKind_Unexpected, Object: "column", Name: "public.users.nickname", Actual: "text"},
+			{Kind: SchemaDifferenceKind_Missing, Object: "index", Name: "public.users.users_pkey", Expected: "CREATE UNIQUE INDEX users_pkey ON public.users USING btree (id)"},
+			{Kind: SchemaDifferenceKind_Missing, Object: "table", Name: "public.orders"},
+			{Kind: SchemaDifferenceKind_Unexpected, Object: "table", Name: "scratch.tmp"},
+		}
+
+		if diff := cmp.Diff(want, DiffSchemas(expected, actual)); diff != "" {
+			t.Errorf("DiffSchemas() mismatch (-want +got):\n%s", diff)
+		}
+	})
+}
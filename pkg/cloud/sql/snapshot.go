@@ -0,0 +1,403 @@
+// Copyright Nitric Pty Ltd.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sql
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/pkg/stdcopy"
+
+	"github.com/nitrictech/cli/pkg/docker"
+	"github.com/nitrictech/cli/pkg/paths"
+	"github.com/nitrictech/cli/pkg/system"
+)
+
+const snapshotFileExtension = ".dump"
+
+// branchFile records the git branch the databases were last used with
+const branchFile = ".branch"
+
+var snapshotNameRegex = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+var branchNameReplacer = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// Snapshot is a named set of database dumps stored in the project .nitric directory
+type Snapshot struct {
+	Name      string    `json:"name"`
+	Databases []string  `json:"databases"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// BranchSnapshotName returns the name of the snapshot kept for a git branch. Branch names are sanitized for readability
+// and suffixed with a hash of the branch, so branches that sanitize to the same name, e.g. feature/a and feature_a, don't share a snapshot.
+func BranchSnapshotName(branch string) string {
+	hash := sha256.Sum256([]byte(branch))
+
+	return fmt.Sprintf("branch-%s-%s", branchNameReplacer.ReplaceAllString(branch, "_"), hex.EncodeToString(hash[:])[:8])
+}
+
+func snapshotDir(snapshotsDir string, name string) (string, error) {
+	if !snapshotNameRegex.MatchString(name) {
+		return "", fmt.Errorf("invalid snapshot name %q, names may contain letters, numbers, dots, dashes and underscores", name)
+	}
+
+	return filepath.Join(snapshotsDir, name), nil
+}
+
+// exec runs a command in the database container, writing its stdout to stdout and sending stdin to it when provided
+func (l *LocalSqlServer) exec(ctx context.Context, cmd []string, stdin io.Reader, stdout io.Writer) error {
+	dockerClient, err := docker.New()
+	if err != nil {
+		return err
+	}
+
+	execResp, err := dockerClient.ContainerExecCreate(ctx, l.containerId, types.ExecConfig{
+		Cmd:          cmd,
+		AttachStdin:  stdin != nil,
+		AttachStdout: true,
+		AttachStderr: true,
+	})
+	if err != nil {
+		return err
+	}
+
+	attach, err := dockerClient.ContainerExecAttach(ctx, execResp.ID, types.ExecStartCheck{})
+	if err != nil {
+		return err
+	}
+	defer attach.Close()
+
+	if stdin != nil {
+		go func() {
+			_, _ = io.Copy(attach.Conn, stdin)
+			_ = attach.CloseWrite()
+		}()
+	}
+
+	var stderr bytes.Buffer
+
+	if _, err := stdcopy.StdCopy(stdout, &stderr, attach.Reader); err != nil {
+		return err
+	}
+
+	inspect, err := dockerClient.ContainerExecInspect(ctx, execResp.ID)
+	if err != nil {
+		return err
+	}
+
+	if inspect.ExitCode != 0 {
+		return fmt.Errorf("%s exited with status %d: %s", cmd[0], inspect.ExitCode, strings.TrimSpace(stderr.String()))
+	}
+
+	return nil
+}
+
+// CreateSnapshot dumps databases into a named snapshot, all databases are included when none are provided.
+// An existing snapshot is only replaced once every database has been dumped, so it only includes the databases dumped by the latest call.
+func (l *LocalSqlServer) CreateSnapshot(ctx context.Context, name string, databases []string) (*Snapshot, error) {
+	dir, err := snapshotDir(l.snapshotsDir, name)
+	if err != nil {
+		return nil, err
+	}
+
+	existing, err := l.Databases(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(databases) == 0 {
+		databases = existing
+	}
+
+	if err := os.MkdirAll(l.snapshotsDir, os.ModePerm); err != nil {
+		return nil, err
+	}
+
+	// dump to a temporary directory so a failed dump doesn't replace a previous snapshot
+	tmpDir, err := os.MkdirTemp(l.snapshotsDir, "."+name+"-*.tmp")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(tmpDir)
+
+	for _, databaseName := range databases {
+		if !slices.Contains(existing, databaseName) {
+			return nil, fmt.Errorf("database %s doesn't exist", databaseName)
+		}
+
+		dumpFile, err := os.Create(filepath.Join(tmpDir, databaseName+snapshotFileExtension))
+		if err != nil {
+			return nil, err
+		}
+
+		err = l.exec(ctx, []string{"pg_dump", "-U", l.config.Username, "--format=custom", "--no-owner", databaseName}, nil, dumpFile)
+		dumpFile.Close()
+
+		if err != nil {
+			return nil, fmt.Errorf("unable to dump database %s: %w", databaseName, err)
+		}
+	}
+
+	if err := replaceDir(tmpDir, dir); err != nil {
+		return nil, err
+	}
+
+	return readSnapshot(l.snapshotsDir, name)
+}
+
+// replaceDir moves src to dst, replacing dst if it exists. dst is restored if src can't be moved.
+func replaceDir(src string, dst string) error {
+	backup := ""
+
+	if _, err := os.Stat(dst); err == nil {
+		backup = filepath.Join(filepath.Dir(dst), "."+filepath.Base(dst)+".old")
+
+		if err := os.RemoveAll(backup); err != nil {
+			return err
+		}
+
+		if err := os.Rename(dst, backup); err != nil {
+			return err
+		}
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+
+	if err := os.Rename(src, dst); err != nil {
+		if backup != "" {
+			_ = os.Rename(backup, dst)
+		}
+
+		return err
+	}
+
+	if backup != "" {
+		return os.RemoveAll(backup)
+	}
+
+	return nil
+}
+
+// RestoreSnapshot replaces databases with their dumps from a named snapshot, all databases in the snapshot are restored when none are provided
+func (l *LocalSqlServer) RestoreSnapshot(ctx context.Context, name string, databases []string) error {
+	dir, err := snapshotDir(l.snapshotsDir, name)
+	if err != nil {
+		return err
+	}
+
+	snapshot, err := readSnapshot(l.snapshotsDir, name)
+	if err != nil {
+		return err
+	}
+
+	if len(databases) == 0 {
+		databases = snapshot.Databases
+	}
+
+	for _, databaseName := range databases {
+		if !slices.Contains(snapshot.Databases, databaseName) {
+			return fmt.Errorf("snapshot %s doesn't include database %s", name, databaseName)
+		}
+
+		if err := l.DropDatabase(ctx, databaseName); err != nil {
+			return err
+		}
+
+		// the dump includes the extensions of the database, so it isn't initialized
+		if _, err := l.createDatabase(databaseName); err != nil {
+			return err
+		}
+
+		dump, err := os.Open(filepath.Join(dir, databaseName+snapshotFileExtension))
+		if err != nil {
+			return err
+		}
+
+		err = l.exec(ctx, []string{"pg_restore", "-U", l.config.Username, "--no-owner", "-d", databaseName}, dump, io.Discard)
+		dump.Close()
+
+		if err != nil {
+			return fmt.Errorf("unable to restore database %s: %w", databaseName, err)
+		}
+	}
+
+	return nil
+}
+
+// Snapshots returns the database snapshots of a project, sorted by name
+func Snapshots(projectDirectory string) ([]*Snapshot, error) {
+	snapshotsDir := paths.NitricDatabaseSnapshotsDir(projectDirectory)
+
+	entries, err := os.ReadDir(snapshotsDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []*Snapshot{}, nil
+		}
+
+		return nil, err
+	}
+
+	snapshots := []*Snapshot{}
+
+	for _, entry := range entries {
+		// skip the temporary directories of snapshots being created or replaced
+		if !entry.IsDir() || !snapshotNameRegex.MatchString(entry.Name()) {
+			continue
+		}
+
+		snapshot, err := readSnapshot(snapshotsDir, entry.Name())
+		if err != nil {
+			return nil, err
+		}
+
+		snapshots = append(snapshots, snapshot)
+	}
+
+	return snapshots, nil
+}
+
+// DeleteSnapshot removes a named database snapshot of a project
+func DeleteSnapshot(projectDirectory string, name string) error {
+	dir, err := snapshotDir(paths.NitricDatabaseSnapshotsDir(projectDirectory), name)
+	if err != nil {
+		return err
+	}
+
+	if _, err := os.Stat(dir); err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("snapshot %s doesn't exist", name)
+		}
+
+		return err
+	}
+
+	return os.RemoveAll(dir)
+}
+
+func readSnapshot(snapshotsDir string, name string) (*Snapshot, error) {
+	dir := filepath.Join(snapshotsDir, name)
+
+	info, err := os.Stat(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("snapshot %s doesn't exist", name)
+		}
+
+		return nil, err
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot := &Snapshot{
+		Name:      name,
+		Databases: []string{},
+		CreatedAt: info.ModTime(),
+	}
+
+	for _, entry := range entries {
+		if databaseName, ok := strings.CutSuffix(entry.Name(), snapshotFileExtension); ok && !entry.IsDir() {
+			snapshot.Databases = append(snapshot.Databases, databaseName)
+		}
+	}
+
+	sort.Strings(snapshot.Databases)
+
+	return snapshot, nil
+}
+
+// currentGitBranch returns the checked out git branch of the project, it is empty when the project isn't a repository
+func currentGitBranch(dir string) string {
+	cmd := exec.Command("git", "rev-parse", "--abbrev-ref", "HEAD")
+	cmd.Dir = dir
+
+	output, err := cmd.Output()
+	if err != nil {
+		return ""
+	}
+
+	return strings.TrimSpace(string(output))
+}
+
+// swapBranchSnapshots snapshots the databases of the previously used git branch when the branch has changed,
+// then restores the snapshot of the current branch, or starts it with empty databases if it has none
+func (l *LocalSqlServer) swapBranchSnapshots(ctx context.Context, projectDirectory string) error {
+	branch := currentGitBranch(projectDirectory)
+	if branch == "" {
+		return nil
+	}
+
+	branchFilePath := filepath.Join(l.snapshotsDir, branchFile)
+
+	previous, err := os.ReadFile(branchFilePath)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	previousBranch := strings.TrimSpace(string(previous))
+
+	if previousBranch != "" && previousBranch != branch {
+		databases, err := l.Databases(ctx)
+		if err != nil {
+			return err
+		}
+
+		if len(databases) > 0 {
+			system.Logf("saving databases of branch %s", previousBranch)
+
+			if _, err := l.CreateSnapshot(ctx, BranchSnapshotName(previousBranch), databases); err != nil {
+				return fmt.Errorf("unable to snapshot databases of branch %s: %w", previousBranch, err)
+			}
+		}
+
+		for _, databaseName := range databases {
+			if err := l.DropDatabase(ctx, databaseName); err != nil {
+				return err
+			}
+		}
+
+		if _, err := readSnapshot(l.snapshotsDir, BranchSnapshotName(branch)); err == nil {
+			system.Logf("restoring databases of branch %s", branch)
+
+			if err := l.RestoreSnapshot(ctx, BranchSnapshotName(branch), nil); err != nil {
+				return fmt.Errorf("unable to restore databases of branch %s: %w", branch, err)
+			}
+		}
+	}
+
+	if err := os.MkdirAll(l.snapshotsDir, os.ModePerm); err != nil {
+		return err
+	}
+
+	return os.WriteFile(branchFilePath, []byte(branch), 0o600)
+}
@@ -0,0 +1,142 @@
+// Copyright Nitric Pty Ltd.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sql
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/nitrictech/cli/pkg/paths"
+)
+
+func TestBranchSnapshotName(t *testing.T) {
+	names := map[string]string{}
+
+	for _, branch := range []string{"feature/add users", "feature/a", "feature_a", "feature-a"} {
+		name := BranchSnapshotName(branch)
+
+		if !snapshotNameRegex.MatchString(name) {
+			t.Errorf("BranchSnapshotName(%q) = %s, want a valid snapshot name", branch, name)
+		}
+
+		if other, ok := names[name]; ok {
+			t.Errorf("BranchSnapshotName(%q) = BranchSnapshotName(%q) = %s, want a snapshot per branch", branch, other, name)
+		}
+
+		names[name] = branch
+	}
+
+	if got := BranchSnapshotName("feature/add users"); !strings.HasPrefix(got, "branch-feature_add_users-") {
+		t.Errorf("BranchSnapshotName() = %s, want the sanitized branch name as a prefix", got)
+	}
+}
+
+func TestReplaceDir(t *testing.T) {
+	snapshotsDir := t.TempDir()
+	dir := filepath.Join(snapshotsDir, "seeded")
+
+	for _, file := range []string{"seeded/users.dump", "seeded/dropped.dump", ".seeded-1.tmp/users.dump"} {
+		if err := os.MkdirAll(filepath.Dir(filepath.Join(snapshotsDir, file)), os.ModePerm); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := os.WriteFile(filepath.Join(snapshotsDir, file), []byte(file), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := replaceDir(filepath.Join(snapshotsDir, ".seeded-1.tmp"), dir); err != nil {
+		t.Fatalf("replaceDir() error = %v", err)
+	}
+
+	snapshot, err := readSnapshot(snapshotsDir, "seeded")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// dumps of databases that weren't dumped again are removed
+	if diff := cmp.Diff([]string{"users"}, snapshot.Databases); diff != "" {
+		t.Errorf("replaced snapshot databases mismatch (-want +got):\n%s", diff)
+	}
+
+	if contents, _ := os.ReadFile(filepath.Join(dir, "users.dump")); string(contents) != ".seeded-1.tmp/users.dump" {
+		t.Errorf("users.dump = %s, want the new dump", contents)
+	}
+
+	entries, err := os.ReadDir(snapshotsDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(entries) != 1 {
+		t.Errorf("snapshots directory has %d entries, want only the replaced snapshot", len(entries))
+	}
+}
+
+func TestSnapshots(t *testing.T) {
+	projectDir := t.TempDir()
+	snapshotsDir := paths.NitricDatabaseSnapshotsDir(projectDir)
+
+	for _, file := range []string{"seeded/users.dump", "seeded/orders.dump", "seeded/users-123.tmp", "branch-main/users.dump", ".seeded-123.tmp/users.dump"} {
+		if err := os.MkdirAll(filepath.Dir(filepath.Join(snapshotsDir, file)), os.ModePerm); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := os.WriteFile(filepath.Join(snapshotsDir, file), []byte{}, 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := os.WriteFile(filepath.Join(snapshotsDir, branchFile), []byte("main"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	snapshots, err := Snapshots(projectDir)
+	if err != nil {
+		t.Fatalf("Snapshots() error = %v", err)
+	}
+
+	got := map[string][]string{}
+	for _, snapshot := range snapshots {
+		got[snapshot.Name] = snapshot.Databases
+	}
+
+	want := map[string][]string{
+		"branch-main": {"users"},
+		"seeded":      {"orders", "users"},
+	}
+
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Snapshots() mismatch (-want +got):\n%s", diff)
+	}
+
+	if err := DeleteSnapshot(projectDir, "seeded"); err != nil {
+		t.Fatalf("DeleteSnapshot() error = %v", err)
+	}
+
+	if err := DeleteSnapshot(projectDir, "seeded"); err == nil {
+		t.Errorf("expected an error deleting a snapshot that doesn't exist")
+	}
+
+	if err := DeleteSnapshot(projectDir, "../seeded"); err == nil {
+		t.Errorf("expected an error for an invalid snapshot name")
+	}
+}
@@ -18,29 +18,43 @@ package sql
 
 import (
 	"context"
+	"crypto/sha256"
 	"encoding/hex"
 	"fmt"
-	"maps"
 	"net"
 	"net/netip"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/asaskevich/EventBus"
 	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/filters"
 	"github.com/docker/docker/api/types/mount"
 	"github.com/docker/docker/api/types/volume"
+	"github.com/docker/docker/client"
 	"github.com/docker/go-connections/nat"
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/samber/lo"
+	"github.com/sirupsen/logrus"
 	"github.com/spf13/afero"
 	orderedmap "github.com/wk8/go-ordered-map/v2"
 
 	"github.com/nitrictech/cli/pkg/cloud/resources"
 	"github.com/nitrictech/cli/pkg/docker"
 	"github.com/nitrictech/cli/pkg/netx"
+	"github.com/nitrictech/cli/pkg/paths"
+	"github.com/nitrictech/cli/pkg/project/localconfig"
+	"github.com/nitrictech/cli/pkg/system"
 	"github.com/nitrictech/nitric/core/pkg/logger"
 	resourcespb "github.com/nitrictech/nitric/core/pkg/proto/resources/v1"
 	sqlpb "github.com/nitrictech/nitric/core/pkg/proto/sql/v1"
@@ -57,12 +71,26 @@ const (
 )
 
 type DatabaseServer struct {
-	DatabaseName     string
-	Status           string
+	DatabaseName string
+	Status       string
+	// StatusMessage is the latest migration output, or the reason the database is in an error state
+	StatusMessage    string
 	ResourceRegister *resources.ResourceRegister[resourcespb.SqlDatabaseResource]
 	ConnectionString string
 }
 
+// MigrationError is returned by a MigrationRunner when the migrations of a database fail
+type MigrationError struct {
+	DatabaseName string
+	ExitCode     int64
+	// Logs is the tail of the migration output
+	Logs string
+}
+
+func (e *MigrationError) Error() string {
+	return fmt.Sprintf("migrations for database %s exited with status %d\n%s", e.DatabaseName, e.ExitCode, e.Logs)
+}
+
 type (
 	DatabaseName = string
 	State        = map[DatabaseName]*DatabaseServer
@@ -74,38 +102,164 @@ type LocalSqlServer struct {
 	connectionStringHost string
 	port                 int
 	State                State
+	stateLock            sync.RWMutex
 	sqlpb.UnimplementedSqlServer
 
-	migrationRunner MigrationRunner
+	migrationRunner  MigrationRunner
+	config           localconfig.LocalSqlConfiguration
+	projectDirectory string
+	// snapshotsDir is the directory database snapshots are stored in
+	snapshotsDir string
+	// pools holds a connection pool for each connection string used by queries
+	pools     map[string]*pgxpool.Pool
+	poolsLock sync.Mutex
+	// migrating holds a channel for each database with running or pending migrations, closed once they complete
+	migrating     map[DatabaseName]chan struct{}
+	migratingLock sync.Mutex
+	// migrated holds the databases whose first migration run has started, it runs when the database is registered
+	migrated map[DatabaseName]bool
 
 	bus EventBus.Bus
 }
 
-type MigrationRunner = func(fs afero.Fs, servers map[string]*DatabaseServer, databasesToMigrate map[string]*resourcespb.SqlDatabaseResource, useBuilder bool) error
+// MigrationOutput receives the output of the migrations of a database as it is written
+type MigrationOutput = func(databaseName string, output string)
+
+type MigrationRunner = func(fs afero.Fs, servers map[string]*DatabaseServer, databasesToMigrate map[string]*resourcespb.SqlDatabaseResource, useBuilder bool, output MigrationOutput) error
 
 var _ sqlpb.SqlServer = (*LocalSqlServer)(nil)
 
 const localDatabaseTopic = "local_database"
 
+// credentialsLabel labels the database volume with a hash of the credentials it was created with
+const credentialsLabel = "nitric.credentials"
+
 func (l *LocalSqlServer) SubscribeToState(subscriberFunction func(State)) {
 	// ignore the error, it's only returned if the fn param isn't a function
 	_ = l.bus.Subscribe(localDatabaseTopic, subscriberFunction)
 }
 
-func (l *LocalSqlServer) Publish(state State) {
-	l.bus.Publish(localDatabaseTopic, maps.Clone(state))
+func (l *LocalSqlServer) publishState() {
+	l.bus.Publish(localDatabaseTopic, l.GetState())
 }
 
+// GetState returns a copy of the state of the databases
 func (l *LocalSqlServer) GetState() State {
-	return maps.Clone(l.State)
+	l.stateLock.RLock()
+	defer l.stateLock.RUnlock()
+
+	return lo.MapValues(l.State, func(db *DatabaseServer, _ DatabaseName) *DatabaseServer {
+		copied := *db
+
+		return &copied
+	})
 }
 
-func (l *LocalSqlServer) ensureDatabaseExists(databaseName string) (string, error) {
-	// Ensure the database exists
+// setStatus updates the status of a registered database
+func (l *LocalSqlServer) setStatus(databaseName string, status DatabaseStatus, message string) {
+	l.stateLock.Lock()
+	defer l.stateLock.Unlock()
+
+	if db, ok := l.State[databaseName]; ok {
+		db.Status = string(status)
+		db.StatusMessage = message
+	}
+}
+
+const (
+	defaultImage = "postgres"
+	// DefaultVersion is the postgres image tag used when no version is configured
+	DefaultVersion  = "17.6"
+	defaultUsername = "postgres"
+	defaultPassword = "localsecret"
+)
+
+// databaseUrl returns the connection string of a database on the local database server
+func (l *LocalSqlServer) databaseUrl(host string, databaseName string) string {
+	dbUrl := url.URL{
+		Scheme:   "postgresql",
+		User:     url.UserPassword(l.config.Username, l.config.Password),
+		Host:     net.JoinHostPort(host, fmt.Sprint(l.port)),
+		Path:     "/" + databaseName,
+		RawQuery: "sslmode=disable",
+	}
+
+	return dbUrl.String()
+}
+
+// adminConnectionString returns the connection string of the postgres maintenance database
+func (l *LocalSqlServer) adminConnectionString() string {
+	return l.databaseUrl("localhost", "postgres")
+}
+
+func (l *LocalSqlServer) containerName() string {
+	return fmt.Sprintf("nitric-%s-local-sql", l.projectName)
+}
+
+// volumeName returns the name of the data volume, images other than the default have their own volume as their data may not be compatible
+func (l *LocalSqlServer) volumeName() string {
+	if l.config.Image == defaultImage && l.config.Version == DefaultVersion {
+		return fmt.Sprintf("%s-local-sql", l.projectName)
+	}
+
+	image := volumeNameReplacer.ReplaceAllString(fmt.Sprintf("%s-%s", l.config.Image, l.config.Version), "-")
+
+	return fmt.Sprintf("%s-local-sql-%s", l.projectName, image)
+}
+
+var volumeNameReplacer = regexp.MustCompile(`[^a-zA-Z0-9_.-]+`)
+
+// initializeDatabase creates the configured extensions of a database, then runs its init scripts if it was just created
+func (l *LocalSqlServer) initializeDatabase(ctx context.Context, databaseName string, created bool) error {
+	conn, err := pgx.Connect(ctx, l.databaseUrl("localhost", databaseName))
+	if err != nil {
+		return err
+	}
+	defer conn.Close(ctx)
+
+	return l.initialize(ctx, conn, databaseName, created)
+}
+
+// initialize creates the extensions and runs the init scripts configured for a database on a connection,
+// which may be to another database, e.g. a fresh database used to check the drift of its schema
+func (l *LocalSqlServer) initialize(ctx context.Context, conn *pgx.Conn, databaseName string, created bool) error {
+	dbConfig := l.config.Databases[databaseName]
+
+	for _, extension := range append(slices.Clone(l.config.Extensions), dbConfig.Extensions...) {
+		if _, err := conn.Exec(ctx, fmt.Sprintf("CREATE EXTENSION IF NOT EXISTS %s", pgx.Identifier{extension}.Sanitize())); err != nil {
+			return fmt.Errorf("unable to create extension %s in database %s: %w", extension, databaseName, err)
+		}
+	}
+
+	if !created {
+		return nil
+	}
+
+	for _, script := range append(slices.Clone(l.config.InitScripts), dbConfig.InitScripts...) {
+		if !filepath.IsAbs(script) {
+			script = filepath.Join(l.projectDirectory, script)
+		}
+
+		contents, err := os.ReadFile(script)
+		if err != nil {
+			return fmt.Errorf("unable to read init script for database %s: %w", databaseName, err)
+		}
+
+		// without arguments the statements are sent with the simple protocol, so scripts may contain several statements
+		if _, err := conn.Exec(ctx, string(contents)); err != nil {
+			return fmt.Errorf("init script %s failed for database %s: %w", script, databaseName, err)
+		}
+	}
+
+	return nil
+}
+
+// createDatabase creates a database if it doesn't exist, returning true if it was created
+func (l *LocalSqlServer) createDatabase(databaseName string) (bool, error) {
 	// Connect to the PostgreSQL instance
-	conn, err := pgx.Connect(context.Background(), fmt.Sprintf("user=postgres password=localsecret host=localhost port=%d dbname=postgres sslmode=disable", l.port))
+	conn, err := pgx.Connect(context.Background(), l.adminConnectionString())
 	if err != nil {
-		return "", err
+		return false, err
 	}
 	defer conn.Close(context.Background())
 
@@ -115,13 +269,86 @@ func (l *LocalSqlServer) ensureDatabaseExists(databaseName string) (string, erro
 		// If the database already exists, don't treat it as an error
 		if strings.Contains(err.Error(), "already exists") {
 			logger.Debugf("Database %s already exists", databaseName)
-		} else {
-			return "", err
+
+			return false, nil
 		}
+
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (l *LocalSqlServer) ensureDatabaseExists(databaseName string) (string, error) {
+	// Ensure the database exists
+	created, err := l.createDatabase(databaseName)
+	if err != nil {
+		return "", err
+	}
+
+	if err := l.initializeDatabase(context.Background(), databaseName, created); err != nil {
+		return "", err
 	}
 
 	// Return the connection string of the new database
-	return fmt.Sprintf("postgresql://postgres:localsecret@%s:%d/%s?sslmode=disable", l.connectionStringHost, l.port, databaseName), nil
+	return l.databaseUrl(l.connectionStringHost, databaseName), nil
+}
+
+// findRunningContainer finds the database container of the project when it is already running, e.g. during nitric start
+func (l *LocalSqlServer) findRunningContainer(dockerClient *docker.Docker) (bool, error) {
+	containers, err := dockerClient.ContainerList(context.Background(), container.ListOptions{
+		Filters: filters.NewArgs(filters.Arg("name", fmt.Sprintf("^/%s$", l.containerName()))),
+	})
+	if err != nil {
+		return false, err
+	}
+
+	for _, c := range containers {
+		for _, port := range c.Ports {
+			if port.PrivatePort == 5432 && port.PublicPort != 0 {
+				l.containerId = c.ID
+				l.port = int(port.PublicPort)
+
+				return true, nil
+			}
+		}
+	}
+
+	return false, nil
+}
+
+// credentialsHash identifies the credentials a database volume was created with, without storing them on the volume
+func (l *LocalSqlServer) credentialsHash() string {
+	hash := sha256.Sum256([]byte(l.config.Username + "\x00" + l.config.Password))
+
+	return hex.EncodeToString(hash[:])
+}
+
+// databaseVolume returns the persistent volume of the databases, creating it if it doesn't exist
+// postgres only applies its credentials when the volume is first initialized, so changed credentials are reported here instead of failing to connect.
+func (l *LocalSqlServer) databaseVolume(dockerClient *docker.Docker) (volume.Volume, error) {
+	existing, err := dockerClient.VolumeInspect(context.Background(), l.volumeName())
+	if err != nil && !client.IsErrNotFound(err) {
+		return volume.Volume{}, err
+	}
+
+	if err == nil {
+		// volumes created by older versions of the CLI aren't labelled
+		if hash, ok := existing.Labels[credentialsLabel]; ok && hash != l.credentialsHash() {
+			return volume.Volume{}, fmt.Errorf("the local databases were created with a different sql username or password, restore them in local.nitric.yaml or remove the databases with 'docker volume rm %s'", l.volumeName())
+		}
+
+		return existing, nil
+	}
+
+	// create a persistent volume for the database
+	return dockerClient.VolumeCreate(context.Background(), volume.CreateOptions{
+		Driver: "local",
+		Name:   l.volumeName(),
+		Labels: map[string]string{
+			credentialsLabel: l.credentialsHash(),
+		},
+	})
 }
 
 func (l *LocalSqlServer) start() error {
@@ -136,18 +363,26 @@ func (l *LocalSqlServer) start() error {
 		return err
 	}
 
-	err = dockerClient.ImagePull("postgres:17.6", types.ImagePullOptions{
+	// reuse the container if it is already running
+	running, err := l.findRunningContainer(dockerClient)
+	if err != nil {
+		return err
+	}
+
+	if running {
+		return nil
+	}
+
+	image := fmt.Sprintf("%s:%s", l.config.Image, l.config.Version)
+
+	err = dockerClient.ImagePull(image, types.ImagePullOptions{
 		All: false,
 	})
 	if err != nil {
 		return err
 	}
 
-	// create a persistent volume for the database
-	volume, err := dockerClient.VolumeCreate(context.Background(), volume.CreateOptions{
-		Driver: "local",
-		Name:   fmt.Sprintf("%s-local-sql", l.projectName),
-	})
+	volume, err := l.databaseVolume(dockerClient)
 	if err != nil {
 		return err
 	}
@@ -164,9 +399,10 @@ func (l *LocalSqlServer) start() error {
 	_ = newLis.Close()
 
 	l.containerId, err = dockerClient.ContainerCreate(&container.Config{
-		Image: "postgres:17.6",
+		Image: image,
 		Env: []string{
-			"POSTGRES_PASSWORD=localsecret",
+			fmt.Sprintf("POSTGRES_USER=%s", l.config.Username),
+			fmt.Sprintf("POSTGRES_PASSWORD=%s", l.config.Password),
 			"PGDATA=/var/lib/postgresql/data/pgdata",
 		},
 	}, &container.HostConfig{
@@ -185,7 +421,7 @@ func (l *LocalSqlServer) start() error {
 				},
 			},
 		},
-	}, nil, fmt.Sprintf("nitric-%s-local-sql", l.projectName))
+	}, nil, l.containerName())
 	if err != nil {
 		return err
 	}
@@ -199,6 +435,8 @@ func (l *LocalSqlServer) Stop() error {
 		return err
 	}
 
+	l.closePools()
+
 	err = dockerClient.ContainerStop(context.Background(), l.containerId, container.StopOptions{})
 	if err != nil {
 		return err
@@ -209,7 +447,84 @@ func (l *LocalSqlServer) Stop() error {
 	return nil
 }
 
+// waitForReady blocks until the database server accepts connections
+func (l *LocalSqlServer) waitForReady(ctx context.Context) error {
+	for {
+		conn, err := pgx.Connect(ctx, l.adminConnectionString())
+		if err == nil {
+			return conn.Close(ctx)
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("timed out waiting for the local database to start: %w", err)
+		case <-time.After(500 * time.Millisecond):
+		}
+	}
+}
+
+// Databases returns the names of the databases on the local database server
+func (l *LocalSqlServer) Databases(ctx context.Context) ([]string, error) {
+	conn, err := pgx.Connect(ctx, l.adminConnectionString())
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close(ctx)
+
+	rows, err := conn.Query(ctx, "SELECT datname FROM pg_database WHERE NOT datistemplate AND datname <> 'postgres' ORDER BY datname")
+	if err != nil {
+		return nil, err
+	}
+
+	return pgx.CollectRows(rows, pgx.RowTo[string])
+}
+
+// DropDatabase drops a database, closing any open connections to it
+func (l *LocalSqlServer) DropDatabase(ctx context.Context, databaseName string) error {
+	conn, err := pgx.Connect(ctx, l.adminConnectionString())
+	if err != nil {
+		return err
+	}
+	defer conn.Close(ctx)
+
+	_, err = conn.Exec(ctx, "SELECT pg_terminate_backend(pid) FROM pg_stat_activity WHERE datname = $1 AND pid <> pg_backend_pid()", databaseName)
+	if err != nil {
+		return err
+	}
+
+	_, err = conn.Exec(ctx, "DROP DATABASE IF EXISTS "+pgx.Identifier{databaseName}.Sanitize())
+
+	return err
+}
+
+// waitForMigrations blocks until any running migrations for the database complete, returning an error if they failed
+func (l *LocalSqlServer) waitForMigrations(ctx context.Context, databaseName string) error {
+	l.migratingLock.Lock()
+	done, ok := l.migrating[databaseName]
+	l.migratingLock.Unlock()
+
+	if ok {
+		select {
+		case <-done:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	db, ok := l.GetState()[databaseName]
+	if ok && db.Status == string(DatabaseStatusError) {
+		return fmt.Errorf("database %s is unavailable: %s", databaseName, db.StatusMessage)
+	}
+
+	return nil
+}
+
 func (l *LocalSqlServer) ConnectionString(ctx context.Context, req *sqlpb.SqlConnectionStringRequest) (*sqlpb.SqlConnectionStringResponse, error) {
+	// services that depend on the database wait for its migrations to succeed
+	if err := l.waitForMigrations(ctx, req.DatabaseName); err != nil {
+		return nil, err
+	}
+
 	connectionString, err := l.ensureDatabaseExists(req.DatabaseName)
 	if err != nil {
 		return nil, err
@@ -221,35 +536,54 @@ func (l *LocalSqlServer) ConnectionString(ctx context.Context, req *sqlpb.SqlCon
 	}, nil
 }
 
-// create a function that will execute a query on the local database
-func (l *LocalSqlServer) Query(ctx context.Context, connectionString string, query string) ([]*orderedmap.OrderedMap[string, any], error) {
-	// Connect to the PostgreSQL instance using the provided connection string
-	conn, err := pgx.Connect(ctx, connectionString)
+// Query executes the statements of a query on a local database, returning the rows of the last statement that returned rows.
+// The statements run in a single transaction, which is rolled back instead of committed when requested or read only.
+func (l *LocalSqlServer) Query(ctx context.Context, connectionString string, query string, opts QueryOptions) (*QueryResult, error) {
+	commands := []string{}
+
+	// Split commands from string
+	for _, command := range SQLSplit(query) {
+		if command = strings.TrimSpace(command); command != "" {
+			commands = append(commands, command)
+		}
+	}
+
+	if len(opts.Params) > 0 && len(commands) > 1 {
+		return nil, fmt.Errorf("parameters can only be used with a single statement, the query has %d", len(commands))
+	}
+
+	pool, err := l.pool(ctx, connectionString)
 	if err != nil {
 		return nil, err
 	}
 
-	defer conn.Close(ctx)
-
 	// Begin transaction
-	tx, err := conn.Begin(ctx)
+	tx, err := pool.BeginTx(ctx, opts.Transaction.txOptions())
 	if err != nil {
 		return nil, err
 	}
 
-	// Split commands from string
-	commands := SQLSplit(query)
-
-	results := []*orderedmap.OrderedMap[string, any]{}
+	result := &QueryResult{
+		Columns: []string{},
+		Rows:    []*orderedmap.OrderedMap[string, any]{},
+	}
 
 	// Execute each command
-	for _, command := range commands {
-		command = strings.TrimSpace(command)
-		if command == "" {
-			continue
+	for i, command := range commands {
+		offset, limit := 0, 0
+
+		// paginate the rows of the last statement
+		if i == len(commands)-1 && opts.Limit > 0 {
+			limit = opts.Limit
+
+			if paginated, ok := paginate(command, opts.Limit+1, opts.Offset); ok {
+				command = paginated
+			} else {
+				offset = opts.Offset
+			}
 		}
 
-		rows, err := tx.Query(ctx, command)
+		rows, err := tx.Query(ctx, command, opts.Params...)
 		if err != nil {
 			_ = tx.Rollback(ctx)
 
@@ -258,17 +592,32 @@ func (l *LocalSqlServer) Query(ctx context.Context, connectionString string, que
 
 		if rows.Next() {
 			// Process the query results
-			results, err = processRows(rows)
-			rows.Close()
+			result.Columns = columnNames(rows)
+			result.Rows, result.HasMore, err = processRows(rows, offset, limit)
+		}
 
-			if err != nil {
-				_ = tx.Rollback(ctx)
+		rows.Close()
 
-				return nil, err
-			}
-		} else {
-			rows.Close()
+		if err == nil {
+			err = rows.Err()
 		}
+
+		if err != nil {
+			_ = tx.Rollback(ctx)
+
+			return nil, err
+		}
+
+		result.RowsAffected = rows.CommandTag().RowsAffected()
+	}
+
+	switch opts.Transaction {
+	case TransactionMode_Rollback:
+		result.RolledBack = true
+
+		return result, tx.Rollback(ctx)
+	case TransactionMode_ReadOnly:
+		return result, tx.Rollback(ctx)
 	}
 
 	// Commit the transaction
@@ -276,101 +625,295 @@ func (l *LocalSqlServer) Query(ctx context.Context, connectionString string, que
 		return nil, err
 	}
 
-	return results, nil
+	return result, nil
+}
+
+// migrationErrors collects the migration errors of each failed database from a wrapped or joined error
+func migrationErrors(err error, failed map[DatabaseName]*MigrationError) {
+	switch e := err.(type) {
+	case *MigrationError:
+		failed[e.DatabaseName] = e
+	case interface{ Unwrap() []error }:
+		for _, wrapped := range e.Unwrap() {
+			migrationErrors(wrapped, failed)
+		}
+	case interface{ Unwrap() error }:
+		migrationErrors(e.Unwrap(), failed)
+	}
 }
 
 func (l *LocalSqlServer) BuildAndRunMigrations(fs afero.Fs, databasesToMigrate map[string]*resourcespb.SqlDatabaseResource, useBuilder bool) error {
+	l.migratingLock.Lock()
+
 	// Update the migration status
 	for dbName := range databasesToMigrate {
-		l.State[dbName].Status = string(DatabaseStatusApplyingMigrations)
+		l.setStatus(dbName, DatabaseStatusApplyingMigrations, "")
+
+		if _, ok := l.migrating[dbName]; !ok {
+			l.migrating[dbName] = make(chan struct{})
+		}
 	}
 
-	l.Publish(l.State)
+	l.migratingLock.Unlock()
+
+	l.publishState()
+
+	defer func() {
+		l.migratingLock.Lock()
+		defer l.migratingLock.Unlock()
 
+		for dbName := range databasesToMigrate {
+			if done, ok := l.migrating[dbName]; ok {
+				close(done)
+				delete(l.migrating, dbName)
+			}
+		}
+	}()
+
+	state := l.GetState()
 	servers := map[string]*DatabaseServer{}
 
 	// only run migrations for databases that are keys in databasesToMigrate
 	for dbName := range databasesToMigrate {
-		servers[dbName] = l.State[dbName]
+		servers[dbName] = state[dbName]
 	}
 
-	err := l.migrationRunner(fs, servers, databasesToMigrate, useBuilder)
-	if err != nil {
-		return err
-	}
+	// output is written concurrently by the migrations of each database
+	err := l.migrationRunner(fs, servers, databasesToMigrate, useBuilder, func(dbName string, output string) {
+		for _, line := range strings.Split(strings.TrimSpace(output), "\n") {
+			if line == "" {
+				continue
+			}
+
+			system.GetServiceLogger().WriteLog(logrus.InfoLevel, line, fmt.Sprintf("%s-migrations", dbName))
+
+			l.setStatus(dbName, DatabaseStatusApplyingMigrations, line)
+		}
 
-	// Update the status to running
+		l.publishState()
+	})
+
+	failed := map[DatabaseName]*MigrationError{}
+	migrationErrors(err, failed)
+
+	// Update the status of each database, errors that aren't from a specific database fail them all
 	for dbName := range databasesToMigrate {
-		l.State[dbName].Status = string(DatabaseStatusActive)
+		switch {
+		case failed[dbName] != nil:
+			l.setStatus(dbName, DatabaseStatusError, failed[dbName].Error())
+		case err != nil && len(failed) == 0:
+			l.setStatus(dbName, DatabaseStatusError, err.Error())
+		default:
+			l.setStatus(dbName, DatabaseStatusActive, "")
+		}
 	}
 
-	l.Publish(l.State)
+	l.publishState()
 
 	return err
 }
 
 func (l *LocalSqlServer) RegisterDatabases(lrs resources.LocalResourcesState) {
-	// reset the state
-	l.State = make(State)
+	previous := l.GetState()
+	state := make(State)
 
 	// Check for new databases to migrate
 	for dbName, r := range lrs.SqlDatabases.GetAll() {
-		_, ok := l.State[dbName]
+		state[dbName] = &DatabaseServer{
+			Status:           string(DatabaseStatusStarting),
+			ResourceRegister: r,
+			ConnectionString: "",
+		}
 
-		if !ok {
-			l.State[dbName] = &DatabaseServer{
-				Status:           string(DatabaseStatusStarting),
-				ResourceRegister: r,
-				ConnectionString: "",
-			}
+		connectionString, err := l.ensureDatabaseExists(dbName)
+		if err != nil {
+			// Mark database as errored, e.g. when one of its extensions or init scripts fails
+			state[dbName].Status = string(DatabaseStatusError)
+			state[dbName].StatusMessage = err.Error()
 
-			connectionString, err := l.ensureDatabaseExists(dbName)
-			if err != nil {
-				// Mark database as errored
-				l.State[dbName].Status = string(DatabaseStatusError)
-			}
+			continue
+		}
+
+		// Update the connection string
+		state[dbName].ConnectionString = connectionString
+		state[dbName].Status = string(DatabaseStatusActive)
+
+		// keep the migration status of databases that were already registered, databases that failed to register have no connection string
+		if prev, ok := previous[dbName]; ok && prev.ConnectionString != "" && prev.Status != string(DatabaseStatusStarting) {
+			state[dbName].Status = prev.Status
+			state[dbName].StatusMessage = prev.StatusMessage
+		}
+	}
+
+	l.stateLock.Lock()
+	l.State = state
+	l.stateLock.Unlock()
+
+	l.runFirstMigrations(state)
+
+	l.publishState()
+}
+
+// runFirstMigrations starts the first migration run of newly registered databases with migrations.
+// Requests for their connection strings wait for the run, as it's marked as running before this returns.
+func (l *LocalSqlServer) runFirstMigrations(state State) {
+	if l.migrationRunner == nil {
+		return
+	}
+
+	l.migratingLock.Lock()
+	defer l.migratingLock.Unlock()
 
-			// Update the connection string
-			l.State[dbName].ConnectionString = connectionString
-			l.State[dbName].Status = string(DatabaseStatusActive)
+	databasesToMigrate := map[string]*resourcespb.SqlDatabaseResource{}
+
+	for dbName, db := range state {
+		if l.migrated[dbName] || db.ResourceRegister == nil || db.ResourceRegister.Resource.GetMigrations().GetMigrationsPath() == "" {
+			continue
+		}
+
+		// databases that couldn't be created or initialized are migrated when they're registered again without errors
+		if db.Status == string(DatabaseStatusError) {
+			continue
+		}
+
+		l.migrated[dbName] = true
+		databasesToMigrate[dbName] = db.ResourceRegister.Resource
+
+		if _, ok := l.migrating[dbName]; !ok {
+			l.migrating[dbName] = make(chan struct{})
 		}
 	}
 
-	l.Publish(l.State)
+	if len(databasesToMigrate) == 0 {
+		return
+	}
+
+	go func() {
+		err := l.BuildAndRunMigrations(afero.NewOsFs(), databasesToMigrate, false)
+		if err != nil {
+			logger.Errorf("error running migrations: %s", err.Error())
+		}
+	}()
+}
+
+type LocalSqlServerOptions struct {
+	MigrationRunner MigrationRunner
+	// ConnectionStringHost is the host used in database connection strings, defaults to localhost
+	ConnectionStringHost string
+	// ProjectDirectory is the directory of the project, snapshots are stored in its .nitric directory
+	ProjectDirectory string
+	// Config is the local database configuration from local.nitric.yaml
+	Config localconfig.LocalSqlConfiguration
 }
 
-func NewLocalSqlServer(projectName string, localResources *resources.LocalResourcesService, migrationRunner MigrationRunner, connectionStringHost string) (*LocalSqlServer, error) {
-	if connectionStringHost == "" {
+func newLocalSqlServer(projectName string, opts LocalSqlServerOptions) *LocalSqlServer {
+	if opts.ConnectionStringHost == "" {
 		// default to localhost
-		connectionStringHost = "localhost"
+		opts.ConnectionStringHost = "localhost"
 	}
 
-	localSql := &LocalSqlServer{
+	opts.Config.Image, _ = lo.Coalesce(opts.Config.Image, defaultImage)
+	opts.Config.Version, _ = lo.Coalesce(opts.Config.Version, DefaultVersion)
+	opts.Config.Username, _ = lo.Coalesce(opts.Config.Username, defaultUsername)
+	opts.Config.Password, _ = lo.Coalesce(opts.Config.Password, defaultPassword)
+
+	return &LocalSqlServer{
 		projectName:          projectName,
 		State:                make(State),
 		bus:                  EventBus.New(),
-		migrationRunner:      migrationRunner,
-		connectionStringHost: connectionStringHost,
+		migrationRunner:      opts.MigrationRunner,
+		migrating:            map[DatabaseName]chan struct{}{},
+		migrated:             map[DatabaseName]bool{},
+		pools:                map[string]*pgxpool.Pool{},
+		connectionStringHost: opts.ConnectionStringHost,
+		config:               opts.Config,
+		projectDirectory:     opts.ProjectDirectory,
+		snapshotsDir:         paths.NitricDatabaseSnapshotsDir(opts.ProjectDirectory),
 	}
+}
+
+func NewLocalSqlServer(projectName string, localResources *resources.LocalResourcesService, opts LocalSqlServerOptions) (*LocalSqlServer, error) {
+	localSql := newLocalSqlServer(projectName, opts)
 
 	err := localSql.start()
 	if err != nil {
 		return nil, err
 	}
 
+	if opts.Config.BranchSnapshots {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+		defer cancel()
+
+		if err := localSql.waitForReady(ctx); err != nil {
+			return nil, err
+		}
+
+		if err := localSql.swapBranchSnapshots(ctx, opts.ProjectDirectory); err != nil {
+			return nil, err
+		}
+	}
+
 	// subscribe to local resources for migrations
 	localResources.SubscribeToState(localSql.RegisterDatabases)
 
 	return localSql, nil
 }
 
-func processRows(rows pgx.Rows) ([]*orderedmap.OrderedMap[string, any], error) {
+// ConnectLocalSqlServer connects to the database container of a running project, starting the container when it isn't running.
+// started is true when the container was started and should be stopped by the caller.
+func ConnectLocalSqlServer(projectName string, opts LocalSqlServerOptions) (server *LocalSqlServer, started bool, err error) {
+	localSql := newLocalSqlServer(projectName, opts)
+
+	dockerClient, err := docker.New()
+	if err != nil {
+		return nil, false, err
+	}
+
+	running, err := localSql.findRunningContainer(dockerClient)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if !running {
+		if err := localSql.start(); err != nil {
+			return nil, false, err
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	if err := localSql.waitForReady(ctx); err != nil {
+		if !running {
+			_ = localSql.Stop()
+		}
+
+		return nil, false, err
+	}
+
+	return localSql, !running, nil
+}
+
+// processRows reads the rows of a query from the current row, skipping offset rows and reading at most limit rows when it isn't zero
+func processRows(rows pgx.Rows, offset int, limit int) ([]*orderedmap.OrderedMap[string, any], bool, error) {
 	fieldDescriptions := rows.FieldDescriptions()
 	numColumns := len(fieldDescriptions)
 
 	results := []*orderedmap.OrderedMap[string, any]{}
+	hasMore := false
+
+	for ; offset > 0; offset-- {
+		if !rows.Next() {
+			return results, false, rows.Err()
+		}
+	}
 
 	for {
+		if limit > 0 && len(results) == limit {
+			hasMore = true
+			break
+		}
+
 		values := make([]interface{}, numColumns)
 		valuePointers := make([]interface{}, numColumns)
 
@@ -380,7 +923,7 @@ func processRows(rows pgx.Rows) ([]*orderedmap.OrderedMap[string, any], error) {
 
 		err := rows.Scan(valuePointers...)
 		if err != nil {
-			return nil, fmt.Errorf("failed to scan row: %w", err)
+			return nil, false, fmt.Errorf("failed to scan row: %w", err)
 		}
 
 		row := orderedmap.New[string, any]()
@@ -412,7 +955,7 @@ func processRows(rows pgx.Rows) ([]*orderedmap.OrderedMap[string, any], error) {
 			case [16]uint8:
 				u, err := uuid.FromBytes(v[:])
 				if err != nil {
-					return nil, fmt.Errorf("failed to parse UUID: %w", err)
+					return nil, false, fmt.Errorf("failed to parse UUID: %w", err)
 				}
 
 				val = u.String()
@@ -429,10 +972,10 @@ func processRows(rows pgx.Rows) ([]*orderedmap.OrderedMap[string, any], error) {
 	}
 
 	if rows.Err() != nil {
-		return nil, fmt.Errorf("row iteration failed: %w", rows.Err())
+		return nil, false, fmt.Errorf("row iteration failed: %w", rows.Err())
 	}
 
-	return results, nil
+	return results, hasMore, nil
 }
 
 func formatInterval(interval pgtype.Interval) string {
@@ -41,6 +41,21 @@ func SQLSplit(sql string) []string {
 	return l.statements
 }
 
+// SQLSplitTerminated splits sql into the statements terminated by a semicolon and the unterminated text that follows them.
+// Semicolons in strings, quoted identifiers and comments don't terminate a statement.
+func SQLSplitTerminated(sql string) ([]string, string) {
+	l := &sqlLexer{
+		src:     sql,
+		stateFn: rawState,
+	}
+
+	for l.stateFn != nil {
+		l.stateFn = l.stateFn(l)
+	}
+
+	return l.statements[:l.terminated], sql[l.terminatedEnd:]
+}
+
 type sqlLexer struct {
 	src     string
 	start   int
@@ -49,6 +64,9 @@ type sqlLexer struct {
 	stateFn stateFn
 
 	statements []string
+	// terminated is the number of statements terminated by a semicolon, which end at terminatedEnd
+	terminated    int
+	terminatedEnd int
 }
 
 func (l *sqlLexer) addStatement(s string) {
@@ -85,6 +103,8 @@ func rawState(l *sqlLexer) stateFn {
 		case ';':
 			l.addStatement(l.src[l.start:l.pos])
 			l.start = l.pos
+			l.terminated = len(l.statements)
+			l.terminatedEnd = l.pos
 
 			return rawState
 		case '-':
@@ -166,3 +166,61 @@ select 2;`,
 		})
 	}
 }
+
+func TestSplitTerminated(t *testing.T) {
+	for _, tt := range []struct {
+		name       string
+		sql        string
+		statements []string
+		remainder  string
+	}{
+		{
+			name:       "unterminated",
+			sql:        "select 42",
+			statements: []string{},
+			remainder:  "select 42",
+		},
+		{
+			name:       "terminated",
+			sql:        "select 42; select 7;",
+			statements: []string{"select 42;", "select 7;"},
+			remainder:  "",
+		},
+		{
+			name:       "unterminated after terminated",
+			sql:        "select 42;\nselect 7",
+			statements: []string{"select 42;"},
+			remainder:  "\nselect 7",
+		},
+		{
+			name:       "semicolon in string",
+			sql:        "select 'a;",
+			statements: []string{},
+			remainder:  "select 'a;",
+		},
+		{
+			name:       "semicolon in comment",
+			sql:        "select 1 -- ;",
+			statements: []string{},
+			remainder:  "select 1 -- ;",
+		},
+		{
+			name:       "semicolon in dollar quotes",
+			sql:        "DO $$BEGIN PERFORM 1;",
+			statements: []string{},
+			remainder:  "DO $$BEGIN PERFORM 1;",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			statements, remainder := SQLSplitTerminated(tt.sql)
+
+			if diff := cmp.Diff(tt.statements, statements); diff != "" {
+				t.Errorf("SQLSplitTerminated() statements mismatch (-want +got):\n%s", diff)
+			}
+
+			if diff := cmp.Diff(tt.remainder, remainder); diff != "" {
+				t.Errorf("SQLSplitTerminated() remainder mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
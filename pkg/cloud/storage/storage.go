@@ -32,12 +32,14 @@ import (
 
 	"github.com/asaskevich/EventBus"
 	"github.com/gorilla/mux"
+	"github.com/samber/lo"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 
 	jwt "github.com/golang-jwt/jwt/v5"
 
 	"github.com/nitrictech/cli/pkg/cloud/env"
+	"github.com/nitrictech/cli/pkg/cloud/replicas"
 	"github.com/nitrictech/cli/pkg/eventbus"
 	"github.com/nitrictech/cli/pkg/grpcx"
 
@@ -79,11 +81,61 @@ func getSigningSecret() ([]byte, error) {
 	return []byte(*signingSecret), nil
 }
 
+// replicaListeners holds the listener streams of a replica of a service, by bucket, event type and key prefix
+type replicaListeners struct {
+	lock    sync.RWMutex
+	streams map[string]storagepb.StorageListener_ListenServer
+}
+
+func (l *replicaListeners) add(key string, stream storagepb.StorageListener_ListenServer) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	l.streams[key] = stream
+}
+
+func (l *replicaListeners) remove(key string, stream storagepb.StorageListener_ListenServer) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	if l.streams[key] == stream {
+		delete(l.streams, key)
+	}
+}
+
+func (l *replicaListeners) stream(key string) storagepb.StorageListener_ListenServer {
+	l.lock.RLock()
+	defer l.lock.RUnlock()
+
+	return l.streams[key]
+}
+
+func newReplicaListeners() *replicaListeners {
+	return &replicaListeners{
+		streams: map[string]storagepb.StorageListener_ListenServer{},
+	}
+}
+
+// bucketSubscription forwards the events of a bucket to one replica of a service listening with a key prefix
+type bucketSubscription struct {
+	serviceName string
+	topicName   string
+	keyPrefix   string
+	listeners   int
+}
+
 // LocalStorageService - A local implementation of the storage and listeners services, bypasses the gateway to forward storage change events directly to listeners.
 type LocalStorageService struct {
 	listenersLock sync.RWMutex
 	listeners     State
 
+	listenerReplicas  *replicas.Pool[*replicaListeners]
+	subscriptions     map[string]*bucketSubscription
+	subscriptionsLock sync.RWMutex
+	// subscribedTopics are the storage bus topics events are dispatched from, handlers are never unsubscribed
+	// since the bus can't tell apart closures created by the same function
+	subscribedTopics map[string]bool
+
 	storageListener net.Listener
 
 	bus EventBus.Bus
@@ -141,6 +193,83 @@ func (r *LocalStorageService) unregisterListener(serviceName string, registratio
 	r.bus.Publish(localStorageTopic, r.listeners)
 }
 
+// subscribe delivers the events of a bucket with keys matching the prefix to the listeners of a service
+func (r *LocalStorageService) subscribe(serviceName string, topicName string, keyPrefix string) error {
+	r.subscriptionsLock.Lock()
+	defer r.subscriptionsLock.Unlock()
+
+	subscriptionKey := fmt.Sprintf("%s:%s:%s", serviceName, topicName, keyPrefix)
+
+	if subscription, ok := r.subscriptions[subscriptionKey]; ok {
+		subscription.listeners++
+		return nil
+	}
+
+	if !r.subscribedTopics[topicName] {
+		err := eventbus.StorageBus().SubscribeAsync(topicName, func(req *storagepb.ServerMessage) {
+			r.dispatch(topicName, req)
+		}, false)
+		if err != nil {
+			return fmt.Errorf("error subscribing to topic: %s", err.Error())
+		}
+
+		r.subscribedTopics[topicName] = true
+	}
+
+	r.subscriptions[subscriptionKey] = &bucketSubscription{
+		serviceName: serviceName,
+		topicName:   topicName,
+		keyPrefix:   keyPrefix,
+		listeners:   1,
+	}
+
+	return nil
+}
+
+func (r *LocalStorageService) unsubscribe(serviceName string, topicName string, keyPrefix string) {
+	r.subscriptionsLock.Lock()
+	defer r.subscriptionsLock.Unlock()
+
+	subscriptionKey := fmt.Sprintf("%s:%s:%s", serviceName, topicName, keyPrefix)
+
+	subscription, ok := r.subscriptions[subscriptionKey]
+	if !ok {
+		return
+	}
+
+	subscription.listeners--
+	if subscription.listeners <= 0 {
+		delete(r.subscriptions, subscriptionKey)
+	}
+}
+
+// dispatch sends a bucket event to each subscription with a matching key prefix,
+// delivering it to one replica of the subscribed service in round robin order
+func (r *LocalStorageService) dispatch(topicName string, req *storagepb.ServerMessage) {
+	r.subscriptionsLock.RLock()
+	subscriptions := lo.Filter(lo.Values(r.subscriptions), func(subscription *bucketSubscription, _ int) bool {
+		return subscription.topicName == topicName && strings.HasPrefix(req.GetBlobEventRequest().GetBlobEvent().Key, subscription.keyPrefix)
+	})
+	r.subscriptionsLock.RUnlock()
+
+	for _, subscription := range subscriptions {
+		key := fmt.Sprintf("%s:%s", topicName, subscription.keyPrefix)
+
+		for _, replica := range r.listenerReplicas.Replicas(subscription.serviceName) {
+			stream := replica.Manager.stream(key)
+			if stream == nil {
+				continue
+			}
+
+			if err := stream.Send(req); err != nil {
+				fmt.Println("problem sending the event")
+			}
+
+			break
+		}
+	}
+}
+
 func (r *LocalStorageService) GetListeners() map[BucketName]map[serviceName]int {
 	r.listenersLock.RLock()
 	defer r.listenersLock.RUnlock()
@@ -192,23 +321,19 @@ func (r *LocalStorageService) Listen(stream storagepb.StorageListener_ListenServ
 	r.registerListener(serviceName, firstRequest.GetRegistrationRequest())
 	defer r.unregisterListener(serviceName, firstRequest.GetRegistrationRequest())
 
-	fun := func(req *storagepb.ServerMessage) {
-		if strings.HasPrefix(req.GetBlobEventRequest().GetBlobEvent().Key, firstRequest.GetRegistrationRequest().KeyPrefixFilter) {
-			err := stream.Send(req)
-			if err != nil {
-				fmt.Println("problem sending the event")
-			}
-		}
-	}
+	keyPrefix := firstRequest.GetRegistrationRequest().KeyPrefixFilter
+	key := fmt.Sprintf("%s:%s", listenTopicName, keyPrefix)
 
-	err = eventbus.StorageBus().SubscribeAsync(listenTopicName, fun, false)
+	listeners := r.listenerReplicas.Manager(serviceName, grpcx.GetServiceReplicaFromStream(stream))
+	listeners.add(key, stream)
+	defer listeners.remove(key, stream)
+
+	err = r.subscribe(serviceName, listenTopicName, keyPrefix)
 	if err != nil {
-		return fmt.Errorf("error subscribing to topic: %s", err.Error())
+		return err
 	}
 
-	defer func() {
-		_ = eventbus.StorageBus().Unsubscribe(listenTopicName, fun)
-	}()
+	defer r.unsubscribe(serviceName, listenTopicName, keyPrefix)
 
 	// block here...
 	for {
@@ -512,8 +637,11 @@ func NewLocalStorageService(opts StorageOptions) (*LocalStorageService, error) {
 	var err error
 
 	storageService := &LocalStorageService{
-		listeners: map[string]map[string]int{},
-		bus:       EventBus.New(),
+		listeners:        map[string]map[string]int{},
+		listenerReplicas: replicas.NewPool(newReplicaListeners),
+		subscriptions:    map[string]*bucketSubscription{},
+		subscribedTopics: map[string]bool{},
+		bus:              EventBus.New(),
 	}
 
 	storageService.storageListener, err = net.Listen("tcp", ":0")
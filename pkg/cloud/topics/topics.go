@@ -25,13 +25,18 @@ import (
 	"time"
 
 	"github.com/asaskevich/EventBus"
+	"github.com/samber/lo"
+	"golang.org/x/sync/errgroup"
 	"google.golang.org/grpc/codes"
 
+	"github.com/nitrictech/cli/pkg/cloud/health"
+	"github.com/nitrictech/cli/pkg/cloud/replicas"
 	"github.com/nitrictech/cli/pkg/grpcx"
 
 	grpc_errors "github.com/nitrictech/nitric/core/pkg/grpc/errors"
 	"github.com/nitrictech/nitric/core/pkg/logger"
 	topicspb "github.com/nitrictech/nitric/core/pkg/proto/topics/v1"
+	"github.com/nitrictech/nitric/core/pkg/workers"
 	"github.com/nitrictech/nitric/core/pkg/workers/topics"
 )
 
@@ -43,8 +48,9 @@ type (
 type State = map[topicName]map[serviceName]int
 
 type LocalTopicsAndSubscribersService struct {
-	*topics.SubscriberManager
+	workers     *replicas.Pool[*topics.SubscriberManager]
 	subscribers State
+	isReady     health.IsReady
 
 	subscribersLock sync.RWMutex
 
@@ -158,7 +164,79 @@ func (s *LocalTopicsAndSubscribersService) Subscribe(stream topicspb.Subscriber_
 	s.registerSubscriber(serviceName, firstRequest.GetRegistrationRequest())
 	defer s.unregisterSubscriber(serviceName, firstRequest.GetRegistrationRequest())
 
-	return s.SubscriberManager.Subscribe(peekableStream)
+	return s.workers.Manager(serviceName, grpcx.GetServiceReplicaFromStream(stream)).Subscribe(peekableStream)
+}
+
+// deliverToService delivers a message to one replica of a service, load balancing across the ready replicas subscribed to the topic.
+// If none of the replicas are ready, health.ErrNotReady is returned
+func (s *LocalTopicsAndSubscribersService) deliverToService(serviceName string, request *topicspb.ServerMessage) (*topicspb.ClientMessage, error) {
+	waiting := false
+
+	for _, replica := range s.workers.Replicas(serviceName) {
+		if !s.isReady(serviceName, replica.Index) {
+			waiting = true
+			continue
+		}
+
+		resp, err := replica.Manager.HandleRequest(request)
+		if err != nil && strings.HasPrefix(err.Error(), "no workers registered") {
+			continue
+		}
+
+		return resp, err
+	}
+
+	if waiting {
+		return nil, fmt.Errorf("%w, topic %s is waiting for service %s to pass its health check", health.ErrNotReady, request.GetMessageRequest().GetTopicName(), serviceName)
+	}
+
+	return nil, nil
+}
+
+// HandleRequest delivers a message to every service subscribed to the topic, once per service regardless of its number of replicas
+func (s *LocalTopicsAndSubscribersService) HandleRequest(request *topicspb.ServerMessage) (*topicspb.ClientMessage, error) {
+	if request.Id == "" {
+		request.Id = workers.GenerateUniqueId()
+	}
+
+	services := s.workers.Services()
+	responses := make([]*topicspb.ClientMessage, len(services))
+
+	errs := errgroup.Group{}
+
+	for i, serviceName := range services {
+		errs.Go(func() error {
+			resp, err := s.deliverToService(serviceName, request)
+			responses[i] = resp
+
+			return err
+		})
+	}
+
+	if err := errs.Wait(); err != nil {
+		return nil, fmt.Errorf("errors occurred handling subscription: %w", err)
+	}
+
+	delivered := lo.Compact(responses)
+	if len(delivered) == 0 {
+		return nil, fmt.Errorf("no workers registered for topic subscription: %s", request.GetMessageRequest().GetTopicName())
+	}
+
+	return &topicspb.ClientMessage{
+		Content: &topicspb.ClientMessage_MessageResponse{
+			MessageResponse: &topicspb.MessageResponse{
+				Success: lo.EveryBy(delivered, func(resp *topicspb.ClientMessage) bool {
+					return resp.GetMessageResponse().GetSuccess()
+				}),
+			},
+		},
+	}, nil
+}
+
+func (s *LocalTopicsAndSubscribersService) WorkerCount() int {
+	return lo.SumBy(s.workers.All(), func(manager *topics.SubscriberManager) int {
+		return manager.WorkerCount()
+	})
 }
 
 func (s *LocalTopicsAndSubscribersService) deliverEvent(ctx context.Context, req *topicspb.TopicPublishRequest) error {
@@ -171,7 +249,7 @@ func (s *LocalTopicsAndSubscribersService) deliverEvent(ctx context.Context, req
 		},
 	}
 
-	resp, err := s.SubscriberManager.HandleRequest(msg)
+	resp, err := s.HandleRequest(msg)
 	if err != nil {
 		return err
 	}
@@ -239,11 +317,12 @@ func (s *LocalTopicsAndSubscribersService) Publish(ctx context.Context, req *top
 }
 
 // Create new Dev EventService
-func NewLocalTopicsService() (*LocalTopicsAndSubscribersService, error) {
+func NewLocalTopicsService(isReady health.IsReady) (*LocalTopicsAndSubscribersService, error) {
 	return &LocalTopicsAndSubscribersService{
-		SubscriberManager: topics.New(),
-		subscribersLock:   sync.RWMutex{},
-		subscribers:       make(map[string]map[string]int),
-		bus:               EventBus.New(),
+		workers:         replicas.NewPool(topics.New),
+		isReady:         isReady,
+		subscribersLock: sync.RWMutex{},
+		subscribers:     make(map[string]map[string]int),
+		bus:             EventBus.New(),
 	}, nil
 }
@@ -18,14 +18,21 @@ package websockets
 
 import (
 	"context"
+	"encoding/base64"
+	"errors"
 	"fmt"
+	"sort"
+	"strings"
 	"sync"
 	"time"
 	"unicode/utf8"
 
 	"github.com/asaskevich/EventBus"
 	"github.com/fasthttp/websocket"
+	"github.com/samber/lo"
 
+	"github.com/nitrictech/cli/pkg/cloud/health"
+	"github.com/nitrictech/cli/pkg/cloud/replicas"
 	"github.com/nitrictech/cli/pkg/grpcx"
 
 	nitricws "github.com/nitrictech/nitric/core/pkg/proto/websockets/v1"
@@ -39,15 +46,35 @@ type (
 
 type State = map[socketName]map[serviceName][]nitricws.WebsocketEventType
 
+type connection struct {
+	conn        *websocket.Conn
+	writeLock   sync.Mutex
+	queryParams map[string][]string
+	connectedAt time.Time
+}
+
+// write a message to the connection, gorilla websockets only support one concurrent writer
+func (c *connection) write(messageType int, data []byte) error {
+	c.writeLock.Lock()
+	defer c.writeLock.Unlock()
+
+	return c.conn.WriteMessage(messageType, data)
+}
+
 type LocalWebsocketService struct {
-	*websockets.WebsocketManager
-	connections map[string]map[string]*websocket.Conn
+	workers     *replicas.Pool[*websockets.WebsocketManager]
+	connections map[string]map[string]*connection
 	state       State
 	lock        sync.RWMutex
 	serversLock sync.RWMutex
 
 	servers map[string]string
 
+	// binarySockets are the sockets that pass binary messages through as binary frames
+	binarySockets map[string]bool
+
+	isReady health.IsReady
+
 	bus EventBus.Bus
 }
 
@@ -65,13 +92,26 @@ type WebsocketMessage struct {
 	Data         string    `json:"data,omitempty"`
 	Time         time.Time `json:"time,omitempty"`
 	ConnectionID string    `json:"connectionId,omitempty"`
+	// Binary messages have their data base64 encoded
+	Binary bool `json:"binary,omitempty"`
+	// Broadcast messages were sent to every connection of the socket
+	Broadcast bool `json:"broadcast,omitempty"`
+}
+
+type ConnectionInfo struct {
+	ConnectionID string              `json:"connectionId"`
+	QueryParams  map[string][]string `json:"queryParams,omitempty"`
+	ConnectedAt  time.Time           `json:"connectedAt"`
 }
 
 type WebsocketInfo struct {
 	ConnectionCount int                `json:"connectionCount,omitempty"`
+	Connections     []ConnectionInfo   `json:"connections,omitempty"`
 	Messages        []WebsocketMessage `json:"messages,omitempty"`
 }
 
+const binaryNotSupportedMessage = "Binary messages are not currently supported by AWS"
+
 type ActionType string
 
 const (
@@ -174,30 +214,119 @@ func (r *LocalWebsocketService) HandleEvents(stream nitricws.WebsocketHandler_Ha
 	r.registerWebsocketWorker(serviceName, firstRequest.GetRegistrationRequest())
 	defer r.unRegisterWebsocketWorker(serviceName, firstRequest.GetRegistrationRequest())
 
-	return r.WebsocketManager.HandleEvents(peekableStream)
+	return r.workers.Manager(serviceName, grpcx.GetServiceReplicaFromStream(stream)).HandleEvents(peekableStream)
 }
 
-func (r *LocalWebsocketService) RegisterConnection(socket string, connectionId string, connection *websocket.Conn) error {
-	r.lock.Lock()
-	defer r.lock.Unlock()
+// HandleRequest sends a websocket event to a handler of the first service handling the event, load balancing across the ready replicas of that service.
+// If no ready replica handles the event but replicas are still starting, health.ErrNotReady is returned
+func (r *LocalWebsocketService) HandleRequest(request *nitricws.ServerMessage) (*nitricws.ClientMessage, error) {
+	eventRequest := request.GetWebsocketEventRequest()
+	waiting := false
+
+	for _, serviceName := range r.workers.Services() {
+		for _, replica := range r.workers.Replicas(serviceName) {
+			if !r.isReady(serviceName, replica.Index) {
+				waiting = true
+				continue
+			}
+
+			resp, err := replica.Manager.HandleRequest(request)
+			if err != nil && strings.HasPrefix(err.Error(), "no handlers for socket") {
+				continue
+			}
+
+			return resp, err
+		}
+	}
 
-	if r.connections[socket] == nil {
-		r.connections[socket] = make(map[string]*websocket.Conn)
+	if waiting {
+		return nil, fmt.Errorf("%w, socket %s is waiting for its services to pass their health checks", health.ErrNotReady, eventRequest.GetSocketName())
+	}
+
+	return nil, fmt.Errorf("no handlers for socket: %s", eventRequest.GetSocketName())
+}
+
+func (r *LocalWebsocketService) WorkerCount() int {
+	return lo.SumBy(r.workers.All(), func(manager *websockets.WebsocketManager) int {
+		return manager.WorkerCount()
+	})
+}
+
+// connectionInfo returns the live connections to a socket, oldest first. The lock must be held by the caller.
+func (r *LocalWebsocketService) connectionInfo(socket string) []ConnectionInfo {
+	infos := make([]ConnectionInfo, 0, len(r.connections[socket]))
+
+	for connectionId, conn := range r.connections[socket] {
+		infos = append(infos, ConnectionInfo{
+			ConnectionID: connectionId,
+			QueryParams:  conn.queryParams,
+			ConnectedAt:  conn.connectedAt,
+		})
 	}
 
-	r.connections[socket][connectionId] = connection
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].ConnectedAt.Before(infos[j].ConnectedAt)
+	})
+
+	return infos
+}
 
+// publishInfo publishes the current connections of a socket. The lock must be held by the caller.
+func (r *LocalWebsocketService) publishInfo(socket string) {
 	r.publishAction(WebsocketAction[EventItem]{
 		Name: socket,
 		Type: INFO,
 		Event: WebsocketInfo{
 			ConnectionCount: len(r.connections[socket]),
+			Connections:     r.connectionInfo(socket),
 		},
 	})
+}
+
+func (r *LocalWebsocketService) RegisterConnection(socket string, connectionId string, conn *websocket.Conn, queryParams map[string][]string) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if r.connections[socket] == nil {
+		r.connections[socket] = make(map[string]*connection)
+	}
+
+	r.connections[socket][connectionId] = &connection{
+		conn:        conn,
+		queryParams: queryParams,
+		connectedAt: time.Now(),
+	}
+
+	r.publishInfo(socket)
 
 	return nil
 }
 
+// GetConnections returns the live connections to a socket
+func (r *LocalWebsocketService) GetConnections(socket string) []ConnectionInfo {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	return r.connectionInfo(socket)
+}
+
+// encodeMessage determines the frame type used to send data to a socket and the data recorded in the message history
+func (r *LocalWebsocketService) encodeMessage(socket string, data []byte) (int, []byte, WebsocketMessage) {
+	if !isBinaryString(data) {
+		return websocket.TextMessage, data, WebsocketMessage{Data: string(data)}
+	}
+
+	if !r.binarySockets[socket] {
+		// binary is not supported by AWS, so tell user unless the socket has opted in to binary frames
+		return websocket.TextMessage, []byte(binaryNotSupportedMessage), WebsocketMessage{Data: binaryNotSupportedMessage}
+	}
+
+	return websocket.BinaryMessage, data, WebsocketMessage{
+		Data:   base64.StdEncoding.EncodeToString(data),
+		Binary: true,
+	}
+}
+
 func (r *LocalWebsocketService) SocketDetails(ctx context.Context, req *nitricws.WebsocketDetailsRequest) (*nitricws.WebsocketDetailsResponse, error) {
 	gatewayUri, ok := r.servers[req.SocketName]
 	if !ok {
@@ -218,32 +347,53 @@ func (r *LocalWebsocketService) SendMessage(ctx context.Context, req *nitricws.W
 		return nil, fmt.Errorf("could not get connection " + req.ConnectionId)
 	}
 
-	// Determine if the message is a binary message
-	isBinary := isBinaryString(req.Data)
+	messageType, data, message := r.encodeMessage(req.SocketName, req.Data)
 
-	if isBinary {
-		// binary is not supported by AWS, so tell user
-		req.Data = []byte("Binary messages are not currently supported by AWS")
-	}
-
-	err := conn.WriteMessage(websocket.TextMessage, req.Data)
+	err := conn.write(messageType, data)
 	if err != nil {
 		return nil, err
 	}
 
+	message.Time = time.Now()
+	message.ConnectionID = req.ConnectionId
+
 	r.publishAction(WebsocketAction[EventItem]{
-		Name: req.SocketName,
-		Type: MESSAGE,
-		Event: WebsocketMessage{
-			Data:         string(req.Data),
-			Time:         time.Now(),
-			ConnectionID: req.ConnectionId,
-		},
+		Name:  req.SocketName,
+		Type:  MESSAGE,
+		Event: message,
 	})
 
 	return &nitricws.WebsocketSendResponse{}, nil
 }
 
+// Broadcast sends a message to every live connection of a socket
+func (r *LocalWebsocketService) Broadcast(socket string, data []byte) error {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	messageType, data, message := r.encodeMessage(socket, data)
+
+	errs := []error{}
+
+	for connectionId, conn := range r.connections[socket] {
+		err := conn.write(messageType, data)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("connection %s: %w", connectionId, err))
+		}
+	}
+
+	message.Time = time.Now()
+	message.Broadcast = true
+
+	r.publishAction(WebsocketAction[EventItem]{
+		Name:  socket,
+		Type:  MESSAGE,
+		Event: message,
+	})
+
+	return errors.Join(errs...)
+}
+
 func (r *LocalWebsocketService) CloseConnection(ctx context.Context, req *nitricws.WebsocketCloseConnectionRequest) (*nitricws.WebsocketCloseConnectionResponse, error) {
 	r.lock.Lock()
 	defer r.lock.Unlock()
@@ -254,7 +404,7 @@ func (r *LocalWebsocketService) CloseConnection(ctx context.Context, req *nitric
 	}
 
 	// force close the connection
-	err := conn.Close()
+	err := conn.conn.Close()
 	if err != nil {
 		return nil, err
 	}
@@ -262,24 +412,32 @@ func (r *LocalWebsocketService) CloseConnection(ctx context.Context, req *nitric
 	// delete the connection from the pool
 	delete(r.connections[req.SocketName], req.ConnectionId)
 
-	r.publishAction(WebsocketAction[EventItem]{
-		Name: req.SocketName,
-		Type: INFO,
-		Event: WebsocketInfo{
-			ConnectionCount: len(r.connections[req.SocketName]),
-		},
-	})
+	r.publishInfo(req.SocketName)
 
 	return &nitricws.WebsocketCloseConnectionResponse{}, nil
 }
 
-func NewLocalWebsocketService() (*LocalWebsocketService, error) {
+type WebsocketOptions struct {
+	// BinarySockets are the sockets that send binary messages as binary frames, for providers that support them
+	BinarySockets []string
+	// IsReady holds back events from service replicas that haven't passed their health check
+	IsReady health.IsReady
+}
+
+func NewLocalWebsocketService(opts WebsocketOptions) (*LocalWebsocketService, error) {
+	binarySockets := map[string]bool{}
+	for _, socket := range opts.BinarySockets {
+		binarySockets[socket] = true
+	}
+
 	return &LocalWebsocketService{
-		WebsocketManager: websockets.NewWebsocketManager(),
-		connections:      make(map[string]map[string]*websocket.Conn),
-		lock:             sync.RWMutex{},
-		state:            make(map[string]map[string][]nitricws.WebsocketEventType),
-		bus:              EventBus.New(),
+		workers:       replicas.NewPool(websockets.NewWebsocketManager),
+		connections:   make(map[string]map[string]*connection),
+		lock:          sync.RWMutex{},
+		state:         make(map[string]map[string][]nitricws.WebsocketEventType),
+		binarySockets: binarySockets,
+		isReady:       opts.IsReady,
+		bus:           EventBus.New(),
 	}, nil
 }
 
@@ -0,0 +1,272 @@
+// Copyright Nitric Pty Ltd.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package websockets
+
+import (
+	"context"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fasthttp/websocket"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+
+	nitricws "github.com/nitrictech/nitric/core/pkg/proto/websockets/v1"
+)
+
+var binaryData = []byte{0xff, 0xfe, 0x00, 0x01}
+
+// connect opens client connections to a socket, registering each on the server side under the id connection-<n>
+func connect(t *testing.T, svc *LocalWebsocketService, socket string, count int) []*websocket.Conn {
+	upgrader := websocket.Upgrader{}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+
+		_ = svc.RegisterConnection(socket, r.URL.Query().Get("id"), conn, r.URL.Query())
+	}))
+
+	t.Cleanup(srv.Close)
+
+	clients := []*websocket.Conn{}
+
+	for i := 0; i < count; i++ {
+		id := "connection-" + string(rune('a'+i))
+
+		client, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(srv.URL, "http")+"?id="+id, nil)
+		if err != nil {
+			t.Fatalf("Dial() error = %v", err)
+		}
+
+		t.Cleanup(func() { client.Close() })
+
+		// wait for the server to register the connection, so connections are ordered by their index
+		deadline := time.Now().Add(5 * time.Second)
+		for len(svc.GetConnections(socket)) < i+1 {
+			if time.Now().After(deadline) {
+				t.Fatalf("connection %s was not registered", id)
+			}
+
+			time.Sleep(time.Millisecond)
+		}
+
+		clients = append(clients, client)
+	}
+
+	return clients
+}
+
+func TestEncodeMessage(t *testing.T) {
+	for _, tt := range []struct {
+		name          string
+		binarySockets []string
+		data          []byte
+		wantType      int
+		wantData      []byte
+		wantMessage   WebsocketMessage
+	}{
+		{
+			name:        "text",
+			data:        []byte("hello"),
+			wantType:    websocket.TextMessage,
+			wantData:    []byte("hello"),
+			wantMessage: WebsocketMessage{Data: "hello"},
+		},
+		{
+			name:        "binary without opting in",
+			data:        binaryData,
+			wantType:    websocket.TextMessage,
+			wantData:    []byte(binaryNotSupportedMessage),
+			wantMessage: WebsocketMessage{Data: binaryNotSupportedMessage},
+		},
+		{
+			name:          "binary on a binary socket",
+			binarySockets: []string{"chat"},
+			data:          binaryData,
+			wantType:      websocket.BinaryMessage,
+			wantData:      binaryData,
+			wantMessage:   WebsocketMessage{Data: base64.StdEncoding.EncodeToString(binaryData), Binary: true},
+		},
+		{
+			name:          "text on a binary socket",
+			binarySockets: []string{"chat"},
+			data:          []byte("hello"),
+			wantType:      websocket.TextMessage,
+			wantData:      []byte("hello"),
+			wantMessage:   WebsocketMessage{Data: "hello"},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, err := NewLocalWebsocketService(WebsocketOptions{BinarySockets: tt.binarySockets})
+			if err != nil {
+				t.Fatalf("NewLocalWebsocketService() error = %v", err)
+			}
+
+			gotType, gotData, gotMessage := svc.encodeMessage("chat", tt.data)
+
+			if gotType != tt.wantType {
+				t.Errorf("encodeMessage() type = %d, want %d", gotType, tt.wantType)
+			}
+
+			if diff := cmp.Diff(tt.wantData, gotData); diff != "" {
+				t.Errorf("encodeMessage() data mismatch (-want +got):\n%s", diff)
+			}
+
+			if diff := cmp.Diff(tt.wantMessage, gotMessage); diff != "" {
+				t.Errorf("encodeMessage() message mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestBroadcast(t *testing.T) {
+	for _, tt := range []struct {
+		name          string
+		binarySockets []string
+		data          []byte
+		wantType      int
+		wantData      []byte
+		wantMessage   WebsocketMessage
+	}{
+		{
+			name:        "text",
+			data:        []byte("hello"),
+			wantType:    websocket.TextMessage,
+			wantData:    []byte("hello"),
+			wantMessage: WebsocketMessage{Data: "hello", Broadcast: true},
+		},
+		{
+			name:        "binary without opting in",
+			data:        binaryData,
+			wantType:    websocket.TextMessage,
+			wantData:    []byte(binaryNotSupportedMessage),
+			wantMessage: WebsocketMessage{Data: binaryNotSupportedMessage, Broadcast: true},
+		},
+		{
+			name:          "binary on a binary socket",
+			binarySockets: []string{"chat"},
+			data:          binaryData,
+			wantType:      websocket.BinaryMessage,
+			wantData:      binaryData,
+			wantMessage:   WebsocketMessage{Data: base64.StdEncoding.EncodeToString(binaryData), Binary: true, Broadcast: true},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, err := NewLocalWebsocketService(WebsocketOptions{BinarySockets: tt.binarySockets})
+			if err != nil {
+				t.Fatalf("NewLocalWebsocketService() error = %v", err)
+			}
+
+			clients := connect(t, svc, "chat", 2)
+
+			messages := []WebsocketMessage{}
+
+			svc.SubscribeToAction(func(action WebsocketAction[EventItem]) {
+				if message, ok := action.Event.(WebsocketMessage); ok {
+					messages = append(messages, message)
+				}
+			})
+
+			if err := svc.Broadcast("chat", tt.data); err != nil {
+				t.Fatalf("Broadcast() error = %v", err)
+			}
+
+			for i, client := range clients {
+				_ = client.SetReadDeadline(time.Now().Add(5 * time.Second))
+
+				gotType, gotData, err := client.ReadMessage()
+				if err != nil {
+					t.Fatalf("client %d ReadMessage() error = %v", i, err)
+				}
+
+				if gotType != tt.wantType {
+					t.Errorf("client %d message type = %d, want %d", i, gotType, tt.wantType)
+				}
+
+				if diff := cmp.Diff(tt.wantData, gotData); diff != "" {
+					t.Errorf("client %d data mismatch (-want +got):\n%s", i, diff)
+				}
+			}
+
+			if diff := cmp.Diff([]WebsocketMessage{tt.wantMessage}, messages, cmpopts.IgnoreFields(WebsocketMessage{}, "Time")); diff != "" {
+				t.Errorf("Broadcast() published messages mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestGetConnections(t *testing.T) {
+	for _, tt := range []struct {
+		name    string
+		count   int
+		close   []string
+		wantIds []string
+	}{
+		{
+			name:    "no connections",
+			wantIds: []string{},
+		},
+		{
+			name:    "oldest first",
+			count:   3,
+			wantIds: []string{"connection-a", "connection-b", "connection-c"},
+		},
+		{
+			name:    "closed connections are removed",
+			count:   3,
+			close:   []string{"connection-b"},
+			wantIds: []string{"connection-a", "connection-c"},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, err := NewLocalWebsocketService(WebsocketOptions{})
+			if err != nil {
+				t.Fatalf("NewLocalWebsocketService() error = %v", err)
+			}
+
+			connect(t, svc, "chat", tt.count)
+
+			for _, id := range tt.close {
+				_, err := svc.CloseConnection(context.Background(), &nitricws.WebsocketCloseConnectionRequest{SocketName: "chat", ConnectionId: id})
+				if err != nil {
+					t.Fatalf("CloseConnection() error = %v", err)
+				}
+			}
+
+			gotIds := []string{}
+
+			for _, info := range svc.GetConnections("chat") {
+				gotIds = append(gotIds, info.ConnectionID)
+
+				if got := info.QueryParams["id"]; len(got) != 1 || got[0] != info.ConnectionID {
+					t.Errorf("GetConnections() query params for %s = %v", info.ConnectionID, info.QueryParams)
+				}
+			}
+
+			if diff := cmp.Diff(tt.wantIds, gotIds); diff != "" {
+				t.Errorf("GetConnections() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
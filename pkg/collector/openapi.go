@@ -0,0 +1,208 @@
+// Copyright Nitric Pty Ltd.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"encoding/json"
+	"fmt"
+	"mime"
+	"strconv"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+
+	deploymentspb "github.com/nitrictech/nitric/core/pkg/proto/deployments/v1"
+)
+
+// ApiExample is a request and response observed for an API, e.g. from the local dashboard's API history
+type ApiExample struct {
+	Method              string
+	Path                string
+	RequestContentType  string
+	RequestBody         []byte
+	Status              int
+	ResponseContentType string
+	ResponseBody        []byte
+}
+
+// ApiDocumentsFromSpec returns the OpenAPI document for each API in the spec, keyed by API name
+func ApiDocumentsFromSpec(spec *deploymentspb.Spec) (map[string]*openapi3.T, error) {
+	docs := map[string]*openapi3.T{}
+
+	for _, res := range spec.Resources {
+		api := res.GetApi()
+		if api == nil {
+			continue
+		}
+
+		doc := &openapi3.T{}
+
+		if err := json.Unmarshal([]byte(api.GetOpenapi()), doc); err != nil {
+			return nil, fmt.Errorf("unable to read openapi document for api %s: %w", res.Id.Name, err)
+		}
+
+		docs[res.Id.Name] = doc
+	}
+
+	return docs, nil
+}
+
+// pathTemplateMatches returns true if a request path matches an OpenAPI path template, e.g. /customers/123 matches /customers/{id}
+func pathTemplateMatches(template string, path string) bool {
+	templateSegments := strings.Split(strings.Trim(template, "/"), "/")
+	pathSegments := strings.Split(strings.Trim(path, "/"), "/")
+
+	if len(templateSegments) != len(pathSegments) {
+		return false
+	}
+
+	for i, segment := range templateSegments {
+		isParam := strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}")
+
+		if !isParam && segment != pathSegments[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+func isJsonContentType(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
+
+// exampleValue converts a body into an example value, decoding JSON bodies so they're embedded as structured examples
+func exampleValue(contentType string, body []byte) any {
+	if isJsonContentType(contentType) {
+		var value any
+
+		if err := json.Unmarshal(body, &value); err == nil {
+			return value
+		}
+	}
+
+	return string(body)
+}
+
+// schemaFromValue infers a schema from an example value, as a hint for documenting request and response bodies
+func schemaFromValue(value any) *openapi3.Schema {
+	switch v := value.(type) {
+	case map[string]any:
+		schema := openapi3.NewObjectSchema()
+
+		for key, property := range v {
+			schema.WithProperty(key, schemaFromValue(property))
+		}
+
+		return schema
+	case []any:
+		if len(v) == 0 {
+			return openapi3.NewArraySchema()
+		}
+
+		return openapi3.NewArraySchema().WithItems(schemaFromValue(v[0]))
+	case float64:
+		if v == float64(int64(v)) {
+			return openapi3.NewIntegerSchema()
+		}
+
+		return openapi3.NewFloat64Schema()
+	case bool:
+		return openapi3.NewBoolSchema()
+	default:
+		return openapi3.NewStringSchema()
+	}
+}
+
+func mediaTypeForBody(contentType string, body []byte) (string, *openapi3.MediaType) {
+	if contentType == "" {
+		contentType = "text/plain"
+	}
+
+	// drop parameters such as charset from the content type key
+	if mediaType, _, err := mime.ParseMediaType(contentType); err == nil {
+		contentType = mediaType
+	}
+
+	value := exampleValue(contentType, body)
+
+	return contentType, &openapi3.MediaType{
+		Example: value,
+		Schema:  openapi3.NewSchemaRef("", schemaFromValue(value)),
+	}
+}
+
+// AddApiExamples merges observed requests and responses into the matching operations of an OpenAPI document.
+// The most recent example for each operation, content type and status code is kept.
+func AddApiExamples(doc *openapi3.T, examples []ApiExample) {
+	for _, example := range examples {
+		for template, pathItem := range doc.Paths {
+			if !pathTemplateMatches(template, example.Path) {
+				continue
+			}
+
+			operation := pathItem.GetOperation(strings.ToUpper(example.Method))
+			if operation == nil {
+				continue
+			}
+
+			if len(example.RequestBody) > 0 {
+				if operation.RequestBody == nil || operation.RequestBody.Value == nil {
+					operation.RequestBody = &openapi3.RequestBodyRef{Value: openapi3.NewRequestBody()}
+				}
+
+				// a request body declared without content has a nil content map
+				if operation.RequestBody.Value.Content == nil {
+					operation.RequestBody.Value.Content = openapi3.NewContent()
+				}
+
+				contentType, mediaType := mediaTypeForBody(example.RequestContentType, example.RequestBody)
+				operation.RequestBody.Value.Content[contentType] = mediaType
+			}
+
+			if example.Status > 0 {
+				if operation.Responses == nil {
+					operation.Responses = openapi3.NewResponses()
+				}
+
+				status := strconv.Itoa(example.Status)
+
+				responseRef := operation.Responses.Get(example.Status)
+				if responseRef == nil || responseRef.Value == nil {
+					responseRef = &openapi3.ResponseRef{Value: openapi3.NewResponse().WithDescription(fmt.Sprintf("Example %s response", status))}
+					operation.Responses[status] = responseRef
+				}
+
+				response := responseRef.Value
+
+				if len(example.ResponseBody) > 0 {
+					if response.Content == nil {
+						response.Content = openapi3.Content{}
+					}
+
+					contentType, mediaType := mediaTypeForBody(example.ResponseContentType, example.ResponseBody)
+					response.Content[contentType] = mediaType
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,118 @@
+// Copyright Nitric Pty Ltd.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestPathTemplateMatches(t *testing.T) {
+	for _, tt := range []struct {
+		template string
+		path     string
+		expected bool
+	}{
+		{template: "/", path: "/", expected: true},
+		{template: "/customers/{id}", path: "/customers/123", expected: true},
+		{template: "/customers/{id}", path: "/customers/123/", expected: true},
+		{template: "/customers/{id}", path: "/customers", expected: false},
+		{template: "/customers/{id}/orders", path: "/customers/123/invoices", expected: false},
+	} {
+		if actual := pathTemplateMatches(tt.template, tt.path); actual != tt.expected {
+			t.Errorf("expected %s matching %s to be %t", tt.path, tt.template, tt.expected)
+		}
+	}
+}
+
+func TestAddApiExamples(t *testing.T) {
+	doc := &openapi3.T{
+		Paths: openapi3.Paths{
+			"/customers/{id}": &openapi3.PathItem{
+				Get: &openapi3.Operation{Responses: openapi3.NewResponses()},
+			},
+		},
+	}
+
+	AddApiExamples(doc, []ApiExample{
+		{
+			Method:              "GET",
+			Path:                "/customers/123",
+			Status:              200,
+			ResponseContentType: "application/json; charset=utf-8",
+			ResponseBody:        []byte(`{"id":"123","orders":2}`),
+		},
+	})
+
+	response := doc.Paths["/customers/{id}"].Get.Responses.Get(200)
+	if response == nil {
+		t.Fatal("expected a 200 response to be added")
+	}
+
+	mediaType := response.Value.Content.Get("application/json")
+	if mediaType == nil {
+		t.Fatal("expected an application/json response example")
+	}
+
+	if diff := cmp.Diff(map[string]any{"id": "123", "orders": float64(2)}, mediaType.Example); diff != "" {
+		t.Errorf("unexpected example (-want +got):\n%s", diff)
+	}
+
+	if mediaType.Schema.Value.Properties["orders"].Value.Type != openapi3.TypeInteger {
+		t.Errorf("expected orders to be inferred as an integer")
+	}
+}
+
+func TestAddApiExamplesRequestBodyWithoutContent(t *testing.T) {
+	doc := &openapi3.T{
+		Paths: openapi3.Paths{
+			"/customers": &openapi3.PathItem{
+				Post: &openapi3.Operation{
+					RequestBody: &openapi3.RequestBodyRef{Value: &openapi3.RequestBody{Description: "a new customer"}},
+					Responses:   openapi3.NewResponses(),
+				},
+			},
+		},
+	}
+
+	AddApiExamples(doc, []ApiExample{
+		{
+			Method:             "POST",
+			Path:               "/customers",
+			Status:             201,
+			RequestContentType: "application/json",
+			RequestBody:        []byte(`{"name":"Jane"}`),
+		},
+	})
+
+	requestBody := doc.Paths["/customers"].Post.RequestBody.Value
+
+	if requestBody.Description != "a new customer" {
+		t.Errorf("expected the existing request body to be kept, got description %q", requestBody.Description)
+	}
+
+	mediaType := requestBody.Content.Get("application/json")
+	if mediaType == nil {
+		t.Fatal("expected an application/json request example")
+	}
+
+	if diff := cmp.Diff(map[string]any{"name": "Jane"}, mediaType.Example); diff != "" {
+		t.Errorf("unexpected example (-want +got):\n%s", diff)
+	}
+}
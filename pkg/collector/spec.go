@@ -189,6 +189,48 @@ func parseMigrationsScheme(migrationsPath string) (string, string, error) {
 	return result["Scheme"], result["Path"], nil
 }
 
+// MigrationsDirectory returns the migrations directory of a database declared with a file:// migrations path
+func MigrationsDirectory(databaseName string, database *resourcespb.SqlDatabaseResource) (string, error) {
+	if database.GetMigrations().GetMigrationsPath() == "" {
+		return "", fmt.Errorf("database '%s' has no migrations", databaseName)
+	}
+
+	scheme, path, err := parseMigrationsScheme(database.GetMigrations().GetMigrationsPath())
+	if err != nil {
+		return "", err
+	}
+
+	if scheme != "file" {
+		return "", fmt.Errorf("database '%s' migrations use the %s scheme, only file migrations are supported", databaseName, scheme)
+	}
+
+	return path, nil
+}
+
+// SqlDatabases returns the sql databases declared by services and batches
+func SqlDatabases(allServiceRequirements []*ServiceRequirements, allBatchRequirements []*BatchRequirements) map[string]*resourcespb.SqlDatabaseResource {
+	sqlDbs := map[string]*resourcespb.SqlDatabaseResource{}
+
+	for _, serviceRequirements := range allServiceRequirements {
+		for databaseName, databaseConfig := range serviceRequirements.sqlDatabases {
+			// prefer the declaration with migrations
+			if _, ok := sqlDbs[databaseName]; !ok || databaseConfig.GetMigrations() != nil {
+				sqlDbs[databaseName] = databaseConfig
+			}
+		}
+	}
+
+	for _, batchRequirements := range allBatchRequirements {
+		for databaseName, databaseConfig := range batchRequirements.sqlDatabases {
+			if _, ok := sqlDbs[databaseName]; !ok || databaseConfig.GetMigrations() != nil {
+				sqlDbs[databaseName] = databaseConfig
+			}
+		}
+	}
+
+	return sqlDbs
+}
+
 // sqlDatabases to requirements
 func MakeDatabaseServiceRequirements(sqlDatabases map[string]*resourcespb.SqlDatabaseResource) []*ServiceRequirements {
 	serviceRequirements := []*ServiceRequirements{}
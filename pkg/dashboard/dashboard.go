@@ -44,7 +44,9 @@ import (
 	websocketspb "github.com/nitrictech/nitric/core/pkg/proto/websockets/v1"
 
 	"github.com/nitrictech/cli/pkg/cloud/apis"
+	"github.com/nitrictech/cli/pkg/cloud/containers"
 	"github.com/nitrictech/cli/pkg/cloud/gateway"
+	"github.com/nitrictech/cli/pkg/cloud/health"
 	httpproxy "github.com/nitrictech/cli/pkg/cloud/http"
 	"github.com/nitrictech/cli/pkg/cloud/resources"
 	"github.com/nitrictech/cli/pkg/cloud/schedules"
@@ -57,6 +59,7 @@ import (
 	"github.com/nitrictech/cli/pkg/project"
 	"github.com/nitrictech/cli/pkg/update"
 	"github.com/nitrictech/cli/pkg/version"
+	"github.com/nitrictech/cli/pkg/wssession"
 )
 
 type BaseResourceSpec struct {
@@ -87,6 +90,16 @@ type TopicSpec struct {
 	*BaseResourceSpec
 }
 
+type ContainerSpec struct {
+	*BaseResourceSpec
+
+	Image         string `json:"image"`
+	Status        string `json:"status"`
+	StatusMessage string `json:"statusMessage,omitempty"`
+	// Ports maps each container port to the host port it is published on
+	Ports map[int]int `json:"ports"`
+}
+
 type QueueSpec struct {
 	*BaseResourceSpec
 }
@@ -104,6 +117,7 @@ type SQLDatabaseSpec struct {
 
 	ConnectionString string `json:"connectionString"`
 	Status           string `json:"status"`
+	StatusMessage    string `json:"statusMessage,omitempty"`
 	MigrationsPath   string `json:"migrationsPath"`
 }
 
@@ -143,6 +157,8 @@ type ServiceSpec struct {
 	*BaseResourceSpec
 
 	FilePath string `json:"filePath"`
+	// Health is the health of each replica of the service, only set for services with a health check
+	Health []health.ReplicaHealth `json:"health,omitempty"`
 }
 
 type BatchSpec struct {
@@ -172,6 +188,7 @@ type Dashboard struct {
 	gatewayService         *gateway.LocalGatewayService
 	databaseService        *sql.LocalSqlServer
 	secretService          *secrets.DevSecretService
+	websocketService       *websockets.LocalWebsocketService
 	apis                   []ApiSpec
 	apiUseHttps            bool
 	apiSecurityDefinitions map[string]map[string]*resourcespb.ApiSecurityDefinitionResource
@@ -182,12 +199,14 @@ type Dashboard struct {
 	stores                 []*KeyValueSpec
 	secrets                []*SecretSpec
 	sqlDatabases           []*SQLDatabaseSpec
+	containers             []*ContainerSpec
 	websockets             []WebsocketSpec
 	websites               []WebsiteSpec
 	subscriptions          []*SubscriberSpec
 	notifications          []*NotifierSpec
 	httpProxies            []*HttpProxySpec
 	queues                 []*QueueSpec
+	serviceHealth          health.State
 	policies               map[string]PolicySpec
 	envMap                 map[string]string
 
@@ -195,6 +214,8 @@ type Dashboard struct {
 	historyWebSocket *melody.Melody
 	wsWebSocket      *melody.Melody
 	websocketsInfo   map[string]*websockets.WebsocketInfo
+	wsClients        map[string]*wssession.Client
+	wsClientsLock    sync.Mutex
 	port             int
 	browserHasOpened bool
 	noBrowser        bool
@@ -219,6 +240,7 @@ type DashboardResponse struct {
 	Secrets       []*SecretSpec      `json:"secrets"`
 	Queues        []*QueueSpec       `json:"queues"`
 	HttpProxies   []*HttpProxySpec   `json:"httpProxies"`
+	Containers    []*ContainerSpec   `json:"containers"`
 
 	Services []*ServiceSpec `json:"services"`
 
@@ -257,6 +279,7 @@ func (d *Dashboard) getServices() ([]*ServiceSpec, error) {
 				Name: service.GetFilePath(),
 			},
 			FilePath: absPath,
+			Health:   d.serviceHealth[service.GetFilePath()],
 		})
 	}
 
@@ -627,6 +650,7 @@ func (d *Dashboard) updateSqlDatabases(state sql.State) {
 			},
 			ConnectionString: connectionString,
 			Status:           db.Status,
+			StatusMessage:    db.StatusMessage,
 			MigrationsPath:   db.ResourceRegister.Resource.Migrations.GetMigrationsPath(),
 		})
 	}
@@ -642,6 +666,49 @@ func (d *Dashboard) updateSqlDatabases(state sql.State) {
 	d.refresh()
 }
 
+// containerSpecs returns the auxiliary containers of the project sorted by name.
+// They are started with the local cloud, so their state is read when the dashboard is created as well as subscribed to
+func containerSpecs(state containers.State) []*ContainerSpec {
+	containerSpecs := []*ContainerSpec{}
+
+	for name, c := range state {
+		containerSpecs = append(containerSpecs, &ContainerSpec{
+			BaseResourceSpec: &BaseResourceSpec{
+				Name:               name,
+				RequestingServices: []string{},
+			},
+			Image:         c.Image,
+			Status:        string(c.Status),
+			StatusMessage: c.StatusMessage,
+			Ports:         c.Ports,
+		})
+	}
+
+	slices.SortFunc(containerSpecs, func(a, b *ContainerSpec) int {
+		return compare(a.Name, b.Name)
+	})
+
+	return containerSpecs
+}
+
+func (d *Dashboard) updateContainers(state containers.State) {
+	d.resourcesLock.Lock()
+	defer d.resourcesLock.Unlock()
+
+	d.containers = containerSpecs(state)
+
+	d.refresh()
+}
+
+func (d *Dashboard) updateServiceHealth(state health.State) {
+	d.resourcesLock.Lock()
+	defer d.resourcesLock.Unlock()
+
+	d.serviceHealth = state
+
+	d.refresh()
+}
+
 func (d *Dashboard) handleWebsites(state websites.State) {
 	d.resourcesLock.Lock()
 	defer d.resourcesLock.Unlock()
@@ -766,6 +833,10 @@ func (d *Dashboard) Start() error {
 
 	http.HandleFunc("/api/sql/migrate", d.createApplySqlMigrationsHandler(aferoFs, false))
 
+	http.HandleFunc("/api/sql/schema", d.createSqlSchemaHandler())
+
+	http.HandleFunc("/api/sql/drift", d.createSqlDriftHandler(aferoFs, false))
+
 	// handle websockets
 	http.HandleFunc("/ws-info", func(w http.ResponseWriter, r *http.Request) {
 		err := d.wsWebSocket.HandleRequest(w, r)
@@ -776,6 +847,14 @@ func (d *Dashboard) Start() error {
 
 	http.HandleFunc("/api/ws-clear-messages", d.handleWebsocketMessagesClear())
 
+	http.HandleFunc("/api/ws-connections", d.handleWebsocketConnections())
+
+	http.HandleFunc("/api/ws-broadcast", d.handleWebsocketBroadcast())
+
+	http.HandleFunc("/api/ws-client", d.handleWebsocketClient())
+
+	http.HandleFunc("/api/ws-sessions", d.handleWebsocketSessions(aferoFs))
+
 	http.HandleFunc("/api/logs", d.createServiceLogsHandler(d.project))
 
 	d.wsWebSocket.HandleConnect(func(s *melody.Session) {
@@ -862,6 +941,7 @@ func (d *Dashboard) sendStackUpdate() error {
 		Buckets:             d.buckets,
 		Stores:              d.stores,
 		SQLDatabases:        d.sqlDatabases,
+		Containers:          d.containers,
 		Schedules:           d.schedules,
 		Websockets:          d.websockets,
 		Websites:            d.websites,
@@ -940,6 +1020,7 @@ func New(noBrowser bool, localCloud *cloud.LocalCloud, project *project.Project)
 		gatewayService:         localCloud.Gateway,
 		databaseService:        localCloud.Databases,
 		secretService:          localCloud.Secrets,
+		websocketService:       localCloud.Websockets,
 		apis:                   []ApiSpec{},
 		apiUseHttps:            localCloud.Gateway.ApiTlsCredentials != nil,
 		apiSecurityDefinitions: map[string]map[string]*resourcespb.ApiSecurityDefinitionResource{},
@@ -957,11 +1038,13 @@ func New(noBrowser bool, localCloud *cloud.LocalCloud, project *project.Project)
 		websites:               []WebsiteSpec{},
 		stores:                 []*KeyValueSpec{},
 		sqlDatabases:           []*SQLDatabaseSpec{},
+		containers:             containerSpecs(localCloud.Containers.GetState()),
 		secrets:                []*SecretSpec{},
 		queues:                 []*QueueSpec{},
 		httpProxies:            []*HttpProxySpec{},
 		policies:               map[string]PolicySpec{},
 		websocketsInfo:         map[string]*websockets.WebsocketInfo{},
+		wsClients:              map[string]*wssession.Client{},
 		noBrowser:              noBrowser,
 	}
 
@@ -986,6 +1069,8 @@ func New(noBrowser bool, localCloud *cloud.LocalCloud, project *project.Project)
 	localCloud.Http.SubscribeToState(dash.updateHttpProxies)
 	localCloud.Databases.SubscribeToState(dash.updateSqlDatabases)
 	localCloud.Websites.SubscribeToState(dash.handleWebsites)
+	localCloud.Health.SubscribeToState(dash.updateServiceHealth)
+	localCloud.Containers.SubscribeToState(dash.updateContainers)
 
 	// subscribe to history events from gateway
 	localCloud.Apis.SubscribeToAction(dash.handleApiHistory)
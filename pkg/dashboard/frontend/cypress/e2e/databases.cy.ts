@@ -197,6 +197,9 @@ describe('Databases Spec', () => {
         })
         .invoke('html', testQueries)
 
+      // commit the test table instead of rolling it back
+      cy.getTestEl('query-rollback').click()
+
       cy.getTestEl('run-btn').click()
 
       cy.intercept('POST', '/api/sql', (req) => {
@@ -218,7 +221,7 @@ describe('Databases Spec', () => {
       cy.wait('@query').then((interception) => {
         // Validate the response
         expect(interception.response.statusCode).to.equal(200)
-        expect(interception.response.body).to.deep.equal(expectedResults)
+        expect(interception.response.body.rows).to.deep.equal(expectedResults)
       })
     })
 
@@ -233,6 +236,9 @@ describe('Databases Spec', () => {
         })
         .invoke('html', testQueries)
 
+      // commit the test table instead of rolling it back
+      cy.getTestEl('query-rollback').click()
+
       cy.getTestEl('run-btn').click()
 
       cy.intercept('POST', '/api/sql', (req) => {
@@ -263,7 +269,7 @@ describe('Databases Spec', () => {
       cy.wait('@query').then((interception) => {
         // Validate the response
         expect(interception.response.statusCode).to.equal(200)
-        expect(interception.response.body).to.deep.equal(expectedResults)
+        expect(interception.response.body.rows).to.deep.equal(expectedResults)
       })
     })
 
@@ -310,7 +316,7 @@ describe('Databases Spec', () => {
       cy.wait('@query').then((interception) => {
         // Validate the response
         expect(interception.response.statusCode).to.equal(200)
-        expect(interception.response.body).to.deep.equal([
+        expect(interception.response.body.rows).to.deep.equal([
           {
             id: 1,
             name: `${db}-foo`,
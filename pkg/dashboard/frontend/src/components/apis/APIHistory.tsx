@@ -86,7 +86,7 @@ function isJSON(data: string | undefined) {
 }
 
 const ApiHistoryAccordionContent: React.FC<ApiHistoryItem> = ({
-  event: { request, response },
+  event: { request, response, violations },
 }) => {
   const [tabIndex, setTabIndex] = useState(0)
 
@@ -98,6 +98,18 @@ const ApiHistoryAccordionContent: React.FC<ApiHistoryItem> = ({
 
   return (
     <div>
+      {violations && violations.length > 0 && (
+        <div className="mb-4 flex flex-col gap-1 rounded-md bg-red-50 p-4">
+          <p className="text-md font-semibold text-red-800">
+            Contract Violations
+          </p>
+          <ul className="list-inside list-disc text-sm text-red-700">
+            {violations.map((violation) => (
+              <li key={violation}>{violation}</li>
+            ))}
+          </ul>
+        </div>
+      )}
       <Tabs
         tabs={isJson ? jsonTabs : tabs}
         index={tabIndex}
@@ -0,0 +1,54 @@
+import { type ComponentType } from 'react'
+
+import type { Container } from '@/types'
+import type { NodeProps } from 'reactflow'
+import NodeBase, { type NodeBaseData } from './NodeBase'
+
+export type ContainerNodeData = NodeBaseData<Container>
+
+export const ContainerNode: ComponentType<NodeProps<ContainerNodeData>> = (
+  props,
+) => {
+  const { data } = props
+
+  const ports = Object.entries(data.resource.ports ?? {})
+
+  return (
+    <NodeBase
+      {...props}
+      drawerOptions={{
+        title: `Container - ${data.title}`,
+        description: data.description,
+        icon: data.icon,
+        nodeType: 'container',
+        children: (
+          <>
+            <div className="flex flex-col">
+              <span className="font-bold">Image:</span>
+              <span>{data.resource.image}</span>
+            </div>
+            <div className="flex flex-col">
+              <span className="font-bold">Status:</span>
+              <span>{data.resource.status}</span>
+              {data.resource.statusMessage && (
+                <pre className="whitespace-pre-wrap text-xs text-gray-500">
+                  {data.resource.statusMessage}
+                </pre>
+              )}
+            </div>
+            {ports.length > 0 && (
+              <div className="flex flex-col">
+                <span className="font-bold">Ports:</span>
+                {ports.map(([port, hostPort]) => (
+                  <span key={port}>
+                    {port} → localhost:{hostPort}
+                  </span>
+                ))}
+              </div>
+            )}
+          </>
+        ),
+      }}
+    />
+  )
+}
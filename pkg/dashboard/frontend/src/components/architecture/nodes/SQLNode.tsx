@@ -28,6 +28,11 @@ export const SQLNode: ComponentType<NodeProps<SQLNodeData>> = (props) => {
             <div className="flex flex-col">
               <span className="font-bold">Status:</span>
               <span>{data.resource.status}</span>
+              {data.resource.statusMessage && (
+                <pre className="whitespace-pre-wrap text-xs text-gray-500">
+                  {data.resource.statusMessage}
+                </pre>
+              )}
             </div>
             <div className="flex flex-col">
               <span className="font-bold">Connection String:</span>
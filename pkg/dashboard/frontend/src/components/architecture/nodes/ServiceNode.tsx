@@ -1,11 +1,13 @@
 import { type ComponentType } from 'react'
 
 import type { Edge, NodeProps } from 'reactflow'
+import type { ServiceHealth } from '@/types'
 import NodeBase, { type NodeBaseData } from './NodeBase'
 import { Button } from '@/components/ui/button'
 
 type ServiceData = {
   filePath: string
+  health?: ServiceHealth[]
 }
 
 export interface ServiceNodeData extends NodeBaseData<ServiceData> {
@@ -18,6 +20,7 @@ export const ServiceNode: ComponentType<NodeProps<ServiceNodeData>> = (
   const { data } = props
 
   const Icon = data.icon
+  const health = data.resource.health ?? []
 
   return (
     <NodeBase
@@ -27,6 +30,24 @@ export const ServiceNode: ComponentType<NodeProps<ServiceNodeData>> = (
         icon: Icon,
         nodeType: 'service',
         description: data.description,
+        children: health.length ? (
+          <div className="flex flex-col">
+            <span className="font-bold">Health:</span>
+            {health.map((replica, i) => (
+              <div key={i} className="flex flex-col">
+                <span>
+                  {health.length > 1 && `Replica ${i}: `}
+                  {replica.status}
+                </span>
+                {replica.message && (
+                  <pre className="whitespace-pre-wrap text-xs text-gray-500">
+                    {replica.message}
+                  </pre>
+                )}
+              </div>
+            ))}
+          </div>
+        ) : undefined,
         footerChildren: (
           <Button asChild>
             <a href={`vscode://file/${data.resource.filePath}`}>
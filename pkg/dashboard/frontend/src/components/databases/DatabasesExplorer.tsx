@@ -25,6 +25,9 @@ import QueryResults from './QueryResults'
 import { useSqlMeta } from '@/lib/hooks/use-sql-meta'
 import SectionCard from '../shared/SectionCard'
 import NotFoundAlert from '../shared/NotFoundAlert'
+import { Checkbox } from '../ui/checkbox'
+import { Input } from '../ui/input'
+import { Label } from '../ui/label'
 
 interface QueryHistoryItem {
   query: string
@@ -38,6 +41,20 @@ interface QueryHistory {
 
 const DATABASES_STORAGE_KEY = 'nitric-local-dash-database'
 
+const PAGE_SIZE = 100
+
+const sqlRequestHeaders = () =>
+  fieldRowArrToHeaders([
+    {
+      key: 'Accept',
+      value: '*/*',
+    },
+    {
+      key: 'User-Agent',
+      value: 'Nitric Client (https://www.nitric.io)',
+    },
+  ])
+
 const getStorageHistory = (): QueryHistory | null => {
   try {
     const storage = localStorage.getItem(DATABASES_STORAGE_KEY)
@@ -60,6 +77,11 @@ const DatabasesExplorer: React.FC = () => {
   const [migrationLoading, setMigrationLoading] = useState(false)
 
   const [response, setResponse] = useState<string>()
+  const [offset, setOffset] = useState(0)
+  const [hasMore, setHasMore] = useState(false)
+  // statements that write are rolled back unless the user opts to commit them
+  const [rollback, setRollback] = useState(true)
+  const [params, setParams] = useState('')
 
   const [selectedDb, setSelectedDb] = useState<SQLDatabase>()
 
@@ -107,17 +129,37 @@ const DatabasesExplorer: React.FC = () => {
   // clean up state when selectedDb changes
   useEffect(() => {
     setResponse(undefined)
+    setOffset(0)
+    setHasMore(false)
+    setParams('')
     refreshTables()
 
     setSql('')
   }, [selectedDb])
 
-  const handleRun = async (
-    e: React.MouseEvent<HTMLButtonElement, MouseEvent>,
-  ) => {
+  // parses the bound parameters, a JSON array of values for $1, $2, ...
+  const parseParams = (): any[] | undefined => {
+    if (!params.trim()) return []
+
+    try {
+      const parsed = JSON.parse(params)
+
+      if (Array.isArray(parsed)) return parsed
+    } catch {
+      // reported below
+    }
+
+    setResponse('Error: Parameters should be a JSON array, e.g. [1, "name"]')
+
+    return undefined
+  }
+
+  const runQuery = async (options: {
+    offset?: number
+    explain?: boolean
+  }) => {
     if (!selectedDb) return
     setCallLoading(true)
-    e.preventDefault()
 
     if (!sql) {
       setResponse('Error: Query should not be empty')
@@ -125,23 +167,30 @@ const DatabasesExplorer: React.FC = () => {
       return
     }
 
+    const queryParams = parseParams()
+
+    if (!queryParams) {
+      setCallLoading(false)
+      return
+    }
+
+    const paging = options.offset !== undefined
+    const pageOffset = options.offset ?? 0
+
     const url = `http://${getHost()}/api/sql`
     const requestOptions: RequestInit = {
       method: 'POST',
       body: JSON.stringify({
         query: sql,
         connectionString: selectedDb.connectionString,
+        params: queryParams,
+        limit: PAGE_SIZE,
+        offset: pageOffset,
+        // pages re-run the query, so they run read only to never repeat its writes
+        transaction: paging ? 'read_only' : rollback ? 'rollback' : 'commit',
+        explain: options.explain,
       }),
-      headers: fieldRowArrToHeaders([
-        {
-          key: 'Accept',
-          value: '*/*',
-        },
-        {
-          key: 'User-Agent',
-          value: 'Nitric Client (https://www.nitric.io)',
-        },
-      ]),
+      headers: sqlRequestHeaders(),
     }
 
     const startTime = window.performance.now()
@@ -150,12 +199,60 @@ const DatabasesExplorer: React.FC = () => {
     const callResponse = await generateResponse(res, startTime)
     setResponse(callResponse.data)
 
+    if (res.ok && !options.explain) {
+      setOffset(pageOffset)
+      setHasMore(Boolean(JSON.parse(callResponse.data).hasMore))
+    } else {
+      setOffset(0)
+      setHasMore(false)
+    }
+
     // refresh tables in case of DDL changes
     refreshTables()
 
     setTimeout(() => setCallLoading(false), 300)
   }
 
+  const handleRun = async (
+    e: React.MouseEvent<HTMLButtonElement, MouseEvent>,
+  ) => {
+    e.preventDefault()
+
+    await runQuery({})
+  }
+
+  const handleExport = async (format: 'csv' | 'json') => {
+    if (!selectedDb || !sql) return
+
+    const queryParams = parseParams()
+
+    if (!queryParams) return
+
+    const res = await fetch(`http://${getHost()}/api/sql`, {
+      method: 'POST',
+      body: JSON.stringify({
+        query: sql,
+        connectionString: selectedDb.connectionString,
+        params: queryParams,
+        // exports re-run the query, so they run read only to never repeat its writes
+        transaction: 'read_only',
+        format,
+      }),
+      headers: sqlRequestHeaders(),
+    })
+
+    if (!res.ok) {
+      toast.error('Export failed: ' + (await res.text()))
+      return
+    }
+
+    const link = document.createElement('a')
+    link.href = URL.createObjectURL(await res.blob())
+    link.download = `${selectedDb.name}-results.${format}`
+    link.click()
+    URL.revokeObjectURL(link.href)
+  }
+
   const handleMigrate = async () => {
     if (!selectedDb) return
 
@@ -375,23 +472,98 @@ const DatabasesExplorer: React.FC = () => {
                     }}
                   />
 
+                  <div className="mt-4 flex flex-wrap items-center gap-4">
+                    <div className="flex flex-grow items-center gap-x-2">
+                      <Label htmlFor="query-params" className="shrink-0">
+                        Parameters
+                      </Label>
+                      <Input
+                        id="query-params"
+                        data-testid="query-params"
+                        className="font-mono"
+                        placeholder='[1, "name"]'
+                        value={params}
+                        onChange={(e) => setParams(e.target.value)}
+                      />
+                    </div>
+                    <div className="flex items-center gap-x-2">
+                      <Checkbox
+                        id="query-rollback"
+                        data-testid="query-rollback"
+                        checked={rollback}
+                        onCheckedChange={(checked) =>
+                          setRollback(checked === true)
+                        }
+                      />
+                      <Label htmlFor="query-rollback">Roll back changes</Label>
+                    </div>
+                  </div>
+
                   <div className="mt-4 flex w-full items-center justify-between">
                     <div className="flex items-center gap-x-2">
                       <h3 className="text-xl font-semibold leading-6 text-gray-900">
                         Results
                       </h3>
                     </div>
-                    <Button
-                      size="lg"
-                      data-testid={`run-btn`}
-                      onClick={handleRun}
-                    >
-                      Run
-                    </Button>
+                    <div className="flex items-center gap-x-2">
+                      <Button
+                        variant="outline"
+                        data-testid="export-csv-btn"
+                        onClick={() => handleExport('csv')}
+                      >
+                        Export CSV
+                      </Button>
+                      <Button
+                        variant="outline"
+                        data-testid="export-json-btn"
+                        onClick={() => handleExport('json')}
+                      >
+                        Export JSON
+                      </Button>
+                      <Button
+                        variant="outline"
+                        data-testid="explain-btn"
+                        onClick={() => runQuery({ explain: true })}
+                      >
+                        Explain
+                      </Button>
+                      <Button
+                        size="lg"
+                        data-testid={`run-btn`}
+                        onClick={handleRun}
+                      >
+                        Run
+                      </Button>
+                    </div>
                   </div>
                   <div className="mt-4">
                     <QueryResults response={response} loading={callLoading} />
                   </div>
+                  {(offset > 0 || hasMore) && (
+                    <div className="mt-4 flex items-center justify-end gap-x-2">
+                      <span className="text-sm text-muted-foreground">
+                        Page {offset / PAGE_SIZE + 1}
+                      </span>
+                      <Button
+                        variant="outline"
+                        data-testid="prev-page-btn"
+                        disabled={offset === 0 || callLoading}
+                        onClick={() =>
+                          runQuery({ offset: Math.max(offset - PAGE_SIZE, 0) })
+                        }
+                      >
+                        Previous
+                      </Button>
+                      <Button
+                        variant="outline"
+                        data-testid="next-page-btn"
+                        disabled={!hasMore || callLoading}
+                        onClick={() => runQuery({ offset: offset + PAGE_SIZE })}
+                      >
+                        Next
+                      </Button>
+                    </div>
+                  )}
                 </div>
               </SectionCard>
             </div>
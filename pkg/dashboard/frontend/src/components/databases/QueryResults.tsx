@@ -9,18 +9,19 @@ import {
 } from '../ui/context-menu'
 import { copyToClipboard } from '@/lib/utils/copy-to-clipboard'
 import { cn } from '@/lib/utils'
+import type {
+  SqlExplainResult,
+  SqlQueryResult,
+} from '@/lib/hooks/use-sql-meta'
 
 interface QueryResultsProps {
   response?: string
   loading?: boolean
 }
 
-interface Result {
-  table_name: string
-  [key: string]: any
-}
-
-const parse = (value: string): Result[] | string => {
+const parse = (
+  value: string,
+): SqlQueryResult | SqlExplainResult | string => {
   try {
     return JSON.parse(value)
   } catch (e) {
@@ -68,22 +69,39 @@ const QueryResults: React.FC<QueryResultsProps> = ({ response, loading }) => {
     )
   }
 
-  const rows = parse(response)
+  const result = parse(response)
 
   // if the data is a string after parse, we can assume it's a error response
-  if (typeof rows === 'string') {
+  if (typeof result === 'string') {
+    return (
+      <Container>
+        <p className="m-0 border-0 px-6 py-4 text-sm">{result}</p>
+      </Container>
+    )
+  }
+
+  if ('plan' in result) {
     return (
       <Container>
-        <p className="m-0 border-0 px-6 py-4 text-sm">{rows}</p>
+        <pre
+          data-testid="query-plan"
+          className="m-0 overflow-x-auto border-0 px-6 py-4 text-sm"
+        >
+          {result.text}
+        </pre>
       </Container>
     )
   }
 
+  const { rows, rolledBack } = result
+
   if (rows.length <= 0) {
     return (
       <Container>
         <p className="m-0 border-0 px-6 py-4 text-sm">
           Success. No rows returned
+          {result.rowsAffected > 0 && `, ${result.rowsAffected} rows affected`}
+          {rolledBack && ' (rolled back)'}
         </p>
       </Container>
     )
@@ -138,7 +156,7 @@ const QueryResults: React.FC<QueryResultsProps> = ({ response, loading }) => {
     )
   }
 
-  const columns: CalculatedColumn<any>[] = Object.keys(rows?.[0] ?? []).map(
+  const columns: CalculatedColumn<any>[] = result.columns.map(
     (key, idx) => {
       const columnWidth = Math.max(
         Math.min(
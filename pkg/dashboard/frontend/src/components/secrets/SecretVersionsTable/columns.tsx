@@ -61,6 +61,15 @@ export const columns: ColumnDef<SecretVersion>[] = [
               Latest
             </Badge>
           )}
+          {secretVersion.state && secretVersion.state !== 'enabled' && (
+            <Badge
+              className="ml-2 capitalize"
+              variant="destructive"
+              data-testid={`data-table-${row.id}-state-badge`}
+            >
+              {secretVersion.state}
+            </Badge>
+          )}
         </div>
       )
     },
@@ -0,0 +1,114 @@
+import { useState } from 'react'
+import toast from 'react-hot-toast'
+import { format } from 'date-fns/format'
+import { XMarkIcon } from '@heroicons/react/24/outline'
+import type { WebSocketInfoData } from '../../types'
+import { getHost } from '../../lib/utils'
+import { Button } from '../ui/button'
+import { Input } from '../ui/input'
+import SectionCard from '../shared/SectionCard'
+
+interface Props {
+  socket: string
+  info?: WebSocketInfoData
+}
+
+const WSConnections: React.FC<Props> = ({ socket, info }) => {
+  const [broadcastPayload, setBroadcastPayload] = useState('')
+
+  const closeConnection = async (connectionId: string) => {
+    await toast.promise(
+      fetch(
+        `http://${getHost()}/api/ws-connections?socket=${encodeURIComponent(
+          socket,
+        )}&connectionId=${encodeURIComponent(connectionId)}`,
+        {
+          method: 'DELETE',
+        },
+      ),
+      {
+        error: 'Error closing connection',
+        loading: 'Closing connection',
+        success: 'Connection closed',
+      },
+    )
+  }
+
+  const broadcast = async () => {
+    await toast.promise(
+      fetch(
+        `http://${getHost()}/api/ws-broadcast?socket=${encodeURIComponent(
+          socket,
+        )}`,
+        {
+          method: 'POST',
+          body: broadcastPayload,
+        },
+      ),
+      {
+        error: 'Error broadcasting message',
+        loading: 'Broadcasting message',
+        success: 'Message broadcast',
+      },
+    )
+  }
+
+  const connections = info?.connections ?? []
+
+  return (
+    <div className="space-y-10">
+      <SectionCard className="mt-4" title="Broadcast">
+        <div className="flex gap-2">
+          <Input
+            data-testid="broadcast-input"
+            placeholder="Message to send to every connection"
+            value={broadcastPayload}
+            onChange={(evt) => setBroadcastPayload(evt.target.value)}
+          />
+          <Button
+            data-testid="broadcast-btn"
+            disabled={!broadcastPayload || !connections.length}
+            onClick={broadcast}
+          >
+            Broadcast
+          </Button>
+        </div>
+      </SectionCard>
+      <SectionCard title="Live Connections">
+        {connections.length ? (
+          <ul className="divide-y text-sm">
+            {connections.map((connection) => (
+              <li
+                key={connection.connectionId}
+                className="flex items-center gap-4 py-2"
+              >
+                <span className="font-mono">{connection.connectionId}</span>
+                <span className="truncate text-gray-500">
+                  {Object.entries(connection.queryParams ?? {})
+                    .map(([key, values]) => `${key}=${values.join(',')}`)
+                    .join('&')}
+                </span>
+                <span className="ml-auto">
+                  {format(new Date(connection.connectedAt), 'HH:mm:ss')}
+                </span>
+                <Button
+                  variant="outline"
+                  size="sm"
+                  data-testid={`close-connection-${connection.connectionId}`}
+                  onClick={() => closeConnection(connection.connectionId)}
+                >
+                  <XMarkIcon className="mr-1 h-4 w-4" />
+                  Close
+                </Button>
+              </li>
+            ))}
+          </ul>
+        ) : (
+          <span className="text-lg text-gray-500">No live connections.</span>
+        )}
+      </SectionCard>
+    </div>
+  )
+}
+
+export default WSConnections
@@ -6,6 +6,8 @@ import { formatJSON, generatePath, getHost } from '../../lib/utils'
 import { useWebSocket } from '../../lib/hooks/use-web-socket'
 import AppLayout from '../layout/AppLayout'
 import WSTreeView from './WSTreeView'
+import WSConnections from './WSConnections'
+import WSSessions from './WSSessions'
 import { copyToClipboard } from '../../lib/utils/copy-to-clipboard'
 import toast from 'react-hot-toast'
 import {
@@ -420,6 +422,18 @@ const WSExplorer = () => {
                   >
                     Send Messages
                   </TabsTrigger>
+                  <TabsTrigger
+                    value="connections"
+                    data-testid="connections-tab-trigger"
+                  >
+                    Connections
+                  </TabsTrigger>
+                  <TabsTrigger
+                    value="sessions"
+                    data-testid="sessions-tab-trigger"
+                  >
+                    Sessions
+                  </TabsTrigger>
                 </TabsList>
                 <TabsContent value="monitor">
                   <SectionCard
@@ -752,6 +766,18 @@ const WSExplorer = () => {
                     </div>
                   </SectionCard>
                 </TabsContent>
+                <TabsContent value="connections">
+                  <WSConnections
+                    socket={selectedWebsocket.name}
+                    info={wsInfo}
+                  />
+                </TabsContent>
+                <TabsContent value="sessions">
+                  <WSSessions
+                    socket={selectedWebsocket.name}
+                    recorded={[...messages].reverse()}
+                  />
+                </TabsContent>
               </Tabs>
             </div>
           </div>
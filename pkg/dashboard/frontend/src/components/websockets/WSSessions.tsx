@@ -0,0 +1,168 @@
+import { useState } from 'react'
+import toast from 'react-hot-toast'
+import useSWR from 'swr'
+import type { WebSocketSession, WebSocketSessionResult } from '../../types'
+import { getHost } from '../../lib/utils'
+import { Button } from '../ui/button'
+import { Input } from '../ui/input'
+import SectionCard from '../shared/SectionCard'
+import { Badge } from '../ui/badge'
+
+interface Props {
+  socket: string
+  // messages recorded by the explorer, oldest first, used to save new sessions
+  recorded: { data: string; type: string }[]
+}
+
+const fetcher = (url: string) => fetch(url).then((res) => res.json())
+
+const WSSessions: React.FC<Props> = ({ socket, recorded }) => {
+  const [name, setName] = useState('')
+  const [results, setResults] = useState<
+    Record<string, WebSocketSessionResult>
+  >({})
+
+  const sessionsUrl = `http://${getHost()}/api/ws-sessions`
+
+  const { data: sessions, mutate } = useSWR<WebSocketSession[]>(
+    sessionsUrl,
+    fetcher,
+  )
+
+  const socketSessions = (sessions ?? []).filter((s) => s.socket === socket)
+
+  const saveSession = async () => {
+    const session: WebSocketSession = {
+      name,
+      socket,
+      steps: recorded
+        .filter(({ type }) => type === 'message-out' || type === 'message-in')
+        .map(({ data, type }) =>
+          type === 'message-out' ? { send: data } : { expect: data },
+        ),
+    }
+
+    await toast.promise(
+      fetch(sessionsUrl, {
+        method: 'POST',
+        body: JSON.stringify(session),
+      }).then((res) => {
+        if (!res.ok) throw new Error()
+      }),
+      {
+        error: 'Error saving session',
+        loading: 'Saving session',
+        success: 'Session saved',
+      },
+    )
+
+    setName('')
+    mutate()
+  }
+
+  const runSession = async (sessionName: string) => {
+    const result = await toast.promise(
+      fetch(
+        `${sessionsUrl}?action=run&name=${encodeURIComponent(sessionName)}`,
+        { method: 'POST' },
+      ).then((res) => {
+        if (!res.ok) throw new Error()
+        return res.json() as Promise<WebSocketSessionResult>
+      }),
+      {
+        error: 'Error running session',
+        loading: 'Running session',
+        success: 'Session complete',
+      },
+    )
+
+    setResults((prev) => ({ ...prev, [sessionName]: result }))
+  }
+
+  const deleteSession = async (sessionName: string) => {
+    await fetch(`${sessionsUrl}?name=${encodeURIComponent(sessionName)}`, {
+      method: 'DELETE',
+    })
+
+    mutate()
+  }
+
+  return (
+    <div className="space-y-10">
+      <SectionCard
+        className="mt-4"
+        title="Save Session"
+        description="Save the messages sent and received in the Send Messages tab as a scripted session. Sessions can be re-run from the CLI with nitric websocket run."
+      >
+        <div className="flex gap-2">
+          <Input
+            data-testid="session-name-input"
+            placeholder="Session name"
+            value={name}
+            onChange={(evt) => setName(evt.target.value)}
+          />
+          <Button
+            data-testid="save-session-btn"
+            disabled={!name || !recorded.length}
+            onClick={saveSession}
+          >
+            Save
+          </Button>
+        </div>
+      </SectionCard>
+      <SectionCard title="Sessions">
+        {socketSessions.length ? (
+          <ul className="divide-y text-sm">
+            {socketSessions.map((session) => {
+              const result = results[session.name]
+
+              return (
+                <li key={session.name} className="space-y-2 py-2">
+                  <div className="flex items-center gap-4">
+                    <span className="font-semibold">{session.name}</span>
+                    <span className="text-gray-500">
+                      {session.steps.length} steps
+                    </span>
+                    {result && (
+                      <Badge variant={result.passed ? 'success' : 'destructive'}>
+                        {result.passed ? 'Passed' : 'Failed'}
+                      </Badge>
+                    )}
+                    <Button
+                      className="ml-auto"
+                      size="sm"
+                      data-testid={`run-session-${session.name}`}
+                      onClick={() => runSession(session.name)}
+                    >
+                      Run
+                    </Button>
+                    <Button
+                      size="sm"
+                      variant="outline"
+                      onClick={() => deleteSession(session.name)}
+                    >
+                      Delete
+                    </Button>
+                  </div>
+                  {result?.steps
+                    .filter((step) => !step.passed)
+                    .map((step) => (
+                      <p key={step.step} className="text-red-600">
+                        {step.step}: {step.error}
+                      </p>
+                    ))}
+                </li>
+              )
+            })}
+          </ul>
+        ) : (
+          <span className="text-lg text-gray-500">
+            No sessions saved for this socket.
+          </span>
+        )}
+      </SectionCard>
+    </div>
+  )
+}
+
+export default WSSessions
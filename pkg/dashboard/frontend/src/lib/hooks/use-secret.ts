@@ -28,7 +28,7 @@ export const useSecret = (secretName?: string) => {
   const deleteSecretVersion = useCallback(
     async (sv: SecretVersion) => {
       return fetch(
-        `${SECRETS_API}?action=delete-secret&secret=${secretName}&version=${sv.version}&latest=${sv.latest}`,
+        `${SECRETS_API}?action=delete-secret&secret=${secretName}&version=${sv.version}`,
         {
           method: 'DELETE',
         },
@@ -2,6 +2,38 @@ import useSWR from 'swr'
 import { fetcher } from './fetcher'
 import { SQL_API, TABLE_QUERY } from '../constants'
 
+export interface SqlQueryResult<T = Record<string, any>> {
+  columns: string[]
+  rows: T[]
+  hasMore: boolean
+  rowsAffected: number
+  rolledBack: boolean
+}
+
+export interface SqlPlanNode {
+  nodeType: string
+  relation?: string
+  index?: string
+  startupCost: number
+  totalCost: number
+  planRows: number
+  actualTime: number
+  actualRows: number
+  actualLoops: number
+  filter?: string
+  indexCond?: string
+  hashCond?: string
+  joinFilter?: string
+  children: SqlPlanNode[]
+}
+
+export interface SqlExplainResult {
+  plan: SqlPlanNode
+  planningTime: number
+  executionTime: number
+  text: string
+}
+
 export interface SqlMetaResult {
   columns: {
     column_name: string
@@ -17,10 +49,11 @@ export interface SqlMetaResult {
 export const useSqlMeta = (connectionString?: string) => {
   const { data, mutate } = useSWR<SqlMetaResult[]>(
     connectionString ? SQL_API : null,
-    fetcher({
-      method: 'POST',
-      body: JSON.stringify({ query: TABLE_QUERY, connectionString }),
-    }),
+    (url: string) =>
+      fetcher({
+        method: 'POST',
+        body: JSON.stringify({ query: TABLE_QUERY, connectionString }),
+      })(url).then((result: SqlQueryResult<SqlMetaResult>) => result.rows),
   )
 
   return {
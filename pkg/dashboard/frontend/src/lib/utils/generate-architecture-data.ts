@@ -20,6 +20,7 @@ import {
   LockClosedIcon,
   CogIcon,
   WindowIcon,
+  CubeIcon,
 } from '@heroicons/react/24/outline'
 import {
   MarkerType,
@@ -54,6 +55,10 @@ import {
 } from '@/components/architecture/nodes/HttpProxyNode'
 import { getTopicSubscriptions } from './get-topic-subscriptions'
 import { QueueNode } from '@/components/architecture/nodes/QueueNode'
+import {
+  ContainerNode,
+  type ContainerNodeData,
+} from '@/components/architecture/nodes/ContainerNode'
 import { SQLNode } from '@/components/architecture/nodes/SQLNode'
 import { SiPostgresql } from 'react-icons/si'
 import { unique } from 'radash'
@@ -85,6 +90,7 @@ export const nodeTypes = {
   queue: QueueNode,
   secret: SecretNode,
   websites: WebsitesNode,
+  container: ContainerNode,
 }
 
 const createNode = <T>(
@@ -452,6 +458,17 @@ export function generateArchitectureData(data: WebSocketResponse): {
     nodes.push(node)
   })
 
+  data.containers.forEach((container) => {
+    const node = createNode<ContainerNodeData>(container, 'container', {
+      title: container.name,
+      resource: container,
+      icon: CubeIcon,
+      description: container.image,
+    })
+
+    nodes.push(node)
+  })
+
   // Generate nodes from buckets
   data.buckets.forEach((bucket) => {
     const bucketNotifications = getBucketNotifications(
@@ -637,6 +654,7 @@ export function generateArchitectureData(data: WebSocketResponse): {
         description: '',
         resource: {
           filePath: service.filePath,
+          health: service.health,
         },
         icon: CpuChipIcon,
         connectedEdges: [],
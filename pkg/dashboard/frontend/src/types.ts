@@ -28,7 +28,13 @@ export type KeyValue = BaseResource
 
 export interface SQLDatabase extends BaseResource {
   connectionString: string
-  status: 'starting' | 'active' | 'building migrations' | 'applying migrations'
+  status:
+    | 'starting'
+    | 'active'
+    | 'building migrations'
+    | 'applying migrations'
+    | 'error'
+  statusMessage?: string
   migrationsPath: string
 }
 
@@ -44,7 +50,14 @@ export interface Schedule extends BaseResource {
 
 export type Topic = BaseResource
 
-export type Service = BaseResource
+export interface ServiceHealth {
+  status: 'starting' | 'healthy' | 'unhealthy'
+  message?: string
+}
+
+export interface Service extends BaseResource {
+  health?: ServiceHealth[]
+}
 
 export type Batch = BaseResource
 
@@ -63,15 +76,47 @@ export interface WebSocket extends BaseResource {
   targets: Record<WebsocketEvent, string>
 }
 
+export interface WebSocketConnection {
+  connectionId: string
+  queryParams?: Record<string, string[]>
+  connectedAt: string
+}
+
 export interface WebSocketInfoData {
   connectionCount: number
+  connections?: WebSocketConnection[]
   messages: {
     data: string
     time: string
     connectionId: string
+    binary?: boolean
+    broadcast?: boolean
   }[]
 }
 
+export interface WebSocketSessionStep {
+  send?: string
+  expect?: string
+  wait?: string
+  binary?: boolean
+  match?: 'exact' | 'contains' | 'regex'
+  repeat?: number
+  timeout?: string
+}
+
+export interface WebSocketSession {
+  name: string
+  socket: string
+  query?: Record<string, string>
+  steps: WebSocketSessionStep[]
+}
+
+export interface WebSocketSessionResult {
+  session: string
+  passed: boolean
+  steps: { step: string; passed: boolean; error?: string }[]
+}
+
 export interface WebSocketsInfo {
   [socket: string]: WebSocketInfoData
 }
@@ -80,6 +125,13 @@ export type Bucket = BaseResource
 
 export type Queue = BaseResource
 
+export interface Container extends BaseResource {
+  image: string
+  status: 'starting' | 'running' | 'healthy' | 'unhealthy' | 'error'
+  statusMessage?: string
+  ports: Record<string, number>
+}
+
 export type Secret = BaseResource
 
 export interface SecretVersion {
@@ -87,6 +139,7 @@ export interface SecretVersion {
   value: string
   createdAt: string
   latest: boolean
+  state?: 'enabled' | 'disabled' | 'destroyed'
 }
 
 type ResourceType = 'bucket' | 'topic' | 'websocket' | 'kv' | 'secret' | 'queue'
@@ -128,6 +181,7 @@ export interface WebSocketResponse {
   websockets: WebSocket[]
   websites: Website[]
   queues: Queue[]
+  containers: Container[]
   policies: {
     [name: string]: Policy
   }
@@ -220,6 +274,7 @@ export type ApiHistoryItem = HistoryItem<{
   api: string
   request: RequestHistory
   response: APIResponse
+  violations?: string[]
 }>
 
 export interface RequestHistory {
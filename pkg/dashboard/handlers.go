@@ -23,6 +23,7 @@ import (
 	"fmt"
 	"io"
 	"log"
+	"math"
 	"net/http"
 	"net/url"
 	"regexp"
@@ -35,6 +36,7 @@ import (
 	"github.com/nitrictech/cli/pkg/cloud/apis"
 	"github.com/nitrictech/cli/pkg/cloud/batch"
 	"github.com/nitrictech/cli/pkg/cloud/schedules"
+	"github.com/nitrictech/cli/pkg/cloud/sql"
 	"github.com/nitrictech/cli/pkg/cloud/topics"
 	"github.com/nitrictech/cli/pkg/cloud/websockets"
 	base_http "github.com/nitrictech/nitric/cloud/common/runtime/gateway"
@@ -42,6 +44,7 @@ import (
 	resourcespb "github.com/nitrictech/nitric/core/pkg/proto/resources/v1"
 	secretspb "github.com/nitrictech/nitric/core/pkg/proto/secrets/v1"
 	storagepb "github.com/nitrictech/nitric/core/pkg/proto/storage/v1"
+	websocketspb "github.com/nitrictech/nitric/core/pkg/proto/websockets/v1"
 )
 
 func (d *Dashboard) handleStorage() func(http.ResponseWriter, *http.Request) {
@@ -264,8 +267,14 @@ func (d *Dashboard) createSqlQueryHandler() func(http.ResponseWriter, *http.Requ
 
 		// Parse the SQL query from the request body
 		var requestBody struct {
-			Query            string `json:"query"`
-			ConnectionString string `json:"connectionString"`
+			Query            string              `json:"query"`
+			ConnectionString string              `json:"connectionString"`
+			Params           []any               `json:"params"`
+			Limit            int                 `json:"limit"`
+			Offset           int                 `json:"offset"`
+			Transaction      sql.TransactionMode `json:"transaction"`
+			Explain          bool                `json:"explain"`
+			Format           string              `json:"format"`
 		}
 
 		err := json.NewDecoder(r.Body).Decode(&requestBody)
@@ -274,15 +283,63 @@ func (d *Dashboard) createSqlQueryHandler() func(http.ResponseWriter, *http.Requ
 			return
 		}
 
-		// Execute the SQL query
-		results, err := d.databaseService.Query(context.Background(), requestBody.ConnectionString, requestBody.Query)
-		if err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+		if requestBody.Limit < 0 || requestBody.Offset < 0 {
+			http.Error(w, "limit and offset can't be negative", http.StatusBadRequest)
+			return
+		}
+
+		if !requestBody.Transaction.Valid() {
+			http.Error(w, fmt.Sprintf("unknown transaction mode %q, expected one of %s", requestBody.Transaction, strings.Join(lo.Map(sql.TransactionModes, func(mode sql.TransactionMode, _ int) string { return string(mode) }), ", ")), http.StatusBadRequest)
 			return
 		}
 
+		params := queryParams(requestBody.Params)
+
+		var response any
+
+		if requestBody.Explain {
+			response, err = d.databaseService.Explain(context.Background(), requestBody.ConnectionString, requestBody.Query, params)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+		} else {
+			// Execute the SQL query
+			result, err := d.databaseService.Query(context.Background(), requestBody.ConnectionString, requestBody.Query, sql.QueryOptions{
+				Params:      params,
+				Limit:       requestBody.Limit,
+				Offset:      requestBody.Offset,
+				Transaction: requestBody.Transaction,
+			})
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+
+			switch requestBody.Format {
+			case "csv":
+				w.Header().Set("Content-Type", "text/csv")
+				w.Header().Set("Content-Disposition", `attachment; filename="results.csv"`)
+
+				if err := sql.WriteCSV(w, result); err != nil {
+					http.Error(w, err.Error(), http.StatusInternalServerError)
+				}
+
+				return
+			case "json":
+				w.Header().Set("Content-Disposition", `attachment; filename="results.json"`)
+
+				response = result.Rows
+			case "":
+				response = result
+			default:
+				http.Error(w, fmt.Sprintf("unsupported format %s, expected csv or json", requestBody.Format), http.StatusBadRequest)
+				return
+			}
+		}
+
 		// Write the results to the response
-		jsonResponse, err := json.Marshal(results)
+		jsonResponse, err := json.Marshal(response)
 		if err != nil {
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
@@ -299,6 +356,21 @@ func (d *Dashboard) createSqlQueryHandler() func(http.ResponseWriter, *http.Requ
 	}
 }
 
+// queryParams converts whole JSON numbers to integers, so they can be bound to integer columns
+func queryParams(params []any) []any {
+	converted := make([]any, len(params))
+
+	for i, param := range params {
+		if f, ok := param.(float64); ok && f == math.Trunc(f) && math.Abs(f) < math.MaxInt64 {
+			converted[i] = int64(f)
+		} else {
+			converted[i] = param
+		}
+	}
+
+	return converted
+}
+
 func (d *Dashboard) createApplySqlMigrationsHandler(fs afero.Fs, useBuilder bool) func(http.ResponseWriter, *http.Request) {
 	return func(w http.ResponseWriter, r *http.Request) {
 		// Set CORs headers
@@ -349,6 +421,89 @@ func (d *Dashboard) createApplySqlMigrationsHandler(fs afero.Fs, useBuilder bool
 	}
 }
 
+func (d *Dashboard) createSqlSchemaHandler() func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Set CORs headers
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+		w.Header().Set("Access-Control-Allow-Methods", "GET, OPTIONS")
+		w.Header().Set("Access-Control-Allow-Headers", "*")
+
+		if r.Method == "OPTIONS" {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+
+		databaseName := r.URL.Query().Get("database")
+		if databaseName == "" {
+			http.Error(w, "missing database param", http.StatusBadRequest)
+			return
+		}
+
+		if _, ok := d.databaseService.GetState()[databaseName]; !ok {
+			http.Error(w, "database not found", http.StatusBadRequest)
+			return
+		}
+
+		schema, err := d.databaseService.Schema(r.Context(), databaseName)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		jsonResponse, err := json.Marshal(schema)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+
+		handleResponseWriter(w, jsonResponse)
+	}
+}
+
+func (d *Dashboard) createSqlDriftHandler(fs afero.Fs, useBuilder bool) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Set CORs headers
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+		w.Header().Set("Access-Control-Allow-Methods", "GET, OPTIONS")
+		w.Header().Set("Access-Control-Allow-Headers", "*")
+
+		if r.Method == "OPTIONS" {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+
+		databaseName := r.URL.Query().Get("database")
+		if databaseName == "" {
+			http.Error(w, "missing database param", http.StatusBadRequest)
+			return
+		}
+
+		db, ok := d.databaseService.GetState()[databaseName]
+		if !ok {
+			http.Error(w, "database not found", http.StatusBadRequest)
+			return
+		}
+
+		drift, err := d.databaseService.SchemaDrift(r.Context(), fs, databaseName, db.ResourceRegister.Resource, useBuilder)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		jsonResponse, err := json.Marshal(drift)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+
+		handleResponseWriter(w, jsonResponse)
+	}
+}
+
 func (d *Dashboard) createSecretsHandler() func(http.ResponseWriter, *http.Request) {
 	return func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Access-Control-Allow-Origin", "*")
@@ -363,7 +518,6 @@ func (d *Dashboard) createSecretsHandler() func(http.ResponseWriter, *http.Reque
 		secretName := r.URL.Query().Get("secret")
 		action := r.URL.Query().Get("action")
 		version := r.URL.Query().Get("version")
-		latest := r.URL.Query().Get("latest")
 
 		if secretName == "" {
 			http.Error(w, "missing secret param", http.StatusBadRequest)
@@ -428,7 +582,7 @@ func (d *Dashboard) createSecretsHandler() func(http.ResponseWriter, *http.Reque
 				return
 			}
 
-			err := d.secretService.Delete(context.Background(), secretName, version, latest == "true")
+			err := d.secretService.Delete(context.Background(), secretName, version)
 			if err != nil {
 				http.Error(w, err.Error(), http.StatusInternalServerError)
 				return
@@ -513,6 +667,98 @@ func (d *Dashboard) handleWebsocketMessagesClear() func(http.ResponseWriter, *ht
 	}
 }
 
+func (d *Dashboard) handleWebsocketConnections() func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+		w.Header().Set("Access-Control-Allow-Methods", "GET, DELETE, OPTIONS")
+		w.Header().Set("Access-Control-Allow-Headers", "*")
+
+		if r.Method == "OPTIONS" {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+
+		socketName := r.URL.Query().Get("socket")
+
+		if socketName == "" {
+			http.Error(w, "missing socket param", http.StatusBadRequest)
+			return
+		}
+
+		switch r.Method {
+		case "GET":
+			jsonResponse, err := json.Marshal(d.websocketService.GetConnections(socketName))
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+
+			w.Header().Set("Content-Type", "application/json")
+
+			handleResponseWriter(w, jsonResponse)
+		case "DELETE":
+			connectionId := r.URL.Query().Get("connectionId")
+
+			if connectionId == "" {
+				http.Error(w, "missing connectionId param", http.StatusBadRequest)
+				return
+			}
+
+			_, err := d.websocketService.CloseConnection(context.Background(), &websocketspb.WebsocketCloseConnectionRequest{
+				SocketName:   socketName,
+				ConnectionId: connectionId,
+			})
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusNotFound)
+				return
+			}
+
+			w.WriteHeader(http.StatusOK)
+		default:
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		}
+	}
+}
+
+func (d *Dashboard) handleWebsocketBroadcast() func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+		w.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS")
+		w.Header().Set("Access-Control-Allow-Headers", "*")
+
+		if r.Method == "OPTIONS" {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+
+		if r.Method != "POST" {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		socketName := r.URL.Query().Get("socket")
+
+		if socketName == "" {
+			http.Error(w, "missing socket param", http.StatusBadRequest)
+			return
+		}
+
+		data, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		err = d.websocketService.Broadcast(socketName, data)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+	}
+}
+
 func (d *Dashboard) handleApiHistory(state apis.ApiRequestState) {
 	var queryParams []Param
 
@@ -545,6 +791,7 @@ func (d *Dashboard) handleApiHistory(state apis.ApiRequestState) {
 				Data:   state.HttpResp.GetBody(),
 				Size:   len(state.HttpResp.GetBody()),
 			},
+			Violations: state.Violations,
 		},
 	})
 	if err != nil {
@@ -560,6 +807,7 @@ func (d *Dashboard) handleWebsocketEvents(action websockets.WebsocketAction[webs
 	switch e := action.Event.(type) {
 	case websockets.WebsocketInfo:
 		d.websocketsInfo[action.Name].ConnectionCount = e.ConnectionCount
+		d.websocketsInfo[action.Name].Connections = e.Connections
 	case websockets.WebsocketMessage:
 		d.websocketsInfo[action.Name].Messages = append([]websockets.WebsocketMessage{e}, d.websocketsInfo[action.Name].Messages...)
 	}
@@ -72,9 +72,10 @@ type ScheduleHistoryItem struct {
 }
 
 type ApiHistoryItem struct {
-	Api      string           `json:"api"`
-	Request  *RequestHistory  `json:"request"`
-	Response *ResponseHistory `json:"response"`
+	Api        string           `json:"api"`
+	Request    *RequestHistory  `json:"request"`
+	Response   *ResponseHistory `json:"response"`
+	Violations []string         `json:"violations,omitempty"`
 }
 
 type Param struct {
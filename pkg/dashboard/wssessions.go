@@ -0,0 +1,250 @@
+// Copyright Nitric Pty Ltd.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dashboard
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/spf13/afero"
+
+	"github.com/nitrictech/cli/pkg/wssession"
+)
+
+type WebsocketClientResponse struct {
+	Connected bool                `json:"connected"`
+	Messages  []wssession.Message `json:"messages"`
+}
+
+func (d *Dashboard) websocketUrl(socketName string) (string, error) {
+	address := d.gatewayService.GetWebsocketAddress(socketName)
+	if address == "" {
+		return "", fmt.Errorf("websocket %s is not running", socketName)
+	}
+
+	return fmt.Sprintf("ws://%s", address), nil
+}
+
+func writeJson(w http.ResponseWriter, v any) {
+	jsonResponse, err := json.Marshal(v)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	handleResponseWriter(w, jsonResponse)
+}
+
+// handleWebsocketClient manages a test client connection per socket, opened from the dashboard backend
+func (d *Dashboard) handleWebsocketClient() func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, DELETE, OPTIONS")
+		w.Header().Set("Access-Control-Allow-Headers", "*")
+
+		if r.Method == "OPTIONS" {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+
+		socketName := r.URL.Query().Get("socket")
+
+		if socketName == "" {
+			http.Error(w, "missing socket param", http.StatusBadRequest)
+			return
+		}
+
+		d.wsClientsLock.Lock()
+		defer d.wsClientsLock.Unlock()
+
+		client := d.wsClients[socketName]
+
+		switch r.Method {
+		case "GET":
+			resp := WebsocketClientResponse{
+				Connected: client != nil,
+				Messages:  []wssession.Message{},
+			}
+
+			if client != nil {
+				resp.Messages = client.Messages()
+			}
+
+			writeJson(w, resp)
+		case "POST":
+			action := r.URL.Query().Get("action")
+
+			switch action {
+			case "connect":
+				if client != nil {
+					http.Error(w, "client is already connected", http.StatusConflict)
+					return
+				}
+
+				query := map[string]string{}
+
+				if r.ContentLength > 0 {
+					if err := json.NewDecoder(r.Body).Decode(&query); err != nil {
+						http.Error(w, err.Error(), http.StatusBadRequest)
+						return
+					}
+				}
+
+				address, err := d.websocketUrl(socketName)
+				if err != nil {
+					http.Error(w, err.Error(), http.StatusNotFound)
+					return
+				}
+
+				client, err := wssession.Dial(address, query)
+				if err != nil {
+					http.Error(w, err.Error(), http.StatusBadGateway)
+					return
+				}
+
+				d.wsClients[socketName] = client
+
+				w.WriteHeader(http.StatusOK)
+			case "send":
+				if client == nil {
+					http.Error(w, "client is not connected", http.StatusNotFound)
+					return
+				}
+
+				data, err := io.ReadAll(r.Body)
+				if err != nil {
+					http.Error(w, err.Error(), http.StatusBadRequest)
+					return
+				}
+
+				binary := r.URL.Query().Get("binary") == "true"
+
+				if binary {
+					data, err = base64.StdEncoding.DecodeString(string(data))
+					if err != nil {
+						http.Error(w, "binary messages must be base64 encoded", http.StatusBadRequest)
+						return
+					}
+				}
+
+				err = client.Send(data, binary)
+				if err != nil {
+					http.Error(w, err.Error(), http.StatusInternalServerError)
+					return
+				}
+
+				w.WriteHeader(http.StatusOK)
+			default:
+				http.Error(w, "action must be connect or send", http.StatusBadRequest)
+			}
+		case "DELETE":
+			if client == nil {
+				http.Error(w, "client is not connected", http.StatusNotFound)
+				return
+			}
+
+			delete(d.wsClients, socketName)
+
+			err := client.Close()
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+
+			w.WriteHeader(http.StatusOK)
+		default:
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		}
+	}
+}
+
+// handleWebsocketSessions lists, saves, deletes and runs the scripted websocket sessions of the project
+func (d *Dashboard) handleWebsocketSessions(fs afero.Fs) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, DELETE, OPTIONS")
+		w.Header().Set("Access-Control-Allow-Headers", "*")
+
+		if r.Method == "OPTIONS" {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+
+		switch r.Method {
+		case "GET":
+			sessions, err := wssession.List(fs, d.project.Directory)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+
+			writeJson(w, sessions)
+		case "POST":
+			if r.URL.Query().Get("action") == "run" {
+				session, err := wssession.Load(fs, d.project.Directory, r.URL.Query().Get("name"))
+				if err != nil {
+					http.Error(w, err.Error(), http.StatusBadRequest)
+					return
+				}
+
+				address, err := d.websocketUrl(session.Socket)
+				if err != nil {
+					http.Error(w, err.Error(), http.StatusNotFound)
+					return
+				}
+
+				result, err := wssession.Run(address, session)
+				if err != nil {
+					http.Error(w, err.Error(), http.StatusBadGateway)
+					return
+				}
+
+				writeJson(w, result)
+
+				return
+			}
+
+			session := &wssession.Session{}
+
+			if err := json.NewDecoder(r.Body).Decode(session); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+
+			if err := wssession.Save(fs, d.project.Directory, session); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+
+			w.WriteHeader(http.StatusOK)
+		case "DELETE":
+			if err := wssession.Delete(fs, d.project.Directory, r.URL.Query().Get("name")); err != nil {
+				http.Error(w, err.Error(), http.StatusNotFound)
+				return
+			}
+
+			w.WriteHeader(http.StatusOK)
+		default:
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		}
+	}
+}
@@ -17,12 +17,27 @@
 package env
 
 import (
+	"fmt"
 	"os"
+	"sort"
+	"strings"
 
 	"github.com/joho/godotenv"
 )
 
-var defaultEnv = ".env"
+var (
+	defaultEnv = ".env"
+	localEnv   = ".env.local"
+)
+
+// SecretRefPrefix marks env values that reference a secret in the local secret store, e.g. secret://api-key or secret://api-key/<version>
+const SecretRefPrefix = "secret://"
+
+// Variable is a resolved env variable and the env file it was last set in
+type Variable struct {
+	Value  string `json:"value"`
+	Source string `json:"source"`
+}
 
 func ReadEnv(filePath string) (map[string]string, error) {
 	file, err := os.OpenFile(filePath, os.O_RDONLY, 0o666)
@@ -33,31 +48,140 @@ func ReadEnv(filePath string) (map[string]string, error) {
 	return godotenv.Parse(file)
 }
 
-func ReadLocalEnv(additionalFilePaths ...string) (map[string]string, error) {
-	envVariables, err := ReadEnv(defaultEnv)
-	if err != nil && !os.IsNotExist(err) {
-		return nil, err
-	}
+// EnvFiles returns the env files in the order they are applied, .env, .env.local, .env.<stack> when a stack is provided, then the additional files
+func EnvFiles(stackName string, additionalFilePaths ...string) []string {
+	files := []string{defaultEnv, localEnv}
 
-	if envVariables == nil {
-		envVariables = map[string]string{}
+	if stackName != "" {
+		files = append(files, fmt.Sprintf("%s.%s", defaultEnv, stackName))
 	}
 
-	for _, filePath := range additionalFilePaths {
-		additionalEnvVariables, err := ReadEnv(filePath)
+	return append(files, additionalFilePaths...)
+}
+
+// ReadLayeredEnv reads the layered env files of EnvFiles. Variables in later files override earlier ones and
+// can reference variables from earlier files with ${NAME}. The default files are optional, the additional files must exist.
+func ReadLayeredEnv(stackName string, additionalFilePaths ...string) (map[string]Variable, error) {
+	files := EnvFiles(stackName, additionalFilePaths...)
+	defaults := len(files) - len(additionalFilePaths)
+
+	variables := map[string]Variable{}
+
+	// files are parsed as one document so each file can interpolate the variables of the files before it
+	combined := []byte{}
+
+	for i, filePath := range files {
+		contents, err := os.ReadFile(filePath)
 		if err != nil {
+			if os.IsNotExist(err) && i < defaults {
+				continue
+			}
+
 			return nil, err
 		}
 
-		for key, value := range additionalEnvVariables {
-			envVariables[key] = value
+		combined = append(append(combined, contents...), '\n')
+
+		values, err := godotenv.UnmarshalBytes(combined)
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse env file %s: %w", filePath, err)
 		}
+
+		fileValues, err := godotenv.UnmarshalBytes(contents)
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse env file %s: %w", filePath, err)
+		}
+
+		for key := range fileValues {
+			variables[key] = Variable{Source: filePath}
+		}
+
+		for key, value := range values {
+			variables[key] = Variable{Value: value, Source: variables[key].Source}
+		}
+	}
+
+	return variables, nil
+}
+
+// ReadStackEnv returns the env variables for a stack, see ReadLayeredEnv
+func ReadStackEnv(stackName string, additionalFilePaths ...string) (map[string]string, error) {
+	variables, err := ReadLayeredEnv(stackName, additionalFilePaths...)
+	if err != nil {
+		return nil, err
+	}
+
+	envVariables := make(map[string]string, len(variables))
+	for key, variable := range variables {
+		envVariables[key] = variable.Value
 	}
 
 	return envVariables, nil
 }
 
+// ReadLocalEnv returns the env variables for running locally, see ReadLayeredEnv
+func ReadLocalEnv(additionalFilePaths ...string) (map[string]string, error) {
+	return ReadStackEnv("", additionalFilePaths...)
+}
+
 func LoadLocalEnv(additionalFilePaths ...string) error {
 	paths := append(additionalFilePaths, defaultEnv)
 	return godotenv.Load(paths...)
 }
+
+// SecretResolver returns the value of a version of a secret, version is latest unless the reference includes a version
+type SecretResolver func(name string, version string) (string, error)
+
+// ParseSecretRef returns the secret name and version of a secret://name[/version] reference
+func ParseSecretRef(value string) (name string, version string, ok bool) {
+	ref, ok := strings.CutPrefix(value, SecretRefPrefix)
+	if !ok || ref == "" {
+		return "", "", false
+	}
+
+	name, version, _ = strings.Cut(ref, "/")
+	if version == "" {
+		version = "latest"
+	}
+
+	return name, version, true
+}
+
+// ResolveSecrets replaces secret:// references in the env variables with the values of the referenced secrets
+func ResolveSecrets(envVariables map[string]string, resolve SecretResolver) error {
+	for key, value := range envVariables {
+		name, version, ok := ParseSecretRef(value)
+		if !ok {
+			continue
+		}
+
+		secretValue, err := resolve(name, version)
+		if err != nil {
+			return fmt.Errorf("unable to resolve %s for env variable %s: %w", value, key, err)
+		}
+
+		envVariables[key] = secretValue
+	}
+
+	return nil
+}
+
+// RejectSecretRefs returns an error for secret:// references in the env variables. References are only resolved from the local secret store
+// by nitric run and nitric start, deployments would receive the reference itself rather than the secret value.
+func RejectSecretRefs(envVariables map[string]string) error {
+	keys := []string{}
+
+	for key, value := range envVariables {
+		if _, _, ok := ParseSecretRef(value); ok {
+			keys = append(keys, key)
+		}
+	}
+
+	if len(keys) == 0 {
+		return nil
+	}
+
+	sort.Strings(keys)
+
+	return fmt.Errorf("env variables %s reference the local secret store with %s, which is only available to nitric run and nitric start. Set their values in a .env.<stack> file or use a secret resource instead", strings.Join(keys, ", "), SecretRefPrefix)
+}
@@ -0,0 +1,105 @@
+// Copyright Nitric Pty Ltd.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package env
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestReadLayeredEnv(t *testing.T) {
+	dir := t.TempDir()
+
+	files := map[string]string{
+		".env":         "HOST=localhost\nURL=http://${HOST}:4000\nAPI_KEY=secret://api-key\n",
+		".env.local":   "HOST=local.dev\n",
+		".env.staging": "TOKEN=secret://token/v1\n",
+		"extra.env":    "ORDERS_URL=${URL}/orders\n",
+	}
+
+	for name, contents := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(contents), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	wd, _ := os.Getwd()
+	t.Cleanup(func() { _ = os.Chdir(wd) })
+
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+
+	variables, err := ReadLayeredEnv("staging", "extra.env")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]Variable{
+		"HOST":       {Value: "local.dev", Source: ".env.local"},
+		"URL":        {Value: "http://localhost:4000", Source: ".env"},
+		"API_KEY":    {Value: "secret://api-key", Source: ".env"},
+		"TOKEN":      {Value: "secret://token/v1", Source: ".env.staging"},
+		"ORDERS_URL": {Value: "http://localhost:4000/orders", Source: "extra.env"},
+	}
+
+	if !reflect.DeepEqual(variables, want) {
+		t.Errorf("ReadLayeredEnv() = %v, want %v", variables, want)
+	}
+
+	if _, err := ReadLayeredEnv("", "missing.env"); !os.IsNotExist(err) {
+		t.Errorf("ReadLayeredEnv() error = %v, want not exist error for missing additional file", err)
+	}
+
+	envVariables, err := ReadStackEnv("staging")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = ResolveSecrets(envVariables, func(name string, version string) (string, error) {
+		return fmt.Sprintf("%s@%s", name, version), nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if envVariables["API_KEY"] != "api-key@latest" || envVariables["TOKEN"] != "token@v1" {
+		t.Errorf("ResolveSecrets() = %v, want resolved secret references", envVariables)
+	}
+}
+
+func TestRejectSecretRefs(t *testing.T) {
+	for _, tt := range []struct {
+		name         string
+		envVariables map[string]string
+		wantErr      bool
+	}{
+		{name: "no references", envVariables: map[string]string{"API_URL": "https://example.com"}},
+		{name: "empty reference", envVariables: map[string]string{"API_KEY": SecretRefPrefix}},
+		{name: "reference", envVariables: map[string]string{"API_URL": "https://example.com", "API_KEY": "secret://api-key"}, wantErr: true},
+		{name: "versioned reference", envVariables: map[string]string{"TOKEN": "secret://token/v1"}, wantErr: true},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := RejectSecretRefs(tt.envVariables); (err != nil) != tt.wantErr {
+				t.Errorf("RejectSecretRefs() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
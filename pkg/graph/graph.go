@@ -0,0 +1,218 @@
+// Copyright Nitric Pty Ltd.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package graph
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/samber/lo"
+
+	deploymentspb "github.com/nitrictech/nitric/core/pkg/proto/deployments/v1"
+	resourcespb "github.com/nitrictech/nitric/core/pkg/proto/resources/v1"
+)
+
+// Node is a single resource in the application graph
+type Node struct {
+	Id   string
+	Name string
+	Type resourcespb.ResourceType
+}
+
+// Edge is a directed, labelled dependency between two nodes
+type Edge struct {
+	From   string
+	To     string
+	Labels []string
+}
+
+// Label returns the edge labels as a single comma separated string
+func (e *Edge) Label() string {
+	return strings.Join(e.Labels, ", ")
+}
+
+// Graph is a static dependency graph of the resources declared by a nitric application
+type Graph struct {
+	Name  string
+	Nodes []*Node
+	Edges []*Edge
+}
+
+func nodeId(id *resourcespb.ResourceIdentifier) string {
+	return fmt.Sprintf("%s:%s", strings.ToLower(id.Type.String()), id.Name)
+}
+
+func (g *Graph) addNode(id *resourcespb.ResourceIdentifier) string {
+	nId := nodeId(id)
+
+	if _, exists := lo.Find(g.Nodes, func(n *Node) bool { return n.Id == nId }); !exists {
+		g.Nodes = append(g.Nodes, &Node{
+			Id:   nId,
+			Name: id.Name,
+			Type: id.Type,
+		})
+	}
+
+	return nId
+}
+
+// addEdge adds an edge between two nodes, merging labels into any existing edge between the same nodes
+func (g *Graph) addEdge(from string, to string, label string) {
+	edge, exists := lo.Find(g.Edges, func(e *Edge) bool { return e.From == from && e.To == to })
+	if !exists {
+		edge = &Edge{From: from, To: to, Labels: []string{}}
+		g.Edges = append(g.Edges, edge)
+	}
+
+	if label != "" && !lo.Contains(edge.Labels, label) {
+		edge.Labels = append(edge.Labels, label)
+	}
+}
+
+func serviceId(name string) *resourcespb.ResourceIdentifier {
+	return &resourcespb.ResourceIdentifier{Name: name, Type: resourcespb.ResourceType_Service}
+}
+
+// apiTargets returns the names of the services targeted by the operations in an API's OpenAPI document
+func apiTargets(api *deploymentspb.Api) ([]string, error) {
+	doc := struct {
+		Paths map[string]map[string]json.RawMessage `json:"paths"`
+	}{}
+
+	if err := json.Unmarshal([]byte(api.GetOpenapi()), &doc); err != nil {
+		return nil, err
+	}
+
+	targets := []string{}
+
+	for _, pathItem := range doc.Paths {
+		for _, rawOperation := range pathItem {
+			operation := struct {
+				Target map[string]string `json:"x-nitric-target"`
+			}{}
+
+			// path level fields (e.g. parameters) aren't operations and won't have a target
+			if err := json.Unmarshal(rawOperation, &operation); err != nil || operation.Target == nil {
+				continue
+			}
+
+			if name := operation.Target["name"]; name != "" && !lo.Contains(targets, name) {
+				targets = append(targets, name)
+			}
+		}
+	}
+
+	return targets, nil
+}
+
+// FromSpec builds a resource dependency graph from a deployment spec.
+//
+// Policies become edges from their principals to their resources, labelled with the granted actions.
+// Triggers such as topic subscriptions, schedules, API routes and bucket listeners become edges from the resource to the service handling them.
+func FromSpec(name string, spec *deploymentspb.Spec) (*Graph, error) {
+	g := &Graph{
+		Name:  name,
+		Nodes: []*Node{},
+		Edges: []*Edge{},
+	}
+
+	for _, res := range spec.Resources {
+		if res.Id.Type == resourcespb.ResourceType_Policy {
+			continue
+		}
+
+		g.addNode(res.Id)
+	}
+
+	for _, res := range spec.Resources {
+		from := nodeId(res.Id)
+
+		switch config := res.Config.(type) {
+		case *deploymentspb.Resource_Policy:
+			for _, principal := range config.Policy.Principals {
+				principalId := g.addNode(principal.Id)
+
+				for _, resource := range config.Policy.Resources {
+					resourceId := g.addNode(resource.Id)
+
+					for _, action := range config.Policy.Actions {
+						g.addEdge(principalId, resourceId, action.String())
+					}
+				}
+			}
+		case *deploymentspb.Resource_Topic:
+			for _, sub := range config.Topic.Subscriptions {
+				if sub.GetService() != "" {
+					g.addEdge(from, g.addNode(serviceId(sub.GetService())), "subscribe")
+				}
+			}
+		case *deploymentspb.Resource_Bucket:
+			for _, listener := range config.Bucket.Listeners {
+				if listener.GetService() != "" {
+					g.addEdge(from, g.addNode(serviceId(listener.GetService())), "notify")
+				}
+			}
+		case *deploymentspb.Resource_Schedule:
+			if config.Schedule.GetTarget().GetService() != "" {
+				g.addEdge(from, g.addNode(serviceId(config.Schedule.GetTarget().GetService())), "trigger")
+			}
+		case *deploymentspb.Resource_Http:
+			if config.Http.GetTarget().GetService() != "" {
+				g.addEdge(from, g.addNode(serviceId(config.Http.GetTarget().GetService())), "proxy")
+			}
+		case *deploymentspb.Resource_Websocket:
+			targets := map[string]*deploymentspb.WebsocketTarget{
+				"connect":    config.Websocket.ConnectTarget,
+				"disconnect": config.Websocket.DisconnectTarget,
+				"message":    config.Websocket.MessageTarget,
+			}
+
+			for _, event := range []string{"connect", "disconnect", "message"} {
+				if targets[event].GetService() != "" {
+					g.addEdge(from, g.addNode(serviceId(targets[event].GetService())), event)
+				}
+			}
+		case *deploymentspb.Resource_Api:
+			targets, err := apiTargets(config.Api)
+			if err != nil {
+				return nil, fmt.Errorf("unable to read openapi document for api %s: %w", res.Id.Name, err)
+			}
+
+			for _, target := range targets {
+				g.addEdge(from, g.addNode(serviceId(target)), "route")
+			}
+		}
+	}
+
+	// sort for stable output, so graphs can be diffed and committed alongside docs
+	slices.SortFunc(g.Nodes, func(a, b *Node) int { return strings.Compare(a.Id, b.Id) })
+	slices.SortFunc(g.Edges, func(a, b *Edge) int {
+		if c := strings.Compare(a.From, b.From); c != 0 {
+			return c
+		}
+
+		return strings.Compare(a.To, b.To)
+	})
+
+	for _, edge := range g.Edges {
+		slices.Sort(edge.Labels)
+	}
+
+	return g, nil
+}
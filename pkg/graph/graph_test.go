@@ -0,0 +1,130 @@
+// Copyright Nitric Pty Ltd.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package graph
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	deploymentspb "github.com/nitrictech/nitric/core/pkg/proto/deployments/v1"
+	resourcespb "github.com/nitrictech/nitric/core/pkg/proto/resources/v1"
+)
+
+func testSpec() *deploymentspb.Spec {
+	return &deploymentspb.Spec{
+		Resources: []*deploymentspb.Resource{
+			{
+				Id: &resourcespb.ResourceIdentifier{Name: "uploads", Type: resourcespb.ResourceType_Bucket},
+				Config: &deploymentspb.Resource_Bucket{
+					Bucket: &deploymentspb.Bucket{},
+				},
+			},
+			{
+				Id: &resourcespb.ResourceIdentifier{Name: "updates", Type: resourcespb.ResourceType_Topic},
+				Config: &deploymentspb.Resource_Topic{
+					Topic: &deploymentspb.Topic{
+						Subscriptions: []*deploymentspb.SubscriptionTarget{
+							{Target: &deploymentspb.SubscriptionTarget_Service{Service: "worker"}},
+						},
+					},
+				},
+			},
+			{
+				Id: &resourcespb.ResourceIdentifier{Name: "main", Type: resourcespb.ResourceType_Api},
+				Config: &deploymentspb.Resource_Api{
+					Api: &deploymentspb.Api{
+						Document: &deploymentspb.Api_Openapi{
+							Openapi: `{"paths":{"/files/{id}":{"parameters":[],"get":{"x-nitric-target":{"name":"api","type":"function"}}}}}`,
+						},
+					},
+				},
+			},
+			{
+				Id: &resourcespb.ResourceIdentifier{Name: "abc123", Type: resourcespb.ResourceType_Policy},
+				Config: &deploymentspb.Resource_Policy{
+					Policy: &deploymentspb.Policy{
+						Principals: []*deploymentspb.Resource{
+							{Id: &resourcespb.ResourceIdentifier{Name: "api", Type: resourcespb.ResourceType_Service}},
+						},
+						Actions: []resourcespb.Action{resourcespb.Action_BucketFilePut, resourcespb.Action_BucketFileGet},
+						Resources: []*deploymentspb.Resource{
+							{Id: &resourcespb.ResourceIdentifier{Name: "uploads", Type: resourcespb.ResourceType_Bucket}},
+						},
+					},
+				},
+			},
+			{
+				Id:     &resourcespb.ResourceIdentifier{Name: "api", Type: resourcespb.ResourceType_Service},
+				Config: &deploymentspb.Resource_Service{Service: &deploymentspb.Service{}},
+			},
+			{
+				Id:     &resourcespb.ResourceIdentifier{Name: "worker", Type: resourcespb.ResourceType_Service},
+				Config: &deploymentspb.Resource_Service{Service: &deploymentspb.Service{}},
+			},
+		},
+	}
+}
+
+func TestFromSpec(t *testing.T) {
+	g, err := FromSpec("test", testSpec())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	nodeIds := []string{}
+
+	for _, n := range g.Nodes {
+		nodeIds = append(nodeIds, n.Id)
+	}
+
+	expectedNodes := []string{"api:main", "bucket:uploads", "service:api", "service:worker", "topic:updates"}
+	if diff := cmp.Diff(expectedNodes, nodeIds); diff != "" {
+		t.Errorf("unexpected nodes (-want +got):\n%s", diff)
+	}
+
+	expectedEdges := []*Edge{
+		{From: "api:main", To: "service:api", Labels: []string{"route"}},
+		{From: "service:api", To: "bucket:uploads", Labels: []string{"BucketFileGet", "BucketFilePut"}},
+		{From: "topic:updates", To: "service:worker", Labels: []string{"subscribe"}},
+	}
+	if diff := cmp.Diff(expectedEdges, g.Edges); diff != "" {
+		t.Errorf("unexpected edges (-want +got):\n%s", diff)
+	}
+}
+
+func TestToMermaid(t *testing.T) {
+	g := &Graph{
+		Name: "test",
+		Nodes: []*Node{
+			{Id: "service:api", Name: "api", Type: resourcespb.ResourceType_Service},
+			{Id: "topic:updates", Name: "updates", Type: resourcespb.ResourceType_Topic},
+		},
+		Edges: []*Edge{
+			{From: "service:api", To: "topic:updates", Labels: []string{"TopicPublish"}},
+		},
+	}
+
+	expected := `flowchart LR
+  service_api["api<br/>(service)"]
+  topic_updates>"updates<br/>(topic)"]
+  service_api -->|"TopicPublish"| topic_updates
+`
+	if diff := cmp.Diff(expected, g.ToMermaid()); diff != "" {
+		t.Errorf("unexpected mermaid output (-want +got):\n%s", diff)
+	}
+}
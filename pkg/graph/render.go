@@ -0,0 +1,163 @@
+// Copyright Nitric Pty Ltd.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package graph
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strings"
+
+	resourcespb "github.com/nitrictech/nitric/core/pkg/proto/resources/v1"
+)
+
+type Format string
+
+const (
+	Format_Dot     Format = "dot"
+	Format_Mermaid Format = "mermaid"
+	Format_SVG     Format = "svg"
+)
+
+var Formats = []string{string(Format_Dot), string(Format_Mermaid), string(Format_SVG)}
+
+var dotShapes = map[resourcespb.ResourceType]string{
+	resourcespb.ResourceType_Service:       "box",
+	resourcespb.ResourceType_Batch:         "box",
+	resourcespb.ResourceType_Bucket:        "folder",
+	resourcespb.ResourceType_Topic:         "cds",
+	resourcespb.ResourceType_Queue:         "cds",
+	resourcespb.ResourceType_Api:           "component",
+	resourcespb.ResourceType_Http:          "component",
+	resourcespb.ResourceType_Websocket:     "component",
+	resourcespb.ResourceType_Website:       "tab",
+	resourcespb.ResourceType_Schedule:      "hexagon",
+	resourcespb.ResourceType_SqlDatabase:   "cylinder",
+	resourcespb.ResourceType_KeyValueStore: "cylinder",
+	resourcespb.ResourceType_Secret:        "note",
+}
+
+func nodeLabel(n *Node) string {
+	return fmt.Sprintf("%s\n(%s)", n.Name, strings.ToLower(n.Type.String()))
+}
+
+// ToDot renders the graph in the graphviz DOT language
+func (g *Graph) ToDot() string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "digraph %q {\n", g.Name)
+	b.WriteString("  rankdir=LR;\n")
+	b.WriteString("  node [fontname=\"Helvetica\", fontsize=10];\n")
+	b.WriteString("  edge [fontname=\"Helvetica\", fontsize=8];\n")
+
+	for _, n := range g.Nodes {
+		shape, ok := dotShapes[n.Type]
+		if !ok {
+			shape = "ellipse"
+		}
+
+		fmt.Fprintf(&b, "  %q [label=%q, shape=%s];\n", n.Id, nodeLabel(n), shape)
+	}
+
+	for _, e := range g.Edges {
+		fmt.Fprintf(&b, "  %q -> %q [label=%q];\n", e.From, e.To, e.Label())
+	}
+
+	b.WriteString("}\n")
+
+	return b.String()
+}
+
+var notMermaidId = regexp.MustCompile(`[^a-zA-Z0-9_]`)
+
+func mermaidId(id string) string {
+	return notMermaidId.ReplaceAllString(id, "_")
+}
+
+func mermaidText(text string) string {
+	return strings.ReplaceAll(text, `"`, "#quot;")
+}
+
+// ToMermaid renders the graph as a mermaid flowchart
+func (g *Graph) ToMermaid() string {
+	var b strings.Builder
+
+	b.WriteString("flowchart LR\n")
+
+	for _, n := range g.Nodes {
+		label := mermaidText(fmt.Sprintf("%s<br/>(%s)", n.Name, strings.ToLower(n.Type.String())))
+
+		switch n.Type {
+		case resourcespb.ResourceType_SqlDatabase, resourcespb.ResourceType_KeyValueStore:
+			fmt.Fprintf(&b, "  %s[(\"%s\")]\n", mermaidId(n.Id), label)
+		case resourcespb.ResourceType_Topic, resourcespb.ResourceType_Queue:
+			fmt.Fprintf(&b, "  %s>\"%s\"]\n", mermaidId(n.Id), label)
+		case resourcespb.ResourceType_Schedule:
+			fmt.Fprintf(&b, "  %s{{\"%s\"}}\n", mermaidId(n.Id), label)
+		default:
+			fmt.Fprintf(&b, "  %s[\"%s\"]\n", mermaidId(n.Id), label)
+		}
+	}
+
+	for _, e := range g.Edges {
+		if len(e.Labels) == 0 {
+			fmt.Fprintf(&b, "  %s --> %s\n", mermaidId(e.From), mermaidId(e.To))
+			continue
+		}
+
+		fmt.Fprintf(&b, "  %s -->|\"%s\"| %s\n", mermaidId(e.From), mermaidText(e.Label()), mermaidId(e.To))
+	}
+
+	return b.String()
+}
+
+// ToSVG renders the graph as an SVG image, using a locally installed graphviz
+func (g *Graph) ToSVG() ([]byte, error) {
+	dotPath, err := exec.LookPath("dot")
+	if err != nil {
+		return nil, fmt.Errorf("svg output requires graphviz to be installed (https://graphviz.org/download), or use --format dot|mermaid: %w", err)
+	}
+
+	stdout := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+
+	cmd := exec.Command(dotPath, "-Tsvg")
+	cmd.Stdin = strings.NewReader(g.ToDot())
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("error rendering svg: %w: %s", err, stderr.String())
+	}
+
+	return stdout.Bytes(), nil
+}
+
+// Render renders the graph in the requested format
+func (g *Graph) Render(format Format) ([]byte, error) {
+	switch format {
+	case Format_Dot:
+		return []byte(g.ToDot()), nil
+	case Format_Mermaid:
+		return []byte(g.ToMermaid()), nil
+	case Format_SVG:
+		return g.ToSVG()
+	default:
+		return nil, fmt.Errorf("unsupported graph format %s", format)
+	}
+}
@@ -19,6 +19,7 @@ package grpcx
 import (
 	"context"
 	"fmt"
+	"strconv"
 	"strings"
 
 	"google.golang.org/grpc"
@@ -27,6 +28,9 @@ import (
 
 const ServiceNameKey = "x-nitric-service-name"
 
+// ServiceReplicaKey identifies the replica of a service a request came from, when a service runs several containers
+const ServiceReplicaKey = "x-nitric-service-replica"
+
 type wrappedStream struct {
 	grpc.ServerStream
 	ctx context.Context
@@ -42,10 +46,16 @@ func newWrappedStream(stream grpc.ServerStream, ctx context.Context) grpc.Server
 }
 
 func CreateServiceNameInterceptor(serviceName string) (grpc.UnaryServerInterceptor, grpc.StreamServerInterceptor) {
+	return CreateServiceReplicaInterceptor(serviceName, 0)
+}
+
+// CreateServiceReplicaInterceptor injects the name and replica of a service into the metadata of its requests
+func CreateServiceReplicaInterceptor(serviceName string, replica int) (grpc.UnaryServerInterceptor, grpc.StreamServerInterceptor) {
 	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
 			// Inject the name of the service
 			md, _ := metadata.FromIncomingContext(ctx)
 			md.Append(ServiceNameKey, serviceName) // example of adding new metadata
+			md.Append(ServiceReplicaKey, strconv.Itoa(replica))
 
 			newCtx := metadata.NewIncomingContext(ctx, md)
 
@@ -58,6 +68,7 @@ func CreateServiceNameInterceptor(serviceName string) (grpc.UnaryServerIntercept
 
 			// Modify metadata here
 			md.Append(ServiceNameKey, serviceName)
+			md.Append(ServiceReplicaKey, strconv.Itoa(replica))
 
 			// Create a new context with the modified metadata
 			newCtx := metadata.NewIncomingContext(ss.Context(), md)
@@ -90,3 +101,18 @@ func GetServiceNameFromIncomingContext(ctx context.Context) (string, error) {
 func GetServiceNameFromStream(stream grpc.ServerStream) (string, error) {
 	return GetServiceNameFromIncomingContext(stream.Context())
 }
+
+// GetServiceReplicaFromStream returns the replica of the service a stream came from, it is zero for services with a single replica
+func GetServiceReplicaFromStream(stream grpc.ServerStream) int {
+	md, ok := metadata.FromIncomingContext(stream.Context())
+	if !ok {
+		return 0
+	}
+
+	replica, err := strconv.Atoi(strings.Join(md.Get(ServiceReplicaKey), ""))
+	if err != nil {
+		return 0
+	}
+
+	return replica
+}
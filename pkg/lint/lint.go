@@ -0,0 +1,144 @@
+// Copyright Nitric Pty Ltd.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lint
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/samber/lo"
+
+	"github.com/nitrictech/cli/pkg/project"
+	deploymentspb "github.com/nitrictech/nitric/core/pkg/proto/deployments/v1"
+)
+
+type Severity string
+
+const (
+	Severity_Off     Severity = "off"
+	Severity_Info    Severity = "info"
+	Severity_Warning Severity = "warning"
+	Severity_Error   Severity = "error"
+)
+
+var Severities = []Severity{Severity_Off, Severity_Info, Severity_Warning, Severity_Error}
+
+// Context is the project information available to lint rules
+type Context struct {
+	// The project's nitric.yaml configuration
+	Config *project.ProjectConfiguration
+	// The deployment spec collected from the project's services
+	Spec *deploymentspb.Spec
+}
+
+// Issue is a single problem reported by a lint rule
+type Issue struct {
+	Rule     string   `json:"rule"`
+	Severity Severity `json:"severity"`
+	Resource string   `json:"resource,omitempty"`
+	Message  string   `json:"message"`
+}
+
+// Rule is a lint check that can be run against a project
+type Rule struct {
+	// Name of the rule, used to configure it in nitric.yaml
+	Name string
+	// Description of what the rule checks
+	Description string
+	// Severity of issues reported by the rule, unless overridden in nitric.yaml
+	DefaultSeverity Severity
+	// Check runs the rule, returning any issues found. The severity of returned issues is set by the linter.
+	Check func(ctx *Context) []Issue
+}
+
+var rules = []Rule{}
+
+// Register adds a rule to the set of rules run by the linter
+func Register(rule Rule) {
+	if _, exists := lo.Find(rules, func(r Rule) bool { return r.Name == rule.Name }); exists {
+		panic(fmt.Sprintf("lint rule %s registered more than once", rule.Name))
+	}
+
+	rules = append(rules, rule)
+}
+
+// Rules returns all registered rules, sorted by name
+func Rules() []Rule {
+	sorted := slices.Clone(rules)
+
+	slices.SortFunc(sorted, func(a, b Rule) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+
+	return sorted
+}
+
+// severities resolves the severity of each rule, applying overrides from nitric.yaml
+func severities(config project.LintConfiguration) (map[string]Severity, error) {
+	resolved := map[string]Severity{}
+
+	for _, rule := range rules {
+		resolved[rule.Name] = rule.DefaultSeverity
+	}
+
+	for ruleName, severity := range config.Rules {
+		if _, ok := resolved[ruleName]; !ok {
+			return nil, fmt.Errorf("unknown lint rule '%s' in nitric.yaml, run 'nitric lint --list' to see available rules", ruleName)
+		}
+
+		if !lo.Contains(Severities, Severity(severity)) {
+			return nil, fmt.Errorf("invalid severity '%s' for lint rule '%s' in nitric.yaml, must be one of: off, info, warning, error", severity, ruleName)
+		}
+
+		resolved[ruleName] = Severity(severity)
+	}
+
+	return resolved, nil
+}
+
+// Run runs all enabled lint rules against the project
+func Run(ctx *Context) ([]Issue, error) {
+	ruleSeverities, err := severities(ctx.Config.Lint)
+	if err != nil {
+		return nil, err
+	}
+
+	issues := []Issue{}
+
+	for _, rule := range Rules() {
+		severity := ruleSeverities[rule.Name]
+		if severity == Severity_Off {
+			continue
+		}
+
+		for _, issue := range rule.Check(ctx) {
+			issue.Rule = rule.Name
+			issue.Severity = severity
+			issues = append(issues, issue)
+		}
+	}
+
+	return issues, nil
+}
+
+// HasErrors returns true if any of the issues have error severity
+func HasErrors(issues []Issue) bool {
+	return lo.ContainsBy(issues, func(issue Issue) bool {
+		return issue.Severity == Severity_Error
+	})
+}
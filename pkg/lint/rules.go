@@ -0,0 +1,281 @@
+// Copyright Nitric Pty Ltd.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lint
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/robfig/cron/v3"
+	"github.com/samber/lo"
+
+	"github.com/nitrictech/cli/pkg/preview"
+	deploymentspb "github.com/nitrictech/nitric/core/pkg/proto/deployments/v1"
+	resourcespb "github.com/nitrictech/nitric/core/pkg/proto/resources/v1"
+)
+
+func resourcesOfType(spec *deploymentspb.Spec, resourceType resourcespb.ResourceType) []*deploymentspb.Resource {
+	return lo.Filter(spec.Resources, func(res *deploymentspb.Resource, _ int) bool {
+		return res.Id.Type == resourceType
+	})
+}
+
+// isGranted returns true if any policy in the spec grants the action on the resource, resources of different types may share a name
+func isGranted(spec *deploymentspb.Spec, action resourcespb.Action, id *resourcespb.ResourceIdentifier) bool {
+	return lo.ContainsBy(resourcesOfType(spec, resourcespb.ResourceType_Policy), func(res *deploymentspb.Resource) bool {
+		policy := res.GetPolicy()
+
+		return lo.Contains(policy.Actions, action) && lo.ContainsBy(policy.Resources, func(r *deploymentspb.Resource) bool {
+			return r.Id.Name == id.Name && r.Id.Type == id.Type
+		})
+	})
+}
+
+func topicNoSubscribers(ctx *Context) []Issue {
+	issues := []Issue{}
+
+	for _, res := range resourcesOfType(ctx.Spec, resourcespb.ResourceType_Topic) {
+		if len(res.GetTopic().GetSubscriptions()) == 0 {
+			issues = append(issues, Issue{
+				Resource: res.Id.Name,
+				Message:  fmt.Sprintf("topic '%s' has no subscribers, messages published to it will be dropped", res.Id.Name),
+			})
+		}
+	}
+
+	return issues
+}
+
+func queueNoConsumers(ctx *Context) []Issue {
+	issues := []Issue{}
+
+	for _, res := range resourcesOfType(ctx.Spec, resourcespb.ResourceType_Queue) {
+		if !isGranted(ctx.Spec, resourcespb.Action_QueueDequeue, res.Id) {
+			issues = append(issues, Issue{
+				Resource: res.Id.Name,
+				Message:  fmt.Sprintf("queue '%s' is never dequeued", res.Id.Name),
+			})
+		}
+	}
+
+	return issues
+}
+
+func secretUnused(ctx *Context) []Issue {
+	issues := []Issue{}
+
+	for _, res := range resourcesOfType(ctx.Spec, resourcespb.ResourceType_Secret) {
+		if !isGranted(ctx.Spec, resourcespb.Action_SecretAccess, res.Id) {
+			issues = append(issues, Issue{
+				Resource: res.Id.Name,
+				Message:  fmt.Sprintf("secret '%s' is never accessed", res.Id.Name),
+			})
+		}
+	}
+
+	return issues
+}
+
+func apiNoSecurity(ctx *Context) []Issue {
+	issues := []Issue{}
+
+	for _, res := range resourcesOfType(ctx.Spec, resourcespb.ResourceType_Api) {
+		doc := &openapi3.T{}
+
+		if err := json.Unmarshal([]byte(res.GetApi().GetOpenapi()), doc); err != nil {
+			issues = append(issues, Issue{
+				Resource: res.Id.Name,
+				Message:  fmt.Sprintf("unable to read openapi document for api '%s': %s", res.Id.Name, err),
+			})
+
+			continue
+		}
+
+		if doc.Components == nil || len(doc.Components.SecuritySchemes) == 0 {
+			issues = append(issues, Issue{
+				Resource: res.Id.Name,
+				Message:  fmt.Sprintf("api '%s' has no security definitions, all of its routes are publicly accessible", res.Id.Name),
+			})
+		}
+	}
+
+	return issues
+}
+
+// minimumScheduleInterval is the most frequently a schedule can run before it's reported
+const minimumScheduleInterval = time.Minute
+
+// cronParser supports an optional seconds field, so expressions firing more than once a minute can be detected
+var cronParser = cron.NewParser(cron.SecondOptional | cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor)
+
+// scheduleInterval returns the shortest interval between runs of a schedule
+func scheduleInterval(schedule *deploymentspb.Schedule) (time.Duration, error) {
+	if every := schedule.GetEvery(); every != nil {
+		parts := strings.Split(strings.TrimSpace(every.Rate), " ")
+		if len(parts) != 2 {
+			return 0, fmt.Errorf("invalid rate: %s", every.Rate)
+		}
+
+		rate, err := strconv.Atoi(parts[0])
+		if err != nil {
+			return 0, fmt.Errorf("invalid rate: %s, must start with integer", every.Rate)
+		}
+
+		switch {
+		case strings.HasPrefix(parts[1], "second"):
+			return time.Duration(rate) * time.Second, nil
+		case strings.HasPrefix(parts[1], "minute"):
+			return time.Duration(rate) * time.Minute, nil
+		case strings.HasPrefix(parts[1], "hour"):
+			return time.Duration(rate) * time.Hour, nil
+		case strings.HasPrefix(parts[1], "day"):
+			return time.Duration(rate) * 24 * time.Hour, nil
+		default:
+			return 0, fmt.Errorf("invalid rate: %s, unknown unit %s", every.Rate, parts[1])
+		}
+	}
+
+	sched, err := cronParser.Parse(schedule.GetCron().GetExpression())
+	if err != nil {
+		return 0, err
+	}
+
+	// sample the first few runs, which is enough to find sub-minute intervals
+	shortest := time.Duration(0)
+	previous := sched.Next(time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC))
+
+	for i := 0; i < 10; i++ {
+		next := sched.Next(previous)
+
+		if interval := next.Sub(previous); shortest == 0 || interval < shortest {
+			shortest = interval
+		}
+
+		previous = next
+	}
+
+	return shortest, nil
+}
+
+func scheduleTooFrequent(ctx *Context) []Issue {
+	issues := []Issue{}
+
+	for _, res := range resourcesOfType(ctx.Spec, resourcespb.ResourceType_Schedule) {
+		interval, err := scheduleInterval(res.GetSchedule())
+		if err != nil {
+			issues = append(issues, Issue{
+				Resource: res.Id.Name,
+				Message:  fmt.Sprintf("schedule '%s' has an invalid cadence: %s", res.Id.Name, err),
+			})
+
+			continue
+		}
+
+		if interval < minimumScheduleInterval {
+			issues = append(issues, Issue{
+				Resource: res.Id.Name,
+				Message:  fmt.Sprintf("schedule '%s' runs every %s, more often than once a minute which most cloud schedulers don't support", res.Id.Name, interval),
+			})
+		}
+	}
+
+	return issues
+}
+
+func unknownPreviewFeature(ctx *Context) []Issue {
+	issues := []Issue{}
+
+	for _, feature := range ctx.Config.Preview {
+		if !lo.Contains(preview.Features, feature) {
+			issues = append(issues, Issue{
+				Message: fmt.Sprintf("unknown preview feature '%s' in nitric.yaml", feature),
+			})
+		}
+	}
+
+	return issues
+}
+
+func serviceNoStartCommand(ctx *Context) []Issue {
+	issues := []Issue{}
+
+	for _, service := range ctx.Config.Services {
+		if service.Start == "" {
+			issues = append(issues, Issue{
+				Resource: service.Match,
+				Message:  fmt.Sprintf("services matching '%s' have no start command, they can't be run with 'nitric start'", service.Match),
+			})
+		}
+	}
+
+	return issues
+}
+
+func init() {
+	Register(Rule{
+		Name:            "topic-no-subscribers",
+		Description:     "topics that have no subscribers",
+		DefaultSeverity: Severity_Warning,
+		Check:           topicNoSubscribers,
+	})
+
+	Register(Rule{
+		Name:            "queue-no-consumers",
+		Description:     "queues that no service or batch dequeues from",
+		DefaultSeverity: Severity_Warning,
+		Check:           queueNoConsumers,
+	})
+
+	Register(Rule{
+		Name:            "secret-unused",
+		Description:     "secrets that no service or batch accesses",
+		DefaultSeverity: Severity_Warning,
+		Check:           secretUnused,
+	})
+
+	Register(Rule{
+		Name:            "api-no-security",
+		Description:     "apis without a security definition",
+		DefaultSeverity: Severity_Warning,
+		Check:           apiNoSecurity,
+	})
+
+	Register(Rule{
+		Name:            "schedule-too-frequent",
+		Description:     "schedules that run more often than once a minute",
+		DefaultSeverity: Severity_Warning,
+		Check:           scheduleTooFrequent,
+	})
+
+	Register(Rule{
+		Name:            "unknown-preview-feature",
+		Description:     "preview features in nitric.yaml that this version of the CLI doesn't recognize",
+		DefaultSeverity: Severity_Warning,
+		Check:           unknownPreviewFeature,
+	})
+
+	Register(Rule{
+		Name:            "service-no-start-command",
+		Description:     "services in nitric.yaml without a start command for 'nitric start'",
+		DefaultSeverity: Severity_Info,
+		Check:           serviceNoStartCommand,
+	})
+}
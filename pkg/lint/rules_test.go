@@ -0,0 +1,100 @@
+// Copyright Nitric Pty Ltd.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lint
+
+import (
+	"testing"
+	"time"
+
+	deploymentspb "github.com/nitrictech/nitric/core/pkg/proto/deployments/v1"
+	resourcespb "github.com/nitrictech/nitric/core/pkg/proto/resources/v1"
+)
+
+func TestScheduleInterval(t *testing.T) {
+	for _, tt := range []struct {
+		schedule *deploymentspb.Schedule
+		expected time.Duration
+	}{
+		{
+			schedule: &deploymentspb.Schedule{Cadence: &deploymentspb.Schedule_Every{Every: &deploymentspb.ScheduleEvery{Rate: "5 minutes"}}},
+			expected: 5 * time.Minute,
+		},
+		{
+			schedule: &deploymentspb.Schedule{Cadence: &deploymentspb.Schedule_Every{Every: &deploymentspb.ScheduleEvery{Rate: "30 seconds"}}},
+			expected: 30 * time.Second,
+		},
+		{
+			schedule: &deploymentspb.Schedule{Cadence: &deploymentspb.Schedule_Every{Every: &deploymentspb.ScheduleEvery{Rate: "1 day"}}},
+			expected: 24 * time.Hour,
+		},
+		{
+			schedule: &deploymentspb.Schedule{Cadence: &deploymentspb.Schedule_Cron{Cron: &deploymentspb.ScheduleCron{Expression: "* * * * *"}}},
+			expected: time.Minute,
+		},
+		{
+			schedule: &deploymentspb.Schedule{Cadence: &deploymentspb.Schedule_Cron{Cron: &deploymentspb.ScheduleCron{Expression: "*/15 * * * * *"}}},
+			expected: 15 * time.Second,
+		},
+	} {
+		interval, err := scheduleInterval(tt.schedule)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if interval != tt.expected {
+			t.Errorf("expected interval %s for %s, got %s", tt.expected, tt.schedule, interval)
+		}
+	}
+}
+
+func TestIsGranted(t *testing.T) {
+	id := func(name string, resourceType resourcespb.ResourceType) *resourcespb.ResourceIdentifier {
+		return &resourcespb.ResourceIdentifier{Name: name, Type: resourceType}
+	}
+
+	spec := &deploymentspb.Spec{
+		Resources: []*deploymentspb.Resource{
+			{
+				Id: id("worker-policy", resourcespb.ResourceType_Policy),
+				Config: &deploymentspb.Resource_Policy{
+					Policy: &deploymentspb.Policy{
+						Actions:   []resourcespb.Action{resourcespb.Action_QueueDequeue},
+						Resources: []*deploymentspb.Resource{{Id: id("orders", resourcespb.ResourceType_Queue)}},
+					},
+				},
+			},
+		},
+	}
+
+	for _, tt := range []struct {
+		name     string
+		action   resourcespb.Action
+		id       *resourcespb.ResourceIdentifier
+		expected bool
+	}{
+		{name: "granted", action: resourcespb.Action_QueueDequeue, id: id("orders", resourcespb.ResourceType_Queue), expected: true},
+		{name: "other action", action: resourcespb.Action_QueueEnqueue, id: id("orders", resourcespb.ResourceType_Queue)},
+		{name: "other resource", action: resourcespb.Action_QueueDequeue, id: id("invoices", resourcespb.ResourceType_Queue)},
+		{name: "same name different type", action: resourcespb.Action_QueueDequeue, id: id("orders", resourcespb.ResourceType_Topic)},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isGranted(spec, tt.action, tt.id); got != tt.expected {
+				t.Errorf("isGranted() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
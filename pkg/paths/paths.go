@@ -96,6 +96,11 @@ func NitricLocalPassphrasePath() string {
 	return filepath.Join(NitricHomeDir(), ".local-stack-pass")
 }
 
+// NitricLocalSecretsKeyPath returns the path of the key used to encrypt local secret values at rest
+func NitricLocalSecretsKeyPath() string {
+	return filepath.Join(NitricHomeDir(), ".local-secrets-key")
+}
+
 // NitricTmpDir returns the directory to find temporary files for a project.
 func NitricTmpDir(stackPath string) string {
 	return filepath.Join(stackPath, ".nitric")
@@ -137,6 +142,16 @@ func NitricTlsKeyFile(stackPath string) string {
 	return filepath.Join(NitricTlsCredentialsPath(stackPath), "./key.pem")
 }
 
+// NitricDatabaseSnapshotsDir returns the directory local database snapshots are stored in.
+func NitricDatabaseSnapshotsDir(stackPath string) string {
+	return filepath.Join(NitricTmpDir(stackPath), "./snapshots")
+}
+
+// NitricWebsocketSessionsDir returns the directory scripted websocket sessions are saved to, these are kept with the project source.
+func NitricWebsocketSessionsDir(stackPath string) string {
+	return filepath.Join(stackPath, "websocket-sessions")
+}
+
 // NitricHistoryFile returns a path to a request history file, making one if it doesn't exist
 func NitricHistoryFile(stackPath string, historyType string) (string, error) {
 	logDir := NitricTmpDir(stackPath)
@@ -174,3 +189,8 @@ func GoPath() (string, error) {
 
 	return goPath, nil
 }
+
+// VSCodeLaunchFile returns the path of the VS Code launch configurations of a project.
+func VSCodeLaunchFile(stackPath string) string {
+	return filepath.Join(stackPath, ".vscode", "launch.json")
+}
@@ -25,3 +25,12 @@ const (
 	Feature_BatchServices   Feature = "batch-services"
 	Feature_Websites        Feature = "websites"
 )
+
+// Features are all of the preview features supported by this version of the CLI
+var Features = []Feature{
+	Feature_DockerProviders,
+	Feature_BetaProviders,
+	Feature_SqlDatabases,
+	Feature_BatchServices,
+	Feature_Websites,
+}
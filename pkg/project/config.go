@@ -20,11 +20,13 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"reflect"
 
 	"github.com/spf13/afero"
 	"gopkg.in/yaml.v3"
 
 	"github.com/nitrictech/cli/pkg/preview"
+	"github.com/nitrictech/cli/pkg/project/yamlschema"
 )
 
 type RuntimeConfiguration struct {
@@ -107,6 +109,11 @@ func (w WebsiteConfiguration) GetBasedir() string {
 	return w.Basedir
 }
 
+type LintConfiguration struct {
+	// Severity overrides for lint rules, keyed by rule name (off, info, warning or error)
+	Rules map[string]string `yaml:"rules,omitempty"`
+}
+
 type ProjectConfiguration struct {
 	Name      string                          `yaml:"name"`
 	Directory string                          `yaml:"-"`
@@ -115,10 +122,17 @@ type ProjectConfiguration struct {
 	Websites  []WebsiteConfiguration          `yaml:"websites"`
 	Runtimes  map[string]RuntimeConfiguration `yaml:"runtimes,omitempty"`
 	Preview   []preview.Feature               `yaml:"preview,omitempty"`
+	Lint      LintConfiguration               `yaml:"lint,omitempty"`
 }
 
 const defaultNitricYamlPath = "./nitric.yaml"
 
+// ConfigurationSchema returns the JSON Schema of the nitric.yaml file
+func ConfigurationSchema() *yamlschema.Schema {
+	return yamlschema.Generate(reflect.TypeOf(ProjectConfiguration{})).Root("nitric.yaml")
+}
+
+// ToFile writes the whole configuration to a file, use EditConfigurationFile to edit an existing nitric.yaml without losing its comments and formatting
 func (p ProjectConfiguration) ToFile(fs afero.Fs, filepath string) error {
 	nitricYamlPath := defaultNitricYamlPath
 
@@ -174,6 +188,10 @@ func ConfigurationFromFile(fs afero.Fs, filePath string) (*ProjectConfiguration,
 		return nil, fmt.Errorf("unable to parse nitric.yaml: %w", err)
 	}
 
+	if err := yamlschema.Validate(projectFileContents, ConfigurationSchema()); err != nil {
+		return nil, fmt.Errorf("invalid nitric.yaml:\n%w", err)
+	}
+
 	projectConfig.Directory = filepath.Dir(filePath)
 
 	return projectConfig, nil
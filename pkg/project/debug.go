@@ -0,0 +1,249 @@
+// Copyright Nitric Pty Ltd.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package project
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"net"
+	"path"
+	"path/filepath"
+
+	"github.com/samber/lo"
+	"github.com/spf13/afero"
+
+	"github.com/nitrictech/cli/pkg/netx"
+	"github.com/nitrictech/cli/pkg/project/runtime"
+)
+
+// DebugTarget - a service run with a debugger, with the host port the debugger of each replica is published on
+type DebugTarget struct {
+	ServicePath string
+	Debugger    *runtime.Debugger
+	HostPorts   []int
+
+	baseDir string
+}
+
+// LaunchConfigurations - returns the VS Code attach configurations for each replica of the service
+func (t *DebugTarget) LaunchConfigurations() []map[string]any {
+	configurations := []map[string]any{}
+
+	for replica, hostPort := range t.HostPorts {
+		configurations = append(configurations, t.Debugger.LaunchConfiguration(launchConfigurationName(t.ServicePath, replica, len(t.HostPorts)), hostPort, t.baseDir))
+	}
+
+	return configurations
+}
+
+// launchConfigurationName returns the name of the attach configuration of a replica of a debugged service
+func launchConfigurationName(servicePath string, replica int, replicas int) string {
+	name := fmt.Sprintf("Nitric: attach %s", servicePath)
+	if replicas > 1 {
+		name = fmt.Sprintf("%s[%d]", name, replica)
+	}
+
+	return name
+}
+
+// readLaunchFile reads a VS Code launch.json, returning an empty launch.json if it doesn't exist
+func readLaunchFile(fs afero.Fs, filePath string) (map[string]any, []byte, error) {
+	launch := map[string]any{
+		"version":        "0.2.0",
+		"configurations": []any{},
+	}
+
+	contents, err := afero.ReadFile(fs, filePath)
+	if err != nil {
+		return launch, nil, nil
+	}
+
+	if err := json.Unmarshal(contents, &launch); err != nil {
+		return nil, nil, fmt.Errorf("unable to update %s, it is not valid JSON: %w", filePath, err)
+	}
+
+	return launch, contents, nil
+}
+
+// LaunchConfigurationPorts - returns the debugger ports of the attach configurations in a VS Code launch.json by name,
+// so debuggers keep their ports between runs
+func LaunchConfigurationPorts(fs afero.Fs, filePath string) map[string]int {
+	ports := map[string]int{}
+
+	launch, _, err := readLaunchFile(fs, filePath)
+	if err != nil {
+		return ports
+	}
+
+	configurations, _ := launch["configurations"].([]any)
+
+	for _, configuration := range configurations {
+		existing, ok := configuration.(map[string]any)
+		if !ok {
+			continue
+		}
+
+		port, ok := existing["port"].(float64)
+		if connect, isMap := existing["connect"].(map[string]any); !ok && isMap {
+			port, ok = connect["port"].(float64)
+		}
+
+		if ok {
+			ports[fmt.Sprint(existing["name"])] = int(port)
+		}
+	}
+
+	return ports
+}
+
+// reserveDebuggerPort reserves the preferred port of a debugger if it is free, otherwise the next free port from the debugger's default port
+func reserveDebuggerPort(debugger *runtime.Debugger, preferredPort int) (net.Listener, error) {
+	if preferredPort > 0 {
+		if lis, err := netx.GetNextListener(netx.MinPort(preferredPort), netx.MaxPort(preferredPort+1)); err == nil {
+			return lis, nil
+		}
+	}
+
+	return netx.GetNextListener(netx.MinPort(debugger.Port), netx.MaxPort(debugger.Port+1000))
+}
+
+// matchesService returns true if a --debug pattern is the name, file path or a glob matching the file path of a service
+func matchesService(pattern string, svc Service) bool {
+	servicePath := filepath.ToSlash(svc.GetFilePath())
+
+	if pattern == svc.Name || filepath.ToSlash(filepath.Clean(pattern)) == servicePath {
+		return true
+	}
+
+	matched, _ := path.Match(pattern, servicePath)
+
+	return matched
+}
+
+// DebugTargets - returns the services matching the patterns with the debugger of their runtime,
+// reserving a host port for the debugger of each replica. Replicas keep their preferred port, keyed by launch configuration name, if it is free,
+// otherwise ports are reserved from the debugger's default port. The build arguments of the debuggers are added to the service builds.
+func (p *Project) DebugTargets(patterns []string, preferredPorts map[string]int) ([]*DebugTarget, error) {
+	targets := []*DebugTarget{}
+	listeners := []net.Listener{}
+
+	defer func() {
+		for _, lis := range listeners {
+			_ = lis.Close()
+		}
+	}()
+
+	for _, pattern := range patterns {
+		if !lo.SomeBy(p.services, func(svc Service) bool { return matchesService(pattern, svc) }) {
+			return nil, fmt.Errorf("no service matches --debug %s", pattern)
+		}
+	}
+
+	for i, svc := range p.services {
+		if !lo.SomeBy(patterns, func(pattern string) bool { return matchesService(pattern, svc) }) {
+			continue
+		}
+
+		debugger, err := runtime.NewDebugger(svc.filepath)
+		if err != nil {
+			return nil, fmt.Errorf("unable to debug service %s: %w", svc.GetFilePath(), err)
+		}
+
+		target := &DebugTarget{
+			ServicePath: svc.GetFilePath(),
+			Debugger:    debugger,
+			HostPorts:   []int{},
+			baseDir:     svc.basedir,
+		}
+
+		if len(debugger.BuildArgs) > 0 {
+			buildArgs := maps.Clone(svc.buildContext.BuildArguments)
+			if buildArgs == nil {
+				buildArgs = map[string]string{}
+			}
+
+			maps.Copy(buildArgs, debugger.BuildArgs)
+
+			p.services[i].buildContext.BuildArguments = buildArgs
+		}
+
+		replicas := max(p.LocalConfig.Service(svc.GetFilePath()).Replicas, 1)
+
+		for replica := range replicas {
+			// listeners stay open until every port is reserved, so each replica gets a different port
+			lis, err := reserveDebuggerPort(debugger, preferredPorts[launchConfigurationName(target.ServicePath, replica, replicas)])
+			if err != nil {
+				return nil, fmt.Errorf("unable to reserve a debugger port for service %s: %w", svc.GetFilePath(), err)
+			}
+
+			listeners = append(listeners, lis)
+			target.HostPorts = append(target.HostPorts, lis.Addr().(*net.TCPAddr).Port)
+		}
+
+		targets = append(targets, target)
+	}
+
+	return targets, nil
+}
+
+// WriteLaunchConfigurations - adds the attach configurations of the debug targets to a VS Code launch.json,
+// replacing configurations with the same name and keeping any others. The file isn't written if it already has the configurations.
+func WriteLaunchConfigurations(fs afero.Fs, filePath string, targets []*DebugTarget) error {
+	launch, existingContents, err := readLaunchFile(fs, filePath)
+	if err != nil {
+		return err
+	}
+
+	configurations, _ := launch["configurations"].([]any)
+	names := map[string]bool{}
+
+	added := []any{}
+
+	for _, target := range targets {
+		for _, configuration := range target.LaunchConfigurations() {
+			names[configuration["name"].(string)] = true
+			added = append(added, configuration)
+		}
+	}
+
+	configurations = lo.Filter(configurations, func(configuration any, _ int) bool {
+		existing, ok := configuration.(map[string]any)
+
+		return !ok || !names[fmt.Sprint(existing["name"])]
+	})
+
+	launch["configurations"] = append(configurations, added...)
+
+	contents, err := json.MarshalIndent(launch, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	contents = append(contents, '\n')
+
+	if bytes.Equal(contents, existingContents) {
+		return nil
+	}
+
+	if err := fs.MkdirAll(filepath.Dir(filePath), 0o755); err != nil {
+		return err
+	}
+
+	return afero.WriteFile(fs, filePath, contents, 0o644)
+}
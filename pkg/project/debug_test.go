@@ -0,0 +1,156 @@
+// Copyright Nitric Pty Ltd.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package project
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/spf13/afero"
+
+	"github.com/nitrictech/cli/pkg/project/localconfig"
+)
+
+func TestDebugTargets(t *testing.T) {
+	p := &Project{
+		LocalConfig: localconfig.LocalConfiguration{
+			Services: map[string]localconfig.LocalServiceConfiguration{
+				"services/api.ts": {Replicas: 2},
+			},
+		},
+		services: []Service{
+			{Name: "my-project_services-api", filepath: "services/api.ts"},
+			{Name: "my-project_services-worker", filepath: "services/worker.py"},
+			{Name: "my-project_services-report", filepath: "services/report.dart"},
+		},
+	}
+
+	targets, err := p.DebugTargets([]string{"services/*.ts", "my-project_services-worker"}, nil)
+	if err != nil {
+		t.Fatalf("DebugTargets() error = %v", err)
+	}
+
+	if len(targets) != 2 || targets[0].ServicePath != "services/api.ts" || targets[1].ServicePath != "services/worker.py" {
+		t.Fatalf("DebugTargets() = %+v, want api and worker services", targets)
+	}
+
+	if len(targets[0].HostPorts) != 2 || targets[0].HostPorts[0] == targets[0].HostPorts[1] {
+		t.Errorf("DebugTargets() host ports = %v, want a different port for each replica", targets[0].HostPorts)
+	}
+
+	if diff := cmp.Diff(map[string]string{"SOURCE_MAPS": "true"}, p.services[0].buildContext.BuildArguments); diff != "" {
+		t.Errorf("DebugTargets() typescript build arguments mismatch (-want +got):\n%s", diff)
+	}
+
+	// ports from an earlier run are kept when they are free
+	preferred := map[string]int{
+		"Nitric: attach services/api.ts[0]": targets[0].HostPorts[1],
+		"Nitric: attach services/api.ts[1]": targets[0].HostPorts[0],
+	}
+
+	targets, err = p.DebugTargets([]string{"services/api.ts"}, preferred)
+	if err != nil {
+		t.Fatalf("DebugTargets() error = %v", err)
+	}
+
+	if diff := cmp.Diff([]int{preferred["Nitric: attach services/api.ts[0]"], preferred["Nitric: attach services/api.ts[1]"]}, targets[0].HostPorts); diff != "" {
+		t.Errorf("DebugTargets() preferred host ports mismatch (-want +got):\n%s", diff)
+	}
+
+	if _, err := p.DebugTargets([]string{"services/missing.ts"}, nil); err == nil {
+		t.Errorf("DebugTargets() expected an error for a pattern matching no services")
+	}
+
+	if _, err := p.DebugTargets([]string{"services/report.dart"}, nil); err == nil {
+		t.Errorf("DebugTargets() expected an error for a runtime without a debugger")
+	}
+}
+
+func TestWriteLaunchConfigurations(t *testing.T) {
+	fs := afero.NewMemMapFs()
+
+	existing := `{
+  "version": "0.2.0",
+  "configurations": [
+    {"name": "Launch tests", "type": "node", "request": "launch"},
+    {"name": "Nitric: attach services/api.ts", "type": "node", "request": "attach", "port": 1}
+  ]
+}`
+
+	if err := afero.WriteFile(fs, ".vscode/launch.json", []byte(existing), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	p := &Project{
+		services: []Service{{Name: "api", filepath: "services/api.ts"}},
+	}
+
+	targets, err := p.DebugTargets([]string{"api"}, nil)
+	if err != nil {
+		t.Fatalf("DebugTargets() error = %v", err)
+	}
+
+	if err := WriteLaunchConfigurations(fs, ".vscode/launch.json", targets); err != nil {
+		t.Fatalf("WriteLaunchConfigurations() error = %v", err)
+	}
+
+	contents, err := afero.ReadFile(fs, ".vscode/launch.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	launch := struct {
+		Configurations []map[string]any `json:"configurations"`
+	}{}
+
+	if err := json.Unmarshal(contents, &launch); err != nil {
+		t.Fatal(err)
+	}
+
+	names := []string{}
+	for _, configuration := range launch.Configurations {
+		names = append(names, configuration["name"].(string))
+	}
+
+	if diff := cmp.Diff([]string{"Launch tests", "Nitric: attach services/api.ts"}, names); diff != "" {
+		t.Errorf("WriteLaunchConfigurations() mismatch (-want +got):\n%s", diff)
+	}
+
+	if port := launch.Configurations[1]["port"]; port != float64(targets[0].HostPorts[0]) {
+		t.Errorf("WriteLaunchConfigurations() port = %v, want %d", port, targets[0].HostPorts[0])
+	}
+
+	if diff := cmp.Diff(map[string]int{"Nitric: attach services/api.ts": targets[0].HostPorts[0]}, LaunchConfigurationPorts(fs, ".vscode/launch.json")); diff != "" {
+		t.Errorf("LaunchConfigurationPorts() mismatch (-want +got):\n%s", diff)
+	}
+
+	// an unchanged launch.json isn't rewritten
+	readOnlyFs := afero.NewReadOnlyFs(fs)
+
+	if err := WriteLaunchConfigurations(readOnlyFs, ".vscode/launch.json", targets); err != nil {
+		t.Errorf("WriteLaunchConfigurations() error = %v, want no write for unchanged configurations", err)
+	}
+
+	if err := afero.WriteFile(fs, ".vscode/launch.json", []byte("{ // comments\n}"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := WriteLaunchConfigurations(fs, ".vscode/launch.json", targets); err == nil {
+		t.Errorf("WriteLaunchConfigurations() expected an error for a launch.json that isn't valid JSON")
+	}
+}
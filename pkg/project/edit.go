@@ -0,0 +1,390 @@
+// Copyright Nitric Pty Ltd.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package project
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/afero"
+	"gopkg.in/yaml.v3"
+)
+
+const defaultYamlIndent = 2
+
+// ConfigurationEditor makes targeted edits to a nitric.yaml file.
+// Unlike ProjectConfiguration.ToFile only the edited keys are changed, the comments, key order and omitted defaults of the rest of the file are kept.
+type ConfigurationEditor struct {
+	fs     afero.Fs
+	path   string
+	doc    *yaml.Node
+	indent int
+}
+
+// EditConfigurationFile loads a nitric.yaml file for editing, changes are written with Save
+func EditConfigurationFile(fs afero.Fs, filePath string) (*ConfigurationEditor, error) {
+	if filePath == "" {
+		filePath = defaultNitricYamlPath
+	}
+
+	contents, err := afero.ReadFile(fs, filePath)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read nitric.yaml: %w", err)
+	}
+
+	doc := &yaml.Node{}
+
+	if err := yaml.Unmarshal(contents, doc); err != nil {
+		return nil, fmt.Errorf("unable to parse nitric.yaml: %w", err)
+	}
+
+	// an empty file has no content, start from an empty mapping
+	if doc.Kind == 0 {
+		doc = &yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode, Tag: "!!map"}}}
+	}
+
+	if len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("unable to edit nitric.yaml: expected a mapping at the root of the document")
+	}
+
+	return &ConfigurationEditor{
+		fs:     fs,
+		path:   filePath,
+		doc:    doc,
+		indent: detectIndent(contents),
+	}, nil
+}
+
+// detectIndent returns the smallest indentation used in the file, so edits match the existing formatting
+func detectIndent(contents []byte) int {
+	indent := 0
+
+	for _, line := range strings.Split(string(contents), "\n") {
+		trimmed := strings.TrimLeft(line, " ")
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+
+		if spaces := len(line) - len(trimmed); spaces > 0 && (indent == 0 || spaces < indent) {
+			indent = spaces
+		}
+	}
+
+	if indent < 2 {
+		return defaultYamlIndent
+	}
+
+	return indent
+}
+
+// splitPath splits a dot separated key path, e.g. lint.rules or websites.0.path
+func splitPath(path string) ([]string, error) {
+	if path == "" {
+		return nil, fmt.Errorf("a key path is required")
+	}
+
+	return strings.Split(path, "."), nil
+}
+
+// lookup returns the node at the key path, creating missing mappings along the way when create is true
+func (e *ConfigurationEditor) lookup(path string, create bool) (*yaml.Node, error) {
+	keys, err := splitPath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	node := e.doc.Content[0]
+
+	for i, key := range keys {
+		switch node.Kind {
+		case yaml.MappingNode:
+			var next *yaml.Node
+
+			for j := 0; j < len(node.Content)-1; j += 2 {
+				if node.Content[j].Value == key {
+					next = node.Content[j+1]
+					break
+				}
+			}
+
+			if next == nil {
+				if !create {
+					return nil, nil
+				}
+
+				next = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+				node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}, next)
+			}
+
+			// an empty key, e.g. "services:", is parsed as null
+			if create && i < len(keys)-1 && isNull(next) {
+				next.Kind = yaml.MappingNode
+				next.Tag = "!!map"
+				next.Value = ""
+			}
+
+			node = next
+		case yaml.SequenceNode:
+			index, err := strconv.Atoi(key)
+			if err != nil || index < 0 || index >= len(node.Content) {
+				return nil, fmt.Errorf("invalid index %s for %s", key, strings.Join(keys[:i], "."))
+			}
+
+			node = node.Content[index]
+		default:
+			return nil, fmt.Errorf("unable to edit %s, %s is not a mapping or list", path, strings.Join(keys[:i], "."))
+		}
+	}
+
+	return node, nil
+}
+
+func isNull(node *yaml.Node) bool {
+	return node.Kind == yaml.ScalarNode && node.Tag == "!!null"
+}
+
+func isEmpty(node *yaml.Node) bool {
+	switch node.Kind {
+	case yaml.ScalarNode:
+		return node.Value == "" || isNull(node)
+	case yaml.MappingNode, yaml.SequenceNode:
+		return len(node.Content) == 0
+	default:
+		return false
+	}
+}
+
+// Set sets the value at the key path. Existing mappings and lists are merged with the new value so comments on unchanged entries are kept.
+func (e *ConfigurationEditor) Set(path string, value any) error {
+	node, err := e.lookup(path, true)
+	if err != nil {
+		return err
+	}
+
+	valueNode := &yaml.Node{}
+
+	if err := valueNode.Encode(value); err != nil {
+		return err
+	}
+
+	mergeNode(node, valueNode)
+
+	return nil
+}
+
+// Append appends a value to the list at the key path, the list is created if it doesn't exist
+func (e *ConfigurationEditor) Append(path string, value any) error {
+	node, err := e.lookup(path, true)
+	if err != nil {
+		return err
+	}
+
+	// new keys are created as mappings, an empty key is parsed as null
+	if (node.Kind == yaml.MappingNode && len(node.Content) == 0) || isNull(node) {
+		node.Kind = yaml.SequenceNode
+		node.Tag = "!!seq"
+		node.Value = ""
+	}
+
+	if node.Kind != yaml.SequenceNode {
+		return fmt.Errorf("unable to append to %s, it is not a list", path)
+	}
+
+	valueNode := &yaml.Node{}
+
+	if err := valueNode.Encode(value); err != nil {
+		return err
+	}
+
+	// a new entry has nothing to merge with, drop its empty defaults so they aren't expanded into the file
+	pruneEmpty(valueNode)
+
+	node.Content = append(node.Content, valueNode)
+
+	return nil
+}
+
+// Delete removes the key at the key path, it is not an error if the key doesn't exist
+func (e *ConfigurationEditor) Delete(path string) error {
+	keys, err := splitPath(path)
+	if err != nil {
+		return err
+	}
+
+	parent := e.doc.Content[0]
+
+	if len(keys) > 1 {
+		parent, err = e.lookup(strings.Join(keys[:len(keys)-1], "."), false)
+		if err != nil || parent == nil {
+			return err
+		}
+	}
+
+	key := keys[len(keys)-1]
+
+	switch parent.Kind {
+	case yaml.MappingNode:
+		for j := 0; j < len(parent.Content)-1; j += 2 {
+			if parent.Content[j].Value == key {
+				parent.Content = append(parent.Content[:j], parent.Content[j+2:]...)
+				break
+			}
+		}
+	case yaml.SequenceNode:
+		index, err := strconv.Atoi(key)
+		if err != nil || index < 0 || index >= len(parent.Content) {
+			return fmt.Errorf("invalid index %s for %s", key, path)
+		}
+
+		parent.Content = append(parent.Content[:index], parent.Content[index+1:]...)
+	}
+
+	return nil
+}
+
+// pruneEmpty removes the keys with empty values from the mappings in node, e.g. the defaults of an encoded struct
+func pruneEmpty(node *yaml.Node) {
+	switch node.Kind {
+	case yaml.MappingNode:
+		content := []*yaml.Node{}
+
+		for i := 0; i < len(node.Content)-1; i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+
+			pruneEmpty(value)
+
+			if isEmpty(value) {
+				continue
+			}
+
+			content = append(content, key, value)
+		}
+
+		node.Content = content
+	case yaml.SequenceNode:
+		for _, value := range node.Content {
+			pruneEmpty(value)
+		}
+	}
+}
+
+// mergeNode updates dst in place to the value of src, keeping the comments and style of dst where the structure is unchanged
+func mergeNode(dst *yaml.Node, src *yaml.Node) {
+	switch {
+	case dst.Kind == yaml.MappingNode && src.Kind == yaml.MappingNode:
+		content := []*yaml.Node{}
+
+		for i := 0; i < len(src.Content)-1; i += 2 {
+			key, value := src.Content[i], src.Content[i+1]
+			found := false
+
+			for j := 0; j < len(dst.Content)-1; j += 2 {
+				if dst.Content[j].Value == key.Value {
+					key = dst.Content[j]
+
+					mergeNode(dst.Content[j+1], value)
+					value = dst.Content[j+1]
+					found = true
+
+					break
+				}
+			}
+
+			// don't expand defaults, e.g. an empty start command, into keys the file doesn't already have
+			if !found {
+				pruneEmpty(value)
+
+				if isEmpty(value) {
+					continue
+				}
+			}
+
+			content = append(content, key, value)
+		}
+
+		// keep the existing key order, new keys are added at the end
+		dst.Content = orderLike(dst.Content, content)
+	case dst.Kind == yaml.SequenceNode && src.Kind == yaml.SequenceNode:
+		for i, value := range src.Content {
+			if i < len(dst.Content) {
+				mergeNode(dst.Content[i], value)
+			} else {
+				pruneEmpty(value)
+				dst.Content = append(dst.Content, value)
+			}
+		}
+
+		dst.Content = dst.Content[:len(src.Content)]
+	default:
+		headComment, lineComment, footComment := dst.HeadComment, dst.LineComment, dst.FootComment
+		style := dst.Style
+
+		*dst = *src
+
+		dst.HeadComment, dst.LineComment, dst.FootComment = headComment, lineComment, footComment
+
+		if src.Kind == yaml.ScalarNode && style&(yaml.DoubleQuotedStyle|yaml.SingleQuotedStyle) != 0 && src.Tag == "!!str" {
+			dst.Style = style
+		}
+	}
+}
+
+// orderLike orders the key value pairs of merged in the order they appear in original, pairs not in original are kept at the end
+func orderLike(original []*yaml.Node, merged []*yaml.Node) []*yaml.Node {
+	ordered := []*yaml.Node{}
+	added := map[string]bool{}
+
+	for i := 0; i < len(original)-1; i += 2 {
+		for j := 0; j < len(merged)-1; j += 2 {
+			if merged[j].Value == original[i].Value {
+				ordered = append(ordered, merged[j], merged[j+1])
+				added[merged[j].Value] = true
+
+				break
+			}
+		}
+	}
+
+	for j := 0; j < len(merged)-1; j += 2 {
+		if !added[merged[j].Value] {
+			ordered = append(ordered, merged[j], merged[j+1])
+		}
+	}
+
+	return ordered
+}
+
+// Save writes the edited configuration back to the file
+func (e *ConfigurationEditor) Save() error {
+	var out bytes.Buffer
+
+	encoder := yaml.NewEncoder(&out)
+	encoder.SetIndent(e.indent)
+
+	if err := encoder.Encode(e.doc); err != nil {
+		return err
+	}
+
+	if err := encoder.Close(); err != nil {
+		return err
+	}
+
+	return afero.WriteFile(e.fs, e.path, out.Bytes(), os.ModePerm)
+}
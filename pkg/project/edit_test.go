@@ -0,0 +1,173 @@
+// Copyright Nitric Pty Ltd.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package project
+
+import (
+	"testing"
+
+	"github.com/spf13/afero"
+)
+
+const editTestConfig = `# project comment
+name: my-project # the project name
+services:
+    # api services
+    - match: services/*.ts
+      start: npm run dev:services $SERVICE_PATH
+websites:
+lint:
+    rules:
+        # too noisy
+        unused-resource: "off"
+`
+
+func TestConfigurationEditor(t *testing.T) {
+	tests := []struct {
+		name string
+		edit func(e *ConfigurationEditor) error
+		want string
+	}{
+		{
+			name: "set scalar",
+			edit: func(e *ConfigurationEditor) error {
+				return e.Set("name", "renamed")
+			},
+			want: `# project comment
+name: renamed # the project name
+services:
+    # api services
+    - match: services/*.ts
+      start: npm run dev:services $SERVICE_PATH
+websites:
+lint:
+    rules:
+        # too noisy
+        unused-resource: "off"
+`,
+		},
+		{
+			name: "append to null list",
+			edit: func(e *ConfigurationEditor) error {
+				return e.Append("websites", WebsiteConfiguration{Basedir: "./web", Build: Build{Command: "npm run build", Output: "dist"}})
+			},
+			want: `# project comment
+name: my-project # the project name
+services:
+    # api services
+    - match: services/*.ts
+      start: npm run dev:services $SERVICE_PATH
+websites:
+    - basedir: ./web
+      build:
+        command: npm run build
+        output: dist
+lint:
+    rules:
+        # too noisy
+        unused-resource: "off"
+`,
+		},
+		{
+			name: "merge existing entry without expanding defaults",
+			edit: func(e *ConfigurationEditor) error {
+				return e.Set("services.0", BaseServiceConfiguration{Match: "services/*.js", Start: "node $SERVICE_PATH"})
+			},
+			want: `# project comment
+name: my-project # the project name
+services:
+    # api services
+    - match: services/*.js
+      start: node $SERVICE_PATH
+websites:
+lint:
+    rules:
+        # too noisy
+        unused-resource: "off"
+`,
+		},
+		{
+			name: "append without expanding nested defaults",
+			edit: func(e *ConfigurationEditor) error {
+				return e.Append("services", BaseServiceConfiguration{Match: "jobs/*.ts"})
+			},
+			want: `# project comment
+name: my-project # the project name
+services:
+    # api services
+    - match: services/*.ts
+      start: npm run dev:services $SERVICE_PATH
+    - match: jobs/*.ts
+websites:
+lint:
+    rules:
+        # too noisy
+        unused-resource: "off"
+`,
+		},
+		{
+			name: "set nested key and delete",
+			edit: func(e *ConfigurationEditor) error {
+				if err := e.Set("lint.rules.missing-handler", "error"); err != nil {
+					return err
+				}
+
+				return e.Delete("websites")
+			},
+			want: `# project comment
+name: my-project # the project name
+services:
+    # api services
+    - match: services/*.ts
+      start: npm run dev:services $SERVICE_PATH
+lint:
+    rules:
+        # too noisy
+        unused-resource: "off"
+        missing-handler: error
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fs := afero.NewMemMapFs()
+
+			if err := afero.WriteFile(fs, "nitric.yaml", []byte(editTestConfig), 0o600); err != nil {
+				t.Fatal(err)
+			}
+
+			editor, err := EditConfigurationFile(fs, "nitric.yaml")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if err := tt.edit(editor); err != nil {
+				t.Fatal(err)
+			}
+
+			if err := editor.Save(); err != nil {
+				t.Fatal(err)
+			}
+
+			got, _ := afero.ReadFile(fs, "nitric.yaml")
+
+			if string(got) != tt.want {
+				t.Errorf("edited nitric.yaml =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
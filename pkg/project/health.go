@@ -0,0 +1,151 @@
+// Copyright Nitric Pty Ltd.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package project
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/pkg/stdcopy"
+
+	"github.com/nitrictech/cli/pkg/cloud/health"
+	"github.com/nitrictech/cli/pkg/docker"
+	"github.com/nitrictech/cli/pkg/project/localconfig"
+)
+
+// healthProbe runs a single health check of a service container, returning an error if the check fails
+type healthProbe func(ctx context.Context) error
+
+// httpHealthProbe requests a path on the HTTP proxy port of the container, failing on an error response
+func httpHealthProbe(hostPort string, path string) healthProbe {
+	return func(ctx context.Context) error {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("http://localhost:%s%s", hostPort, path), nil)
+		if err != nil {
+			return err
+		}
+
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode >= http.StatusBadRequest {
+			return fmt.Errorf("GET %s responded with %s", path, resp.Status)
+		}
+
+		return nil
+	}
+}
+
+// commandHealthProbe runs a command in the container, failing if it exits with a non 0 status
+func commandHealthProbe(dockerClient *docker.Docker, containerId string, command []string) healthProbe {
+	return func(ctx context.Context) error {
+		exec, err := dockerClient.ContainerExecCreate(ctx, containerId, types.ExecConfig{
+			Cmd:          command,
+			AttachStdout: true,
+			AttachStderr: true,
+		})
+		if err != nil {
+			return err
+		}
+
+		attachResponse, err := dockerClient.ContainerExecAttach(ctx, exec.ID, types.ExecStartCheck{})
+		if err != nil {
+			return err
+		}
+		defer attachResponse.Close()
+
+		if deadline, ok := ctx.Deadline(); ok {
+			_ = attachResponse.Conn.SetDeadline(deadline)
+		}
+
+		var output bytes.Buffer
+		if _, err := stdcopy.StdCopy(&output, &output, attachResponse.Reader); err != nil {
+			return fmt.Errorf("%s did not complete: %w", strings.Join(command, " "), err)
+		}
+
+		inspect, err := dockerClient.ContainerExecInspect(ctx, exec.ID)
+		if err != nil {
+			return err
+		}
+
+		if inspect.ExitCode != 0 {
+			return fmt.Errorf("%s exited with status %d: %s", strings.Join(command, " "), inspect.ExitCode, strings.TrimSpace(output.String()))
+		}
+
+		return nil
+	}
+}
+
+// monitorHealth runs a health check on an interval until the context is done, reporting each change of the health of the container.
+// A container is starting until its first check passes, reporting the error of its last check while it starts.
+// It is unhealthy once the configured number of consecutive checks fail, after it was healthy or after its start period
+func monitorHealth(ctx context.Context, check *localconfig.LocalServiceHealthCheck, probe healthProbe, report func(status health.Status, message string)) {
+	status := health.Status_Starting
+	message := ""
+	failures := 0
+	startDeadline := time.Now().Add(check.StartPeriodDuration())
+
+	ticker := time.NewTicker(check.IntervalDuration())
+	defer ticker.Stop()
+
+	for {
+		probeCtx, cancel := context.WithTimeout(ctx, check.TimeoutDuration())
+		err := probe(probeCtx)
+
+		cancel()
+
+		if ctx.Err() != nil {
+			return
+		}
+
+		if err == nil {
+			failures = 0
+
+			if status != health.Status_Healthy {
+				status = health.Status_Healthy
+				message = ""
+				report(status, message)
+			}
+		} else {
+			failures++
+
+			switch {
+			case status == health.Status_Unhealthy:
+			case failures >= check.RetryCount() && (status == health.Status_Healthy || time.Now().After(startDeadline)):
+				status = health.Status_Unhealthy
+				message = err.Error()
+				report(status, message)
+			case status == health.Status_Starting && err.Error() != message:
+				message = err.Error()
+				report(status, message)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,100 @@
+// Copyright Nitric Pty Ltd.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package project
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/nitrictech/cli/pkg/cloud/health"
+	"github.com/nitrictech/cli/pkg/project/localconfig"
+)
+
+func TestMonitorHealth(t *testing.T) {
+	type report struct {
+		Status  health.Status
+		Message string
+	}
+
+	tests := []struct {
+		name    string
+		check   localconfig.LocalServiceHealthCheck
+		results []bool
+		want    []report
+	}{
+		{
+			// a check fails while the service warms up, passes, then fails until the service is unhealthy and recovers
+			name:    "unhealthy after passing",
+			check:   localconfig.LocalServiceHealthCheck{Interval: "1ms", Retries: 2},
+			results: []bool{false, false, true, false, true, false, false, true},
+			want: []report{
+				{Status: health.Status_Starting, Message: "check 1 failed"},
+				{Status: health.Status_Starting, Message: "check 2 failed"},
+				{Status: health.Status_Healthy},
+				{Status: health.Status_Unhealthy, Message: "check 7 failed"},
+				{Status: health.Status_Healthy},
+			},
+		},
+		{
+			name:    "unhealthy after the start period",
+			check:   localconfig.LocalServiceHealthCheck{Interval: "1ms", Retries: 2, StartPeriod: "1ns"},
+			results: []bool{false, false, false, true},
+			want: []report{
+				{Status: health.Status_Starting, Message: "check 1 failed"},
+				{Status: health.Status_Unhealthy, Message: "check 2 failed"},
+				{Status: health.Status_Healthy},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			checks := 0
+			probe := func(ctx context.Context) error {
+				if checks == len(tt.results) {
+					cancel()
+					return nil
+				}
+
+				passed := tt.results[checks]
+				checks++
+
+				if !passed {
+					return fmt.Errorf("check %d failed", checks)
+				}
+
+				return nil
+			}
+
+			reports := []report{}
+
+			monitorHealth(ctx, &tt.check, probe, func(status health.Status, message string) {
+				reports = append(reports, report{Status: status, Message: message})
+			})
+
+			if diff := cmp.Diff(tt.want, reports); diff != "" {
+				t.Errorf("monitorHealth() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
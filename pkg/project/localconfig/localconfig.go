@@ -19,9 +19,20 @@ package localconfig
 import (
 	"fmt"
 	"os"
+	"path"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
 
+	"github.com/docker/go-units"
+	"github.com/samber/lo"
 	"github.com/spf13/afero"
 	"gopkg.in/yaml.v3"
+
+	"github.com/nitrictech/cli/pkg/project/yamlschema"
 )
 
 type LocalResourceConfiguration struct {
@@ -29,13 +40,220 @@ type LocalResourceConfiguration struct {
 	Port int `yaml:"port"`
 }
 
+type LocalApiConfiguration struct {
+	LocalResourceConfiguration `yaml:",inline"`
+	// Contract is an optional path to an OpenAPI document used to validate requests and responses
+	// when contract validation is enabled, the generated document is used when empty. Relative paths are resolved against the project directory
+	Contract string `yaml:"contract,omitempty"`
+}
+
+type LocalWebsocketConfiguration struct {
+	LocalResourceConfiguration `yaml:",inline"`
+	// Binary passes binary messages through as binary frames, only enable this for providers that support them
+	Binary bool `yaml:"binary,omitempty"`
+}
+
+type LocalSqlDatabaseConfiguration struct {
+	// Extensions are created in the database when it is created, in addition to the extensions of all databases
+	Extensions []string `yaml:"extensions,omitempty"`
+	// InitScripts are paths to SQL files run in the database when it is created, after the scripts of all databases
+	InitScripts []string `yaml:"initScripts,omitempty"`
+}
+
+type LocalSqlConfiguration struct {
+	// Image is the postgres compatible image of the local database server, e.g. pgvector/pgvector or postgis/postgis
+	Image string `yaml:"image,omitempty"`
+	// Version is the tag of the image
+	Version string `yaml:"version,omitempty"`
+	// Username and Password are the credentials of the local database server, they are only applied when its volume is first created
+	// Changing them afterwards is reported when the server starts, the volume has to be removed to recreate the databases with new credentials
+	Username string `yaml:"username,omitempty"`
+	Password string `yaml:"password,omitempty"`
+	// Extensions and InitScripts apply to all databases
+	LocalSqlDatabaseConfiguration `yaml:",inline"`
+	// Databases configures individual databases by name
+	Databases map[string]LocalSqlDatabaseConfiguration `yaml:"databases,omitempty"`
+	// BranchSnapshots keeps a snapshot of the local databases for each git branch, swapping them when the branch changes
+	BranchSnapshots bool `yaml:"branchSnapshots,omitempty"`
+}
+
+var majorVersionRegex = regexp.MustCompile(`\d+`)
+
+// MajorVersion returns the postgres major version of the configured image tag, e.g. 16 for 16.4 or pg16, it is empty when the tag has no version
+func (c LocalSqlConfiguration) MajorVersion() string {
+	return majorVersionRegex.FindString(c.Version)
+}
+
+type LocalServiceConfiguration struct {
+	// Cpu is the number of CPUs available to each container of the service, e.g. 0.5
+	Cpu float64 `yaml:"cpu,omitempty"`
+	// Memory is the memory limit of each container of the service, e.g. 512m or 1g, containers exceeding it are OOM killed
+	Memory string `yaml:"memory,omitempty"`
+	// Replicas is the number of containers run for the service, requests and topic deliveries are load balanced across them
+	Replicas int `yaml:"replicas,omitempty"`
+	// HealthCheck is used to hold back requests and schedules from each container of the service until it is ready
+	HealthCheck *LocalServiceHealthCheck `yaml:"healthcheck,omitempty"`
+}
+
+type LocalServiceHealthCheck struct {
+	// Path is requested with GET on the HTTP proxy port of the service, it is healthy on a 2xx or 3xx response, e.g. /healthz
+	Path string `yaml:"path,omitempty"`
+	// Command is run in the container of the service, it is healthy when the command exits with 0
+	Command []string `yaml:"command,omitempty"`
+	// Interval is the time between checks, defaults to 1s
+	Interval string `yaml:"interval,omitempty"`
+	// Timeout is the time a check can take before it fails, defaults to 5s
+	Timeout string `yaml:"timeout,omitempty"`
+	// Retries is the number of consecutive failed checks before a ready service is unhealthy, defaults to 3
+	Retries int `yaml:"retries,omitempty"`
+	// StartPeriod is the time a service can take to pass its first check, after it the service is unhealthy once its checks fail, defaults to 30s
+	StartPeriod string `yaml:"startPeriod,omitempty"`
+}
+
+// IntervalDuration - returns the time between checks
+func (h LocalServiceHealthCheck) IntervalDuration() time.Duration {
+	interval, err := time.ParseDuration(h.Interval)
+	if err != nil || interval <= 0 {
+		return time.Second
+	}
+
+	return interval
+}
+
+// TimeoutDuration - returns the time a check can take before it fails
+func (h LocalServiceHealthCheck) TimeoutDuration() time.Duration {
+	timeout, err := time.ParseDuration(h.Timeout)
+	if err != nil || timeout <= 0 {
+		return 5 * time.Second
+	}
+
+	return timeout
+}
+
+// StartPeriodDuration - returns the time a service can take to pass its first check
+func (h LocalServiceHealthCheck) StartPeriodDuration() time.Duration {
+	startPeriod, err := time.ParseDuration(h.StartPeriod)
+	if err != nil || startPeriod <= 0 {
+		return 30 * time.Second
+	}
+
+	return startPeriod
+}
+
+// RetryCount - returns the number of consecutive failed checks before a ready service is unhealthy
+func (h LocalServiceHealthCheck) RetryCount() int {
+	if h.Retries <= 0 {
+		return 3
+	}
+
+	return h.Retries
+}
+
+func (h LocalServiceHealthCheck) validate() error {
+	if (h.Path == "") == (len(h.Command) == 0) {
+		return fmt.Errorf("healthcheck requires either a path or a command")
+	}
+
+	if h.Path != "" && !strings.HasPrefix(h.Path, "/") {
+		return fmt.Errorf("healthcheck path %q must start with /", h.Path)
+	}
+
+	for _, duration := range []string{h.Interval, h.Timeout, h.StartPeriod} {
+		if duration == "" {
+			continue
+		}
+
+		if _, err := time.ParseDuration(duration); err != nil {
+			return fmt.Errorf("invalid healthcheck duration %q: %w", duration, err)
+		}
+	}
+
+	if h.Retries < 0 {
+		return fmt.Errorf("healthcheck retries can't be negative")
+	}
+
+	return nil
+}
+
+type LocalContainerHealthCheck struct {
+	// Command is run in the container to check its health, it is healthy when the command exits with 0, e.g. [redis-cli, ping]
+	Command []string `yaml:"command"`
+	// Interval is the time between checks, e.g. 5s
+	Interval string `yaml:"interval,omitempty"`
+	// Timeout is the time a check can take before it fails
+	Timeout string `yaml:"timeout,omitempty"`
+	// Retries is the number of consecutive failed checks before the container is unhealthy
+	Retries int `yaml:"retries,omitempty"`
+}
+
+type LocalContainerConfiguration struct {
+	// Image is the image of the container, e.g. redis:7
+	Image string `yaml:"image"`
+	// Command overrides the command of the image
+	Command []string `yaml:"command,omitempty"`
+	// Env is the environment of the container
+	Env map[string]string `yaml:"env,omitempty"`
+	// Ports are the container ports published on the host, each is published on the first free host port from the same number
+	Ports []int `yaml:"ports,omitempty"`
+	// Volumes are mounted in the container as source:target[:ro], sources starting with . or / are bind mounted, other sources are named volumes
+	Volumes []string `yaml:"volumes,omitempty"`
+	// HealthCheck is used to wait for the container to be ready before services start
+	HealthCheck *LocalContainerHealthCheck `yaml:"healthcheck,omitempty"`
+	// ServiceEnv is added to the environment of services, ${HOST}, ${PORT} and ${PORT_<container port>} are replaced with the container address
+	ServiceEnv map[string]string `yaml:"serviceEnv,omitempty"`
+}
+
 type LocalConfiguration struct {
-	Apis       map[string]LocalResourceConfiguration `yaml:"apis"`
-	Websockets map[string]LocalResourceConfiguration `yaml:"websockets"`
+	Apis       map[string]LocalApiConfiguration       `yaml:"apis"`
+	Websockets map[string]LocalWebsocketConfiguration `yaml:"websockets"`
+	Sql        LocalSqlConfiguration                  `yaml:"sql,omitempty"`
+	// Services configures the containers of services run by nitric run, by service file path or a glob pattern matching it
+	Services map[string]LocalServiceConfiguration `yaml:"services,omitempty"`
+	// Containers are auxiliary containers run alongside the project by nitric run and nitric start, e.g. redis or a mail server, by name
+	Containers map[string]LocalContainerConfiguration `yaml:"containers,omitempty"`
+}
+
+// Service returns the configuration of a service by its file path, an exact match is preferred over glob patterns
+func (c LocalConfiguration) Service(servicePath string) LocalServiceConfiguration {
+	servicePath = filepath.ToSlash(servicePath)
+
+	if config, ok := c.Services[servicePath]; ok {
+		return config
+	}
+
+	patterns := lo.Keys(c.Services)
+	slices.Sort(patterns)
+
+	for _, pattern := range patterns {
+		if matched, _ := path.Match(pattern, servicePath); matched {
+			return c.Services[pattern]
+		}
+	}
+
+	return LocalServiceConfiguration{}
+}
+
+// MemoryBytes returns the memory limit in bytes, it is zero when no limit is configured
+func (c LocalServiceConfiguration) MemoryBytes() (int64, error) {
+	if c.Memory == "" {
+		return 0, nil
+	}
+
+	bytes, err := units.RAMInBytes(c.Memory)
+	if err != nil {
+		return 0, fmt.Errorf("invalid memory %q: %w", c.Memory, err)
+	}
+
+	return bytes, nil
 }
 
 const defaultLocalNitricYamlPath = "./local.nitric.yaml"
 
+// LocalConfigurationSchema returns the JSON Schema of the local.nitric.yaml file
+func LocalConfigurationSchema() *yamlschema.Schema {
+	return yamlschema.Generate(reflect.TypeOf(LocalConfiguration{})).Root("local.nitric.yaml")
+}
+
 func LocalConfigurationFromFile(fs afero.Fs, filePath string) (*LocalConfiguration, error) {
 	if filePath == "" {
 		filePath = defaultLocalNitricYamlPath
@@ -67,5 +285,67 @@ func LocalConfigurationFromFile(fs afero.Fs, filePath string) (*LocalConfigurati
 		return nil, fmt.Errorf("unable to parse local.nitric.yaml: %w", err)
 	}
 
+	if err := yamlschema.Validate(localConfigFileContents, LocalConfigurationSchema()); err != nil {
+		return nil, fmt.Errorf("invalid local.nitric.yaml:\n%w", err)
+	}
+
+	for servicePath, serviceConfig := range localConfig.Services {
+		if _, err := serviceConfig.MemoryBytes(); err != nil {
+			return nil, fmt.Errorf("invalid local.nitric.yaml: service %s: %w", servicePath, err)
+		}
+
+		if serviceConfig.Cpu < 0 || serviceConfig.Replicas < 0 {
+			return nil, fmt.Errorf("invalid local.nitric.yaml: service %s: cpu and replicas can't be negative", servicePath)
+		}
+
+		if serviceConfig.HealthCheck != nil {
+			if err := serviceConfig.HealthCheck.validate(); err != nil {
+				return nil, fmt.Errorf("invalid local.nitric.yaml: service %s: %w", servicePath, err)
+			}
+		}
+	}
+
+	for name, containerConfig := range localConfig.Containers {
+		if err := containerConfig.validate(); err != nil {
+			return nil, fmt.Errorf("invalid local.nitric.yaml: container %s: %w", name, err)
+		}
+	}
+
 	return localConfig, nil
 }
+
+func (c LocalContainerConfiguration) validate() error {
+	if c.Image == "" {
+		return fmt.Errorf("image is required")
+	}
+
+	for _, port := range c.Ports {
+		if port < 1 || port > 65535 {
+			return fmt.Errorf("invalid port %d", port)
+		}
+	}
+
+	for _, volume := range c.Volumes {
+		if parts := strings.Split(volume, ":"); len(parts) < 2 || len(parts) > 3 || parts[0] == "" || parts[1] == "" {
+			return fmt.Errorf("invalid volume %q, expected source:target[:ro]", volume)
+		}
+	}
+
+	if c.HealthCheck != nil {
+		if len(c.HealthCheck.Command) == 0 {
+			return fmt.Errorf("healthcheck command is required")
+		}
+
+		for _, duration := range []string{c.HealthCheck.Interval, c.HealthCheck.Timeout} {
+			if duration == "" {
+				continue
+			}
+
+			if _, err := time.ParseDuration(duration); err != nil {
+				return fmt.Errorf("invalid healthcheck duration %q: %w", duration, err)
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,144 @@
+// Copyright Nitric Pty Ltd.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package localconfig
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/spf13/afero"
+)
+
+func TestService(t *testing.T) {
+	config := LocalConfiguration{
+		Services: map[string]LocalServiceConfiguration{
+			"services/*.ts":      {Replicas: 2},
+			"services/api.ts":    {Replicas: 3, Cpu: 0.5, Memory: "256m"},
+			"services/jobs/*.py": {Memory: "1g"},
+		},
+	}
+
+	for _, tt := range []struct {
+		servicePath string
+		expected    LocalServiceConfiguration
+	}{
+		{servicePath: "services/api.ts", expected: LocalServiceConfiguration{Replicas: 3, Cpu: 0.5, Memory: "256m"}},
+		{servicePath: "services/worker.ts", expected: LocalServiceConfiguration{Replicas: 2}},
+		{servicePath: "services/jobs/report.py", expected: LocalServiceConfiguration{Memory: "1g"}},
+		{servicePath: "other/main.go", expected: LocalServiceConfiguration{}},
+	} {
+		t.Run(tt.servicePath, func(t *testing.T) {
+			if diff := cmp.Diff(tt.expected, config.Service(tt.servicePath)); diff != "" {
+				t.Errorf("Service() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestServiceValidation(t *testing.T) {
+	for _, tt := range []struct {
+		name    string
+		yaml    string
+		wantErr bool
+	}{
+		{name: "valid", yaml: "services:\n  services/*.ts:\n    cpu: 1.5\n    memory: 512m\n    replicas: 2\n"},
+		{name: "invalid memory", yaml: "services:\n  services/*.ts:\n    memory: lots\n", wantErr: true},
+		{name: "negative replicas", yaml: "services:\n  services/*.ts:\n    replicas: -1\n", wantErr: true},
+		{name: "valid healthcheck", yaml: "services:\n  services/*.ts:\n    memory: 512m\n    healthcheck:\n      path: /healthz\n      interval: 500ms\n"},
+		{name: "healthcheck without path or command", yaml: "services:\n  services/*.ts:\n    healthcheck:\n      interval: 1s\n", wantErr: true},
+		{name: "healthcheck with path and command", yaml: "services:\n  services/*.ts:\n    healthcheck:\n      path: /healthz\n      command: [\"true\"]\n", wantErr: true},
+		{name: "healthcheck path without leading slash", yaml: "services:\n  services/*.ts:\n    healthcheck:\n      path: healthz\n", wantErr: true},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			fs := afero.NewMemMapFs()
+
+			if err := afero.WriteFile(fs, "local.nitric.yaml", []byte(tt.yaml), 0o644); err != nil {
+				t.Fatal(err)
+			}
+
+			config, err := LocalConfigurationFromFile(fs, "local.nitric.yaml")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("LocalConfigurationFromFile() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr {
+				return
+			}
+
+			memory, err := config.Service("services/api.ts").MemoryBytes()
+			if err != nil || memory != 512*1024*1024 {
+				t.Errorf("MemoryBytes() = %d, %v, want %d", memory, err, 512*1024*1024)
+			}
+		})
+	}
+}
+
+func TestContainerValidation(t *testing.T) {
+	for _, tt := range []struct {
+		name    string
+		yaml    string
+		wantErr bool
+	}{
+		{name: "valid", yaml: "containers:\n  redis:\n    image: redis:7\n    ports: [6379]\n    volumes: [\"redis-data:/data\", \"./seed:/seed:ro\"]\n    healthcheck:\n      command: [redis-cli, ping]\n      interval: 2s\n"},
+		{name: "missing image", yaml: "containers:\n  redis:\n    ports: [6379]\n", wantErr: true},
+		{name: "invalid port", yaml: "containers:\n  redis:\n    image: redis:7\n    ports: [70000]\n", wantErr: true},
+		{name: "invalid volume", yaml: "containers:\n  redis:\n    image: redis:7\n    volumes: [\"/data\"]\n", wantErr: true},
+		{name: "invalid healthcheck interval", yaml: "containers:\n  redis:\n    image: redis:7\n    healthcheck:\n      command: [redis-cli, ping]\n      interval: often\n", wantErr: true},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			fs := afero.NewMemMapFs()
+
+			if err := afero.WriteFile(fs, "local.nitric.yaml", []byte(tt.yaml), 0o644); err != nil {
+				t.Fatal(err)
+			}
+
+			_, err := LocalConfigurationFromFile(fs, "local.nitric.yaml")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("LocalConfigurationFromFile() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestWebsocketConfiguration(t *testing.T) {
+	for _, tt := range []struct {
+		name     string
+		yaml     string
+		expected map[string]LocalWebsocketConfiguration
+	}{
+		{name: "not configured", yaml: "services: {}\n"},
+		{name: "binary", yaml: "websockets:\n  chat:\n    binary: true\n", expected: map[string]LocalWebsocketConfiguration{"chat": {Binary: true}}},
+		{name: "text only", yaml: "websockets:\n  chat:\n    binary: false\n", expected: map[string]LocalWebsocketConfiguration{"chat": {}}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			fs := afero.NewMemMapFs()
+
+			if err := afero.WriteFile(fs, "local.nitric.yaml", []byte(tt.yaml), 0o644); err != nil {
+				t.Fatal(err)
+			}
+
+			config, err := LocalConfigurationFromFile(fs, "local.nitric.yaml")
+			if err != nil {
+				t.Fatalf("LocalConfigurationFromFile() error = %v", err)
+			}
+
+			if diff := cmp.Diff(tt.expected, config.Websockets); diff != "" {
+				t.Errorf("Websockets mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
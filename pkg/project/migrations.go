@@ -17,15 +17,22 @@
 package project
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"os"
+	"path/filepath"
+	"regexp"
 	goruntime "runtime"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/pkg/stdcopy"
+	"github.com/google/uuid"
 	"github.com/spf13/afero"
 
 	"github.com/nitrictech/cli/pkg/cloud/sql"
@@ -50,28 +57,42 @@ func migrationImageName(dbName string) string {
 	return fmt.Sprintf("%s-migrations", dbName)
 }
 
-func BuildAndRunMigrations(fs afero.Fs, servers map[string]*sql.DatabaseServer, databasesToMigrate map[string]*resourcespb.SqlDatabaseResource, useBuilder bool) error {
-	serviceRequirements := collector.MakeDatabaseServiceRequirements(databasesToMigrate)
+// BuildDatabaseMigrationImages builds the migration images of the databases, returning false if none of them have migrations
+func BuildDatabaseMigrationImages(fs afero.Fs, databases map[string]*resourcespb.SqlDatabaseResource, useBuilder bool) (bool, error) {
+	serviceRequirements := collector.MakeDatabaseServiceRequirements(databases)
 
 	migrationImageContexts, err := collector.GetMigrationImageBuildContexts(serviceRequirements, []*collector.BatchRequirements{}, fs)
 	if err != nil {
-		return fmt.Errorf("failed to get migration image build contexts: %w", err)
+		return false, fmt.Errorf("failed to get migration image build contexts: %w", err)
 	}
 
-	if len(migrationImageContexts) > 0 {
-		updates, err := BuildMigrationImages(fs, migrationImageContexts, useBuilder)
-		if err != nil {
-			return err
-		}
+	if len(migrationImageContexts) == 0 {
+		return false, nil
+	}
 
-		// wait for updates to complete
-		for update := range updates {
-			if update.Err != nil {
-				return fmt.Errorf("failed to build migration image: %w", update.Err)
-			}
+	updates, err := BuildMigrationImages(fs, migrationImageContexts, useBuilder)
+	if err != nil {
+		return false, err
+	}
+
+	// wait for updates to complete
+	for update := range updates {
+		if update.Err != nil {
+			return false, fmt.Errorf("failed to build migration image: %w", update.Err)
 		}
+	}
 
-		err = RunMigrations(servers)
+	return true, nil
+}
+
+func BuildAndRunMigrations(fs afero.Fs, servers map[string]*sql.DatabaseServer, databasesToMigrate map[string]*resourcespb.SqlDatabaseResource, useBuilder bool, output sql.MigrationOutput) error {
+	built, err := BuildDatabaseMigrationImages(fs, databasesToMigrate, useBuilder)
+	if err != nil {
+		return err
+	}
+
+	if built {
+		err = RunMigrations(servers, output)
 		if err != nil {
 			return fmt.Errorf("failed to run migrations: %w", err)
 		}
@@ -185,8 +206,70 @@ func BuildMigrationImages(fs afero.Fs, migrationBuildContexts map[string]*runtim
 	return updatesChan, nil
 }
 
-// Run the migrations
-func RunMigration(databaseName string, connectionString string) error {
+// RunMigration runs the migrations container of a database and waits for it to exit, streaming its output.
+// A *sql.MigrationError is returned if the migrations exit with a non-zero status.
+func RunMigration(databaseName string, connectionString string, output sql.MigrationOutput) error {
+	return runMigrationContainer(databaseName, connectionString, nil, output)
+}
+
+// RunMigrationCommand runs a migrate command, e.g. down 1 or version, in the migrations container of a database.
+// Only images built from file:// migrations include the migrate tool.
+func RunMigrationCommand(databaseName string, connectionString string, output sql.MigrationOutput, args ...string) error {
+	// exec form, so the connection string and arguments are passed to migrate without a shell interpreting them
+	entrypoint := append([]string{"migrate", "-path=/migrations", "-database", dockerConnectionString(connectionString)}, args...)
+
+	return runMigrationContainer(databaseName, connectionString, entrypoint, output)
+}
+
+// migrationTimestampFormat matches the default version format of migrate create
+const migrationTimestampFormat = "20060102150405"
+
+var migrationTitleReplacer = regexp.MustCompile(`[^a-z0-9]+`)
+
+// NewMigrationFiles creates empty up and down migration files in the migrations directory, returning their paths
+func NewMigrationFiles(fs afero.Fs, migrationsDir string, title string, now time.Time) ([]string, error) {
+	name := strings.Trim(migrationTitleReplacer.ReplaceAllString(strings.ToLower(title), "_"), "_")
+	if name == "" {
+		return nil, fmt.Errorf("invalid migration title %q", title)
+	}
+
+	if err := fs.MkdirAll(migrationsDir, os.ModePerm); err != nil {
+		return nil, err
+	}
+
+	files := []string{}
+
+	for _, direction := range []string{"up", "down"} {
+		file := filepath.Join(migrationsDir, fmt.Sprintf("%s_%s.%s.sql", now.UTC().Format(migrationTimestampFormat), name, direction))
+
+		if exists, err := afero.Exists(fs, file); err != nil {
+			return nil, err
+		} else if exists {
+			return nil, fmt.Errorf("migration file %s already exists", file)
+		}
+
+		if err := afero.WriteFile(fs, file, []byte{}, 0o644); err != nil {
+			return nil, err
+		}
+
+		files = append(files, file)
+	}
+
+	return files, nil
+}
+
+// dockerConnectionString returns the connection string of a local database for use from a container
+func dockerConnectionString(connectionString string) string {
+	return strings.Replace(connectionString, "localhost", dockerhost.GetInternalDockerHost(), 1)
+}
+
+// migrationContainerName returns a unique name for a migrations container, so a container left behind by an interrupted run
+// or a concurrent run against the same database, e.g. a schema drift check, doesn't conflict with it
+func migrationContainerName(databaseName string) string {
+	return fmt.Sprintf("nitric-%s-migrations-local-sql-%s", databaseName, strings.ReplaceAll(uuid.NewString(), "-", "")[:8])
+}
+
+func runMigrationContainer(databaseName string, connectionString string, entrypoint []string, output sql.MigrationOutput) error {
 	client, err := docker.New()
 	if err != nil {
 		return err
@@ -195,35 +278,77 @@ func RunMigration(databaseName string, connectionString string) error {
 	// Run the migrations
 	imageName := migrationImageName(databaseName)
 
-	// Update connection string for docker host...
-	dockerHost := dockerhost.GetInternalDockerHost()
-
-	dockerConnectionString := strings.Replace(connectionString, "localhost", dockerHost, 1)
-
 	// Create the container
 	containerId, err := client.ContainerCreate(&container.Config{
-		Image: imageName,
+		Image:      imageName,
+		Entrypoint: entrypoint,
 		Env: []string{
 			fmt.Sprintf("NITRIC_DB_NAME=%s", databaseName),
-			fmt.Sprintf("DB_URL=%s", dockerConnectionString),
+			fmt.Sprintf("DB_URL=%s", dockerConnectionString(connectionString)),
 		},
-	}, &container.HostConfig{
-		AutoRemove: true,
-	}, nil, fmt.Sprintf("nitric-%s-migrations-local-sql", databaseName))
+	}, &container.HostConfig{}, nil, migrationContainerName(databaseName))
 	if err != nil {
 		return err
 	}
 
+	// defer removing container so logs can be retrieved, used instead of AutoRemove
+	defer func() {
+		err := client.ContainerRemove(context.Background(), containerId, container.RemoveOptions{Force: true})
+		if err != nil {
+			logger.Errorf("unable to remove migrations container for database %s: %s", databaseName, err)
+		}
+	}()
+
 	// Start the container
 	err = client.ContainerStart(context.Background(), containerId, container.StartOptions{})
 	if err != nil {
 		return err
 	}
 
+	logReader, err := client.ContainerLogs(context.Background(), containerId, container.LogsOptions{ShowStdout: true, ShowStderr: true, Follow: true})
+	if err != nil {
+		return err
+	}
+	defer logReader.Close()
+
+	// keep the tail of the output to report if the migrations fail
+	var logs bytes.Buffer
+
+	outputWriter := writerFunc(func(p []byte) (int, error) {
+		logs.Write(p)
+		output(databaseName, string(p))
+
+		return len(p), nil
+	})
+
+	// the log stream ends when the container exits
+	if _, err := stdcopy.StdCopy(outputWriter, outputWriter, logReader); err != nil {
+		return fmt.Errorf("error reading logs for migrations of database %s: %w", databaseName, err)
+	}
+
+	okChan, errChan := client.ContainerWait(context.Background(), containerId, container.WaitConditionNotRunning)
+
+	select {
+	case err := <-errChan:
+		return err
+	case okBody := <-okChan:
+		if okBody.StatusCode != 0 {
+			lines := strings.Split(strings.TrimSpace(logs.String()), "\n")
+
+			return &sql.MigrationError{
+				DatabaseName: databaseName,
+				ExitCode:     okBody.StatusCode,
+				Logs:         strings.Join(lines[max(0, len(lines)-20):], "\n"),
+			}
+		}
+	}
+
 	return nil
 }
 
-func RunMigrations(servers map[string]*sql.DatabaseServer) error {
+// RunMigrations runs the migrations of each database concurrently, waiting for all of them to complete.
+// The errors of failed migrations are joined.
+func RunMigrations(servers map[string]*sql.DatabaseServer, output sql.MigrationOutput) error {
 	var wg sync.WaitGroup
 
 	errChan := make(chan error, len(servers))
@@ -234,23 +359,21 @@ func RunMigrations(servers map[string]*sql.DatabaseServer) error {
 		go func(dbName string, connectionString string) {
 			defer wg.Done()
 
-			err := RunMigration(dbName, connectionString)
+			err := RunMigration(dbName, connectionString, output)
 			if err != nil {
 				errChan <- err
 			}
 		}(name, mig.ConnectionString)
 	}
 
-	go func() {
-		wg.Wait()
-		close(errChan)
-	}()
+	wg.Wait()
+	close(errChan)
+
+	errs := []error{}
 
 	for err := range errChan {
-		if err != nil {
-			return err
-		}
+		errs = append(errs, err)
 	}
 
-	return nil
+	return errors.Join(errs...)
 }
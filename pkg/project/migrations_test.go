@@ -0,0 +1,58 @@
+// Copyright Nitric Pty Ltd.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package project
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/spf13/afero"
+)
+
+func TestNewMigrationFiles(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	now := time.Date(2024, 1, 2, 15, 4, 5, 0, time.UTC)
+
+	files, err := NewMigrationFiles(fs, "migrations/users", "Add users table!", now)
+	if err != nil {
+		t.Fatalf("NewMigrationFiles() error = %v", err)
+	}
+
+	want := []string{
+		"migrations/users/20240102150405_add_users_table.up.sql",
+		"migrations/users/20240102150405_add_users_table.down.sql",
+	}
+
+	if diff := cmp.Diff(want, files); diff != "" {
+		t.Errorf("NewMigrationFiles() mismatch (-want +got):\n%s", diff)
+	}
+
+	for _, file := range want {
+		if exists, _ := afero.Exists(fs, file); !exists {
+			t.Errorf("expected %s to be created", file)
+		}
+	}
+
+	if _, err := NewMigrationFiles(fs, "migrations/users", "add users table", now); err == nil {
+		t.Errorf("expected an error when the migration files already exist")
+	}
+
+	if _, err := NewMigrationFiles(fs, "migrations/users", "!!", now); err == nil {
+		t.Errorf("expected an error for an empty migration title")
+	}
+}
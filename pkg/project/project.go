@@ -39,6 +39,7 @@ import (
 	goruntime "runtime"
 
 	"github.com/nitrictech/cli/pkg/cloud"
+	"github.com/nitrictech/cli/pkg/cloud/health"
 	"github.com/nitrictech/cli/pkg/cloud/websites"
 	"github.com/nitrictech/cli/pkg/collector"
 	"github.com/nitrictech/cli/pkg/preview"
@@ -494,15 +495,25 @@ func (p *Project) RunServicesWithCommand(localCloud *cloud.LocalCloud, stop <-ch
 
 		// start the service with the given file reference from its projects CWD
 		group.Go(func() error {
-			port, err := localCloud.AddService(svc.GetFilePath())
+			ports, err := localCloud.AddService(svc.GetFilePath(), 1)
 			if err != nil {
 				return fmt.Errorf("unable to add service %s: %w", svc.GetFilePath(), err)
 			}
 
+			if p.LocalConfig.Service(svc.GetFilePath()).HealthCheck != nil {
+				// health checks run against service containers, which aren't used by nitric start
+				updates <- ServiceRunUpdate{
+					ServiceName: svc.Name,
+					Label:       "nitric",
+					Message:     fmt.Sprintf("the healthcheck of service %s is only run by nitric run, requests are sent to it without waiting for it to be ready\n", svc.GetFilePath()),
+					Status:      ServiceRunStatus_Running,
+				}
+			}
+
 			envVariables := map[string]string{
 				"PYTHONUNBUFFERED":   "TRUE", // ensure all print statements print immediately for python
 				"NITRIC_ENVIRONMENT": "run",
-				"SERVICE_ADDRESS":    "localhost:" + strconv.Itoa(port),
+				"SERVICE_ADDRESS":    "localhost:" + strconv.Itoa(ports[0]),
 			}
 
 			for key, value := range env {
@@ -585,9 +596,9 @@ func (p *Project) RunBatches(localCloud *cloud.LocalCloud, stop <-chan bool, upd
 	return group.Wait()
 }
 
-// RunServices - Runs all the services as containers
-// use the stop channel to stop all running services
-func (p *Project) RunServices(localCloud *cloud.LocalCloud, stop <-chan bool, updates chan<- ServiceRunUpdate, env map[string]string) error {
+// RunServices - Runs all the services as containers, with the replicas, resource limits and health checks from the local configuration
+// and the debugger of any debug targets. Use the stop channel to stop all running services
+func (p *Project) RunServices(localCloud *cloud.LocalCloud, stop <-chan bool, updates chan<- ServiceRunUpdate, env map[string]string, debugTargets []*DebugTarget) error {
 	stopChannels := lo.FanOut[bool](len(p.services), 1, stop)
 
 	group, _ := errgroup.WithContext(context.TODO())
@@ -596,13 +607,56 @@ func (p *Project) RunServices(localCloud *cloud.LocalCloud, stop <-chan bool, up
 		idx := i
 		svc := service
 
+		serviceConfig := p.LocalConfig.Service(svc.GetFilePath())
+
+		memoryBytes, err := serviceConfig.MemoryBytes()
+		if err != nil {
+			return fmt.Errorf("service %s: %w", svc.GetFilePath(), err)
+		}
+
 		group.Go(func() error {
-			port, err := localCloud.AddService(svc.GetFilePath())
+			ports, err := localCloud.AddService(svc.GetFilePath(), serviceConfig.Replicas)
 			if err != nil {
 				return err
 			}
 
-			return svc.RunContainer(stopChannels[idx], updates, WithNitricPort(strconv.Itoa(port)), WithEnvVars(env))
+			if serviceConfig.HealthCheck != nil {
+				// hold back requests and schedules from the replicas until they pass their health check
+				localCloud.Health.Register(svc.GetFilePath(), len(ports))
+			}
+
+			replicaStopChannels := lo.FanOut[bool](len(ports), 1, stopChannels[idx])
+
+			replicaGroup, _ := errgroup.WithContext(context.TODO())
+
+			debugTarget, debug := lo.Find(debugTargets, func(target *DebugTarget) bool {
+				return target.ServicePath == svc.GetFilePath()
+			})
+
+			for replica, port := range ports {
+				opts := []RunContainerOption{
+					WithNitricPort(strconv.Itoa(port)),
+					WithEnvVars(env),
+					WithResources(serviceConfig.Cpu, memoryBytes),
+					WithReplica(replica, len(ports)),
+				}
+
+				if serviceConfig.HealthCheck != nil {
+					opts = append(opts, WithHealthCheck(serviceConfig.HealthCheck, func(status health.Status, message string) {
+						localCloud.Health.SetStatus(svc.GetFilePath(), replica, status, message)
+					}))
+				}
+
+				if debug && replica < len(debugTarget.HostPorts) {
+					opts = append(opts, WithDebugger(debugTarget.Debugger, debugTarget.HostPorts[replica]))
+				}
+
+				replicaGroup.Go(func() error {
+					return svc.RunContainer(replicaStopChannels[replica], updates, opts...)
+				})
+			}
+
+			return replicaGroup.Wait()
 		})
 	}
 
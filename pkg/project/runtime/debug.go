@@ -0,0 +1,182 @@
+// Copyright Nitric Pty Ltd.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package runtime
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+)
+
+type DebuggerType string
+
+const (
+	DebuggerType_Node    DebuggerType = "node"
+	DebuggerType_Debugpy DebuggerType = "debugpy"
+	DebuggerType_Delve   DebuggerType = "delve"
+	DebuggerType_JDWP    DebuggerType = "jdwp"
+)
+
+// Debugger - describes how to run a service container with a debugger that an IDE can attach to
+type Debugger struct {
+	Type DebuggerType
+	// Port is the port the debugger listens on inside the container
+	Port int
+	// Env is added to the environment of the container, e.g. to enable the debugger through runtime options
+	Env map[string]string
+	// BuildArgs are added to the build arguments of the service image, e.g. to build it with source maps
+	BuildArgs map[string]string
+	// RemoteRoot is the directory the service code is copied to in the default image of the runtime
+	RemoteRoot string
+
+	// entrypoint returns the command running the service under the debugger from the entrypoint of the image,
+	// it is nil when the image entrypoint is kept
+	entrypoint func(imageEntrypoint []string) []string
+}
+
+// Entrypoint - returns the entrypoint of the container running the service under the debugger, or nil to keep the image entrypoint
+func (d *Debugger) Entrypoint(imageEntrypoint []string) []string {
+	if d.entrypoint == nil {
+		return nil
+	}
+
+	return d.entrypoint(imageEntrypoint)
+}
+
+// OverridesEntrypoint - returns true if the debugger replaces the entrypoint of the image
+func (d *Debugger) OverridesEntrypoint() bool {
+	return d.entrypoint != nil
+}
+
+// LaunchConfiguration - returns a VS Code launch.json configuration attaching to the debugger on a host port,
+// localRoot is the directory of the service build context relative to the workspace folder
+func (d *Debugger) LaunchConfiguration(name string, hostPort int, localRoot string) map[string]any {
+	localRoot = path.Join("${workspaceFolder}", filepath.ToSlash(localRoot))
+
+	switch d.Type {
+	case DebuggerType_Node:
+		return map[string]any{
+			"name":       name,
+			"type":       "node",
+			"request":    "attach",
+			"address":    "localhost",
+			"port":       hostPort,
+			"localRoot":  localRoot,
+			"remoteRoot": d.RemoteRoot,
+			"restart":    true,
+			"sourceMaps": true,
+			"outFiles":   []string{path.Join(localRoot, "**/*.js"), "!**/node_modules/**"},
+		}
+	case DebuggerType_Debugpy:
+		return map[string]any{
+			"name":    name,
+			"type":    "debugpy",
+			"request": "attach",
+			"connect": map[string]any{
+				"host": "localhost",
+				"port": hostPort,
+			},
+			"pathMappings": []map[string]any{
+				{"localRoot": localRoot, "remoteRoot": d.RemoteRoot},
+			},
+		}
+	case DebuggerType_Delve:
+		return map[string]any{
+			"name":    name,
+			"type":    "go",
+			"request": "attach",
+			"mode":    "remote",
+			"host":    "localhost",
+			"port":    hostPort,
+		}
+	default:
+		return map[string]any{
+			"name":     name,
+			"type":     "java",
+			"request":  "attach",
+			"hostName": "localhost",
+			"port":     hostPort,
+		}
+	}
+}
+
+// NewDebugger - returns the debugger for the runtime of a service, detected from the extension of its entrypoint file
+func NewDebugger(entrypointFilePath string) (*Debugger, error) {
+	ext := filepath.Ext(entrypointFilePath)
+
+	switch ext {
+	case ".js", ".ts":
+		remoteRoot := "/"
+		buildArgs := map[string]string{}
+
+		if ext == ".ts" {
+			remoteRoot = "/usr/app"
+			// the bundle built by ncc only maps back to the typescript sources with source maps
+			buildArgs["SOURCE_MAPS"] = "true"
+		}
+
+		return &Debugger{
+			Type:       DebuggerType_Node,
+			Port:       9229,
+			Env:        map[string]string{"NODE_OPTIONS": "--inspect=0.0.0.0:9229 --enable-source-maps"},
+			BuildArgs:  buildArgs,
+			RemoteRoot: remoteRoot,
+		}, nil
+	case ".py":
+		return &Debugger{
+			Type:       DebuggerType_Debugpy,
+			Port:       5678,
+			Env:        map[string]string{},
+			RemoteRoot: "/",
+			entrypoint: func(imageEntrypoint []string) []string {
+				// debugpy isn't part of the service dependencies, so it's installed when the container starts
+				return []string{"sh", "-c", fmt.Sprintf("pip install --quiet debugpy && python -m debugpy --listen 0.0.0.0:5678 ${HANDLER:-%s}", filepath.ToSlash(entrypointFilePath))}
+			},
+		}, nil
+	case ".go":
+		return &Debugger{
+			Type:       DebuggerType_Delve,
+			Port:       2345,
+			Env:        map[string]string{},
+			RemoteRoot: "/",
+			entrypoint: func(imageEntrypoint []string) []string {
+				// delve must be installed in the image and the binary built with -gcflags="all=-N -l" for breakpoints to work
+				entrypoint := []string{"dlv", "exec", "--headless", "--listen=:2345", "--api-version=2", "--accept-multiclient", "--continue"}
+
+				if len(imageEntrypoint) == 0 {
+					return entrypoint
+				}
+
+				entrypoint = append(entrypoint, imageEntrypoint[0])
+				if len(imageEntrypoint) > 1 {
+					entrypoint = append(append(entrypoint, "--"), imageEntrypoint[1:]...)
+				}
+
+				return entrypoint
+			},
+		}, nil
+	case ".jar":
+		return &Debugger{
+			Type:       DebuggerType_JDWP,
+			Port:       5005,
+			Env:        map[string]string{"JAVA_TOOL_OPTIONS": "-agentlib:jdwp=transport=dt_socket,server=y,suspend=n,address=*:5005"},
+			RemoteRoot: "/",
+		}, nil
+	default:
+		return nil, fmt.Errorf("nitric does not support debugging services with extension %s", ext)
+	}
+}
@@ -0,0 +1,62 @@
+// Copyright Nitric Pty Ltd.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package runtime
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestDebuggerEntrypoint(t *testing.T) {
+	for _, tt := range []struct {
+		file            string
+		imageEntrypoint []string
+		expected        []string
+	}{
+		{
+			file:            "services/api.ts",
+			imageEntrypoint: []string{"node", "lib/index.js"},
+			expected:        nil,
+		},
+		{
+			file:            "services/api.py",
+			imageEntrypoint: []string{"/bin/sh", "-c", "python -u $HANDLER"},
+			expected:        []string{"sh", "-c", "pip install --quiet debugpy && python -m debugpy --listen 0.0.0.0:5678 ${HANDLER:-services/api.py}"},
+		},
+		{
+			file:            "cmd/api/main.go",
+			imageEntrypoint: []string{"/bin/main", "--verbose"},
+			expected:        []string{"dlv", "exec", "--headless", "--listen=:2345", "--api-version=2", "--accept-multiclient", "--continue", "/bin/main", "--", "--verbose"},
+		},
+	} {
+		t.Run(tt.file, func(t *testing.T) {
+			debugger, err := NewDebugger(tt.file)
+			if err != nil {
+				t.Fatalf("NewDebugger() error = %v", err)
+			}
+
+			if diff := cmp.Diff(tt.expected, debugger.Entrypoint(tt.imageEntrypoint)); diff != "" {
+				t.Errorf("Entrypoint() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+
+	if _, err := NewDebugger("services/api.dart"); err == nil {
+		t.Errorf("NewDebugger() expected an error for dart services")
+	}
+}
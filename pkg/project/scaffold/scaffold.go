@@ -0,0 +1,283 @@
+// Copyright Nitric Pty Ltd.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scaffold
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/ettle/strcase"
+	"github.com/samber/lo"
+	"github.com/spf13/afero"
+
+	"github.com/nitrictech/cli/pkg/project"
+	"github.com/nitrictech/cli/pkg/validation"
+)
+
+//go:embed snippets
+var snippets embed.FS
+
+type HandlerType string
+
+const (
+	HandlerType_Api      HandlerType = "api"
+	HandlerType_Topic    HandlerType = "topic"
+	HandlerType_Schedule HandlerType = "schedule"
+	HandlerType_Job      HandlerType = "job"
+)
+
+var HandlerTypes = []string{string(HandlerType_Api), string(HandlerType_Topic), string(HandlerType_Schedule), string(HandlerType_Job)}
+
+type language struct {
+	ext string
+	// dir is true for languages where each service is a directory matched as a whole, with the handler in a main file
+	dir bool
+	// runtime is the custom runtime required by the language, it must be defined in the runtimes of the nitric.yaml
+	runtime string
+	// start is the default start command used when a new services entry is added
+	start string
+	// snakeCase identifiers are used in snippets instead of camelCase
+	snakeCase bool
+}
+
+var languages = map[string]language{
+	"ts":   {ext: ".ts", start: "npx tsx $SERVICE_PATH"},
+	"js":   {ext: ".js", start: "node $SERVICE_PATH"},
+	"py":   {ext: ".py", start: "python -- $SERVICE_PATH", snakeCase: true},
+	"go":   {ext: ".go", dir: true, runtime: "go", start: "go run ./$SERVICE_PATH/..."},
+	"dart": {ext: ".dart", start: "dart run $SERVICE_PATH"},
+}
+
+var Languages = []string{"ts", "js", "py", "go", "dart"}
+
+type ServiceOptions struct {
+	Name     string
+	Language string
+	Type     HandlerType
+}
+
+type ServiceResult struct {
+	// File is the created handler file, relative to the project directory
+	File string
+	// Match is the nitric.yaml match pattern that matches the new service
+	Match string
+	// NewEntry is true when a services entry was added to the nitric.yaml
+	NewEntry bool
+}
+
+// entrypointFromMatch returns the path the service name would have if it were matched by a simple pattern like services/*.ts
+func entrypointFromMatch(basedir string, match string, name string) (string, bool) {
+	if strings.Count(match, "*") != 1 || strings.ContainsAny(match, "?[") {
+		return "", false
+	}
+
+	entrypoint := strings.Replace(match, "*", name, 1)
+
+	if ok, _ := filepath.Match(match, entrypoint); !ok {
+		return "", false
+	}
+
+	return filepath.Join(basedir, entrypoint), true
+}
+
+// matchingPatterns returns the match patterns of the project that match the given entrypoint
+func matchingPatterns(config *project.ProjectConfiguration, entrypoint string) []string {
+	baseServices := []project.BaseService{}
+
+	for _, service := range config.Services {
+		baseServices = append(baseServices, service)
+	}
+
+	for _, batch := range config.Batches {
+		baseServices = append(baseServices, batch)
+	}
+
+	patterns := []string{}
+
+	for _, baseService := range baseServices {
+		if ok, _ := filepath.Match(filepath.Join(baseService.GetBasedir(), baseService.GetMatch()), filepath.Clean(entrypoint)); ok {
+			patterns = append(patterns, baseService.GetMatch())
+		}
+	}
+
+	return patterns
+}
+
+func renderSnippet(lang string, opts ServiceOptions) ([]byte, error) {
+	contents, err := snippets.ReadFile(fmt.Sprintf("snippets/%s/%s.tmpl", lang, opts.Type))
+	if err != nil {
+		return nil, fmt.Errorf("%s handlers are not supported for language %s", opts.Type, lang)
+	}
+
+	tmpl, err := template.New(string(opts.Type)).Parse(string(contents))
+	if err != nil {
+		return nil, err
+	}
+
+	identifier := strcase.ToCamel(opts.Name)
+	if languages[lang].snakeCase {
+		identifier = strcase.ToSnake(opts.Name)
+	}
+
+	var result bytes.Buffer
+
+	err = tmpl.Execute(&result, map[string]string{
+		"Name":       opts.Name,
+		"Identifier": identifier,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to execute %s snippet: %w", opts.Type, err)
+	}
+
+	return result.Bytes(), nil
+}
+
+// AddService creates a handler for a new service from the language snippets and adds it to the project configuration.
+// The handler is placed using the first existing match pattern for the language, or a new services entry is added if there isn't one.
+func AddService(fs afero.Fs, configPath string, opts ServiceOptions) (*ServiceResult, error) {
+	if !validation.IsValidResourceName(opts.Name) {
+		return nil, validation.NewResourceNameViolationError(opts.Name, "service")
+	}
+
+	lang, ok := languages[opts.Language]
+	if !ok {
+		return nil, fmt.Errorf("unsupported language %s, supported languages are %s", opts.Language, strings.Join(Languages, ", "))
+	}
+
+	if !lo.Contains(HandlerTypes, string(opts.Type)) {
+		return nil, fmt.Errorf("unsupported handler type %s, supported types are %s", opts.Type, strings.Join(HandlerTypes, ", "))
+	}
+
+	snippet, err := renderSnippet(opts.Language, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	config, err := project.ConfigurationFromFile(fs, configPath)
+	if err != nil {
+		return nil, err
+	}
+
+	isBatch := opts.Type == HandlerType_Job
+
+	existing := []project.BaseService{}
+
+	if isBatch {
+		for _, batch := range config.Batches {
+			existing = append(existing, batch)
+		}
+	} else {
+		for _, service := range config.Services {
+			existing = append(existing, service)
+		}
+	}
+
+	result := &ServiceResult{}
+	entrypoint := ""
+
+	for _, baseService := range existing {
+		if lang.dir && baseService.GetRuntime() != lang.runtime {
+			continue
+		}
+
+		if !lang.dir && filepath.Ext(baseService.GetMatch()) != lang.ext {
+			continue
+		}
+
+		if e, ok := entrypointFromMatch(baseService.GetBasedir(), baseService.GetMatch(), opts.Name); ok {
+			entrypoint = e
+			result.Match = baseService.GetMatch()
+
+			break
+		}
+	}
+
+	newEntry := project.BaseServiceConfiguration{}
+
+	if entrypoint == "" {
+		if lang.runtime != "" {
+			if _, ok := config.Runtimes[lang.runtime]; !ok {
+				return nil, fmt.Errorf("%s services require a custom runtime, add a %s runtime to the runtimes of your nitric.yaml", opts.Language, lang.runtime)
+			}
+		}
+
+		dir := lo.Ternary(isBatch, "batches", "services")
+
+		newEntry = project.BaseServiceConfiguration{
+			Basedir: "./",
+			Match:   lo.Ternary(lang.dir, dir+"/*", dir+"/*"+lang.ext),
+			Runtime: lang.runtime,
+			Start:   lang.start,
+		}
+
+		if isBatch {
+			config.Batches = append(config.Batches, project.BatchConfiguration{BaseServiceConfiguration: newEntry})
+		} else {
+			config.Services = append(config.Services, project.ServiceConfiguration{BaseServiceConfiguration: newEntry})
+		}
+
+		entrypoint, _ = entrypointFromMatch(newEntry.Basedir, newEntry.Match, opts.Name)
+		result.Match = newEntry.Match
+		result.NewEntry = true
+	}
+
+	patterns := matchingPatterns(config, entrypoint)
+	if len(patterns) == 0 {
+		return nil, fmt.Errorf("service %s wouldn't be matched by any pattern in your nitric.yaml", entrypoint)
+	}
+
+	if len(patterns) > 1 {
+		return nil, fmt.Errorf("service %s would be matched by multiple patterns: %s, services must only be matched by a single pattern", entrypoint, strings.Join(patterns, " and "))
+	}
+
+	result.File = lo.Ternary(lang.dir, filepath.Join(entrypoint, "main"+lang.ext), entrypoint)
+	filePath := filepath.Join(config.Directory, result.File)
+
+	if exists, _ := afero.Exists(fs, filePath); exists {
+		return nil, fmt.Errorf("service file %s already exists", result.File)
+	}
+
+	if result.NewEntry {
+		editor, err := project.EditConfigurationFile(fs, configPath)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := editor.Append(lo.Ternary(isBatch, "batch-services", "services"), newEntry); err != nil {
+			return nil, err
+		}
+
+		if err := editor.Save(); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := fs.MkdirAll(filepath.Dir(filePath), os.ModePerm); err != nil {
+		return nil, err
+	}
+
+	if err := afero.WriteFile(fs, filePath, snippet, os.ModePerm); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
@@ -0,0 +1,120 @@
+// Copyright Nitric Pty Ltd.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scaffold
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/spf13/afero"
+)
+
+const testConfig = `# my project
+name: my-project
+services:
+  # typescript services
+  - basedir: ./
+    match: services/*.ts
+    start: npm run dev:services $SERVICE_PATH
+  - basedir: ./
+    match: services/orders*.ts
+    start: npm run dev:services $SERVICE_PATH
+`
+
+func TestAddService(t *testing.T) {
+	tests := []struct {
+		name         string
+		opts         ServiceOptions
+		wantFile     string
+		wantNewEntry bool
+		wantErr      string
+	}{
+		{
+			name:     "existing match pattern",
+			opts:     ServiceOptions{Name: "payments", Language: "ts", Type: HandlerType_Api},
+			wantFile: "services/payments.ts",
+		},
+		{
+			name:         "new services entry",
+			opts:         ServiceOptions{Name: "order-created", Language: "py", Type: HandlerType_Topic},
+			wantFile:     "services/order-created.py",
+			wantNewEntry: true,
+		},
+		{
+			name:         "new batch services entry",
+			opts:         ServiceOptions{Name: "report", Language: "ts", Type: HandlerType_Job},
+			wantFile:     "batches/report.ts",
+			wantNewEntry: true,
+		},
+		{
+			name:    "matched by multiple patterns",
+			opts:    ServiceOptions{Name: "orders", Language: "ts", Type: HandlerType_Api},
+			wantErr: "matched by multiple patterns",
+		},
+		{
+			name:    "go without a runtime",
+			opts:    ServiceOptions{Name: "payments", Language: "go", Type: HandlerType_Api},
+			wantErr: "require a custom runtime",
+		},
+		{
+			name:    "unsupported handler",
+			opts:    ServiceOptions{Name: "report", Language: "dart", Type: HandlerType_Job},
+			wantErr: "not supported for language dart",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fs := afero.NewMemMapFs()
+
+			if err := afero.WriteFile(fs, "nitric.yaml", []byte(testConfig), 0o600); err != nil {
+				t.Fatal(err)
+			}
+
+			result, err := AddService(fs, "nitric.yaml", tt.opts)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("AddService() error = %v, want %q", err, tt.wantErr)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if result.File != tt.wantFile || result.NewEntry != tt.wantNewEntry {
+				t.Errorf("AddService() = %+v, want file %s and new entry %t", result, tt.wantFile, tt.wantNewEntry)
+			}
+
+			if exists, _ := afero.Exists(fs, tt.wantFile); !exists {
+				t.Errorf("expected handler file %s to be created", tt.wantFile)
+			}
+
+			config, _ := afero.ReadFile(fs, "nitric.yaml")
+
+			if !strings.Contains(string(config), "# typescript services") {
+				t.Errorf("expected nitric.yaml comments to be kept, got:\n%s", config)
+			}
+
+			if tt.wantNewEntry && !strings.Contains(string(config), "match: "+result.Match) {
+				t.Errorf("expected nitric.yaml to contain match %s, got:\n%s", result.Match, config)
+			}
+		})
+	}
+}
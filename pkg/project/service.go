@@ -31,11 +31,14 @@ import (
 	"github.com/docker/docker/api/types/container"
 	"github.com/docker/docker/pkg/stdcopy"
 	"github.com/docker/go-connections/nat"
+	"github.com/docker/go-units"
 	"github.com/samber/lo"
 	"github.com/spf13/afero"
 
+	"github.com/nitrictech/cli/pkg/cloud/health"
 	"github.com/nitrictech/cli/pkg/docker"
 	"github.com/nitrictech/cli/pkg/netx"
+	"github.com/nitrictech/cli/pkg/project/localconfig"
 	"github.com/nitrictech/cli/pkg/project/runtime"
 	"github.com/nitrictech/nitric/core/pkg/env"
 	"github.com/nitrictech/nitric/core/pkg/logger"
@@ -89,6 +92,8 @@ const (
 	ServiceRunStatus_Running ServiceRunStatus = "Running"
 	ServiceRunStatus_Done    ServiceRunStatus = "Done"
 	ServiceRunStatus_Error   ServiceRunStatus = "Error"
+	// ServiceRunStatus_OOMKilled - the service container was killed for exceeding its memory limit
+	ServiceRunStatus_OOMKilled ServiceRunStatus = "OOM Killed"
 )
 
 type ServiceRunUpdate struct {
@@ -192,6 +197,14 @@ type runContainerOptions struct {
 	nitricPort        string
 	nitricEnvironment string
 	envVars           map[string]string
+	cpus              float64
+	memoryBytes       int64
+	replica           int
+	replicas          int
+	debugger          *runtime.Debugger
+	debugPort         int
+	healthCheck       *localconfig.LocalServiceHealthCheck
+	reportHealth      func(status health.Status, message string)
 }
 
 type RunContainerOption func(*runContainerOptions)
@@ -201,6 +214,7 @@ var defaultRunContainerOptions = runContainerOptions{
 	nitricPort:        "50051",
 	nitricEnvironment: "run",
 	envVars:           map[string]string{},
+	replicas:          1,
 }
 
 func WithNitricHost(host string) RunContainerOption {
@@ -227,6 +241,38 @@ func WithEnvVars(envVars map[string]string) RunContainerOption {
 	}
 }
 
+// WithResources limits the cpus and memory of the container, zero values leave the resource unlimited
+func WithResources(cpus float64, memoryBytes int64) RunContainerOption {
+	return func(o *runContainerOptions) {
+		o.cpus = cpus
+		o.memoryBytes = memoryBytes
+	}
+}
+
+// WithReplica sets which replica of the service the container runs, so each replica gets its own container name and log label
+func WithReplica(replica int, replicas int) RunContainerOption {
+	return func(o *runContainerOptions) {
+		o.replica = replica
+		o.replicas = replicas
+	}
+}
+
+// WithDebugger runs the service under a debugger, publishing the debugger's port on a host port
+func WithDebugger(debugger *runtime.Debugger, hostPort int) RunContainerOption {
+	return func(o *runContainerOptions) {
+		o.debugger = debugger
+		o.debugPort = hostPort
+	}
+}
+
+// WithHealthCheck checks the health of the container once it starts, reporting each change of its health
+func WithHealthCheck(check *localconfig.LocalServiceHealthCheck, report func(status health.Status, message string)) RunContainerOption {
+	return func(o *runContainerOptions) {
+		o.healthCheck = check
+		o.reportHealth = report
+	}
+}
+
 type writerFunc func(p []byte) (n int, err error)
 
 func (wf writerFunc) Write(p []byte) (n int, err error) {
@@ -335,6 +381,17 @@ func (s *Service) RunContainer(stop <-chan bool, updates chan<- ServiceRunUpdate
 		return err
 	}
 
+	containerName := s.Name
+	label := s.GetFilePath()
+
+	if runtimeOptions.replicas > 1 {
+		label = fmt.Sprintf("%s[%d]", label, runtimeOptions.replica)
+
+		if runtimeOptions.replica > 0 {
+			containerName = fmt.Sprintf("%s-%d", s.Name, runtimeOptions.replica)
+		}
+	}
+
 	hostConfig := &container.HostConfig{
 		// LogConfig:  *f.ce.Logger(f.runCtx).Config(),
 		LogConfig: container.LogConfig{
@@ -344,6 +401,10 @@ func (s *Service) RunContainer(stop <-chan bool, updates chan<- ServiceRunUpdate
 				"max-file": "3",
 			},
 		},
+		Resources: container.Resources{
+			NanoCPUs: int64(runtimeOptions.cpus * 1e9),
+			Memory:   runtimeOptions.memoryBytes,
+		},
 	}
 
 	if goruntime.GOOS == "linux" {
@@ -377,7 +438,7 @@ func (s *Service) RunContainer(stop <-chan bool, updates chan<- ServiceRunUpdate
 		if isBlacklisted {
 			updates <- ServiceRunUpdate{
 				ServiceName: s.Name,
-				Label:       s.GetFilePath(),
+				Label:       label,
 				Message:     fmt.Sprintf("Skipping blacklisted env var: %s", k),
 				Status:      ServiceRunStatus_Running,
 			}
@@ -404,17 +465,45 @@ func (s *Service) RunContainer(stop <-chan bool, updates chan<- ServiceRunUpdate
 		},
 	}
 
+	if debugger := runtimeOptions.debugger; debugger != nil {
+		for k, v := range debugger.Env {
+			containerConfig.Env = append(containerConfig.Env, k+"="+v)
+		}
+
+		if debugger.OverridesEntrypoint() {
+			image, _, err := dockerClient.ImageInspectWithRaw(context.TODO(), s.Name)
+			if err != nil {
+				return fmt.Errorf("unable to inspect image of service %s: %w", s.Name, err)
+			}
+
+			imageEntrypoint := []string{}
+			if image.Config != nil {
+				imageEntrypoint = append(append(imageEntrypoint, image.Config.Entrypoint...), image.Config.Cmd...)
+			}
+
+			containerConfig.Entrypoint = debugger.Entrypoint(imageEntrypoint)
+		}
+
+		debugPort := nat.Port(fmt.Sprint(debugger.Port))
+		containerConfig.ExposedPorts[debugPort] = struct{}{}
+		hostConfig.PortBindings[debugPort] = []nat.PortBinding{
+			{
+				HostPort: fmt.Sprint(runtimeOptions.debugPort),
+			},
+		}
+	}
+
 	// Create the container
 	containerId, err := dockerClient.ContainerCreate(
 		containerConfig,
 		hostConfig,
 		nil,
-		s.Name,
+		containerName,
 	)
 	if err != nil {
 		updates <- ServiceRunUpdate{
 			ServiceName: s.Name,
-			Label:       s.GetFilePath(),
+			Label:       label,
 			Status:      ServiceRunStatus_Error,
 			Err:         err,
 		}
@@ -429,7 +518,7 @@ func (s *Service) RunContainer(stop <-chan bool, updates chan<- ServiceRunUpdate
 		if err != nil {
 			updates <- ServiceRunUpdate{
 				ServiceName: s.Name,
-				Label:       s.GetFilePath(),
+				Label:       label,
 				Status:      ServiceRunStatus_Error,
 				Err:         err,
 			}
@@ -440,7 +529,7 @@ func (s *Service) RunContainer(stop <-chan bool, updates chan<- ServiceRunUpdate
 	if err != nil {
 		updates <- ServiceRunUpdate{
 			ServiceName: s.Name,
-			Label:       s.GetFilePath(),
+			Label:       label,
 			Status:      ServiceRunStatus_Error,
 			Err:         err,
 		}
@@ -452,10 +541,65 @@ func (s *Service) RunContainer(stop <-chan bool, updates chan<- ServiceRunUpdate
 	updates <- ServiceRunUpdate{
 		ServiceName: s.Name,
 		Label:       "nitric",
-		Message:     fmt.Sprintf("started service %s", s.GetFilePath()),
+		Message:     fmt.Sprintf("started service %s", label),
 		Status:      ServiceRunStatus_Running,
 	}
 
+	if runtimeOptions.debugger != nil {
+		updates <- ServiceRunUpdate{
+			ServiceName: s.Name,
+			Label:       "nitric",
+			Message:     fmt.Sprintf("%s debugger for service %s listening on localhost:%d", runtimeOptions.debugger.Type, label, runtimeOptions.debugPort),
+			Status:      ServiceRunStatus_Running,
+		}
+	}
+
+	if check := runtimeOptions.healthCheck; check != nil {
+		probe := httpHealthProbe(hostProxyPort, check.Path)
+		if len(check.Command) > 0 {
+			probe = commandHealthProbe(dockerClient, containerId, check.Command)
+		}
+
+		// a restarted container isn't ready until it passes its check again
+		runtimeOptions.reportHealth(health.Status_Starting, "")
+
+		healthCtx, stopHealthCheck := context.WithCancel(context.Background())
+		healthCheckDone := make(chan struct{})
+
+		go func() {
+			defer close(healthCheckDone)
+
+			monitorHealth(healthCtx, check, probe, func(status health.Status, message string) {
+				runtimeOptions.reportHealth(status, message)
+
+				update := ServiceRunUpdate{
+					ServiceName: s.Name,
+					Label:       "nitric",
+					Message:     fmt.Sprintf("service %s is %s", label, status),
+					Status:      ServiceRunStatus_Running,
+				}
+
+				if message != "" {
+					update.Message = fmt.Sprintf("service %s is %s: %s", label, status, message)
+				}
+
+				if status == health.Status_Unhealthy {
+					update.Status = ServiceRunStatus_Error
+				}
+
+				updates <- update
+			})
+		}()
+
+		defer func() {
+			stopHealthCheck()
+			<-healthCheckDone
+
+			// an exited container can't handle requests, whatever its last check reported
+			runtimeOptions.reportHealth(health.Status_Unhealthy, fmt.Sprintf("service %s exited", label))
+		}()
+	}
+
 	// Attach to the container to get stdout and stderr
 	attachOptions := container.AttachOptions{
 		Stream: true,
@@ -475,7 +619,7 @@ func (s *Service) RunContainer(stop <-chan bool, updates chan<- ServiceRunUpdate
 		_, err := io.Copy(writerFunc(func(p []byte) (int, error) {
 			updates <- ServiceRunUpdate{
 				ServiceName: s.Name,
-				Label:       s.GetFilePath(),
+				Label:       label,
 				Message:     string(p),
 				Status:      ServiceRunStatus_Running,
 			}
@@ -485,7 +629,7 @@ func (s *Service) RunContainer(stop <-chan bool, updates chan<- ServiceRunUpdate
 		if err != nil {
 			updates <- ServiceRunUpdate{
 				ServiceName: s.Name,
-				Label:       s.GetFilePath(),
+				Label:       label,
 				Status:      ServiceRunStatus_Error,
 				Err:         err,
 			}
@@ -499,7 +643,7 @@ func (s *Service) RunContainer(stop <-chan bool, updates chan<- ServiceRunUpdate
 		case err := <-errChan:
 			updates <- ServiceRunUpdate{
 				ServiceName: s.Name,
-				Label:       s.GetFilePath(),
+				Label:       label,
 				Err:         err,
 				Status:      ServiceRunStatus_Error,
 			}
@@ -520,19 +664,35 @@ func (s *Service) RunContainer(stop <-chan bool, updates chan<- ServiceRunUpdate
 					return fmt.Errorf("error reading logs for service %s: %w", s.Name, err)
 				}
 
+				status := ServiceRunStatus_Error
 				err = fmt.Errorf("service %s exited with non 0 status\n %s", s.Name, logs.String())
 
+				if inspect, inspectErr := dockerClient.ContainerInspect(context.Background(), containerId); inspectErr == nil && inspect.State != nil && inspect.State.OOMKilled {
+					status = ServiceRunStatus_OOMKilled
+					err = fmt.Errorf("service %s was killed for running out of memory\n %s", s.Name, logs.String())
+
+					if runtimeOptions.memoryBytes > 0 {
+						err = fmt.Errorf("service %s was killed for exceeding its memory limit of %s\n %s", s.Name, units.BytesSize(float64(runtimeOptions.memoryBytes)), logs.String())
+					}
+				}
+
+				message := ""
+				if status == ServiceRunStatus_OOMKilled {
+					message = strings.SplitN(err.Error(), "\n", 2)[0]
+				}
+
 				updates <- ServiceRunUpdate{
 					ServiceName: s.Name,
-					Label:       s.filepath,
+					Label:       label,
+					Message:     message,
 					Err:         err,
-					Status:      ServiceRunStatus_Error,
+					Status:      status,
 				}
 
 				return err
 			} else {
 				updates <- ServiceRunUpdate{
-					Label:       s.GetFilePath(),
+					Label:       label,
 					ServiceName: s.Name,
 					Message:     "Service successfully exited",
 					Status:      ServiceRunStatus_Done,
@@ -543,7 +703,7 @@ func (s *Service) RunContainer(stop <-chan bool, updates chan<- ServiceRunUpdate
 		case <-stop:
 			if err := dockerClient.ContainerStop(context.Background(), containerId, container.StopOptions{}); err != nil {
 				updates <- ServiceRunUpdate{
-					Label:       s.GetFilePath(),
+					Label:       label,
 					ServiceName: s.Name,
 					Status:      ServiceRunStatus_Error,
 					Err:         err,
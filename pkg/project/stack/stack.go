@@ -19,15 +19,20 @@ package stack
 import (
 	"bytes"
 	_ "embed"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
 	"regexp"
+	"slices"
+	"sort"
+	"strings"
 	"text/template"
 
 	"github.com/spf13/afero"
 	"gopkg.in/yaml.v3"
 
+	"github.com/nitrictech/cli/pkg/project/yamlschema"
 	"github.com/nitrictech/cli/pkg/update"
 )
 
@@ -57,6 +62,29 @@ var azureTfConfigTemplate string
 
 var fileNameRegex = regexp.MustCompile(`(?i)^nitric\.(\S+)\.ya?ml$`)
 
+// providerTemplates are the stack templates of each nitric provider, keyed by the provider name without a version
+var providerTemplates = map[string]string{
+	"nitric/aws":     awsConfigTemplate,
+	"nitric/awstf":   awsTfConfigTemplate,
+	"nitric/azure":   azureConfigTemplate,
+	"nitric/azuretf": azureTfConfigTemplate,
+	"nitric/gcp":     gcpConfigTemplate,
+	"nitric/gcptf":   gcpTfConfigTemplate,
+}
+
+// providerPostgresVersions are the postgres major versions of the sql databases deployed by each nitric provider
+var providerPostgresVersions = map[string]string{
+	"nitric/aws":     "13",
+	"nitric/awstf":   "13",
+	"nitric/azure":   "14",
+	"nitric/azuretf": "14",
+	"nitric/gcp":     "15",
+	"nitric/gcptf":   "15",
+}
+
+// templateKeyRegex matches the top level keys of a stack template, including the optional keys that are commented out
+var templateKeyRegex = regexp.MustCompile(`(?m)^(?:# ?)*([a-z][a-z0-9-]*):(?:\s|$)`)
+
 func IsValidFileName(stackName string) bool {
 	return fileNameRegex.MatchString(stackName)
 }
@@ -119,6 +147,93 @@ func StackFileName(stackName string) string {
 	return fmt.Sprintf("nitric.%s.yaml", stackName)
 }
 
+// ConfigSchema returns the JSON Schema of stack files for a nitric provider, documenting the keys of its stack template.
+// The provider's configuration isn't declared, so keys that aren't in the template are still allowed.
+// Nil is returned for providers that aren't nitric providers, their configuration isn't known.
+func ConfigSchema(provider string) *yamlschema.Schema {
+	schema := templateSchema(provider)
+	if schema == nil {
+		return nil
+	}
+
+	schema.AdditionalProperties = true
+
+	return schema
+}
+
+// templateSchema returns a schema allowing only the keys of a nitric provider's stack template
+func templateSchema(provider string) *yamlschema.Schema {
+	name, _, _ := strings.Cut(provider, "@")
+
+	template, ok := providerTemplates[name]
+	if !ok {
+		return nil
+	}
+
+	keys := []string{}
+
+	for _, match := range templateKeyRegex.FindAllStringSubmatch(template, -1) {
+		if !slices.Contains(keys, match[1]) {
+			keys = append(keys, match[1])
+		}
+	}
+
+	schema := yamlschema.Keys(keys, []string{"provider"})
+	schema.Properties["provider"] = &yamlschema.Schema{Type: "string", Pattern: "^" + regexp.QuoteMeta(name) + "(@.+)?$"}
+
+	return schema.Root(fmt.Sprintf("%s stack file", name))
+}
+
+// PostgresMajorVersion returns the postgres major version of the sql databases deployed by a nitric provider
+func PostgresMajorVersion(provider string) (string, bool) {
+	name, _, _ := strings.Cut(provider, "@")
+
+	version, ok := providerPostgresVersions[name]
+
+	return version, ok
+}
+
+// ProviderNames returns the names of the nitric providers with stack templates
+func ProviderNames() []string {
+	names := make([]string, 0, len(providerTemplates))
+	for name := range providerTemplates {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+// ValidateFile checks a stack file parses, returning the keys that aren't in its provider's stack template as warnings.
+// They may be misspelled, but the provider could also accept configuration its template doesn't show.
+func ValidateFile(fs afero.Fs, filePath string) (yamlschema.FieldErrors, error) {
+	contents, err := afero.ReadFile(fs, filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	stackConfig := &StackConfig[map[string]any]{}
+
+	if err := yaml.Unmarshal(contents, stackConfig); err != nil {
+		return nil, fmt.Errorf("unable to parse stack file '%s': %w", filePath, err)
+	}
+
+	schema := templateSchema(stackConfig.Provider)
+	if schema == nil {
+		return nil, nil
+	}
+
+	err = yamlschema.Validate(contents, schema)
+
+	warnings := yamlschema.FieldErrors{}
+	if errors.As(err, &warnings) {
+		return warnings, nil
+	}
+
+	return nil, err
+}
+
 // ConfigFromName returns a stack configuration from a given stack name
 func ConfigFromName[T any](fs afero.Fs, stackName string) (*StackConfig[T], error) {
 	stackFile := StackFileName(stackName)
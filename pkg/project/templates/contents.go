@@ -41,6 +41,8 @@ type TemplateInfo struct {
 	Label string `yaml:"label"`
 	Desc  string `yaml:"desc"`
 	Path  string `yaml:"path"`
+	// Placeholders are substituted in the created project, mapping placeholder names to their default values
+	Placeholders map[string]string `yaml:"placeholders,omitempty"`
 }
 
 type repository struct {
@@ -62,11 +64,29 @@ type downloader struct {
 
 var _ Downloader = &downloader{}
 
+// NewDownloader returns a downloader for the official templates and any template sources configured in the user's config directory
 func NewDownloader() Downloader {
-	return &downloader{
-		configPath: filepath.Join(paths.NitricTemplatesDir(), "cli-templates.yaml"),
+	official := &downloader{
+		configPath: filepath.Join(paths.NitricTemplatesDir(), templateIndexFile),
 		newGetter:  NewGetter,
 	}
+
+	sources, err := readSources(templateSourcesFile())
+	if err != nil {
+		return &multiDownloader{err: err}
+	}
+
+	if len(sources) == 0 {
+		return official
+	}
+
+	downloaders := []Downloader{official}
+
+	for _, source := range sources {
+		downloaders = append(downloaders, newSourceDownloader(source))
+	}
+
+	return &multiDownloader{downloaders: downloaders}
 }
 
 func (d *downloader) lazyLoadTemplates() error {
@@ -193,10 +213,11 @@ func (d *downloader) repository() error {
 
 	for _, template := range list {
 		d.repo = append(d.repo, TemplateInfo{
-			Name:  template.Name,
-			Label: template.Label,
-			Desc:  template.Desc,
-			Path:  filepath.Clean(template.Path),
+			Name:         template.Name,
+			Label:        template.Label,
+			Desc:         template.Desc,
+			Path:         filepath.Clean(template.Path),
+			Placeholders: template.Placeholders,
 		})
 	}
 
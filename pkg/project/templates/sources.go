@@ -0,0 +1,438 @@
+// Copyright Nitric Pty Ltd.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package templates
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/go-getter"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v2"
+
+	"github.com/nitrictech/cli/pkg/paths"
+)
+
+const templateIndexFile = "cli-templates.yaml"
+
+// Source is an additional location to find templates, e.g. a private git repository, local directory or tarball.
+// The root of each source must contain a cli-templates.yaml index.
+type Source struct {
+	Name string `yaml:"name"`
+	// Location is any go-getter source, e.g. git::https://github.com/org/templates.git?ref=main, ./templates or https://example.com/templates.tar.gz
+	Location string `yaml:"location"`
+}
+
+type sourcesConfig struct {
+	Sources []Source `yaml:"sources"`
+}
+
+// templateSourcesFile returns the path of the user's template sources configuration
+func templateSourcesFile() string {
+	return filepath.Join(paths.NitricConfigDir(), "template-sources.yaml")
+}
+
+// readSources reads the user's template sources, no sources are returned if the configuration doesn't exist
+func readSources(configPath string) ([]Source, error) {
+	contents, err := os.ReadFile(configPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	config := sourcesConfig{}
+
+	if err := yaml.Unmarshal(contents, &config); err != nil {
+		return nil, errors.WithMessage(err, "template sources file "+configPath)
+	}
+
+	for _, source := range config.Sources {
+		if source.Name == "" || source.Location == "" {
+			return nil, fmt.Errorf("template sources file %s: each source requires a name and location", configPath)
+		}
+
+		if source.Name == "official" {
+			return nil, fmt.Errorf("template sources file %s: the source name official is reserved", configPath)
+		}
+	}
+
+	return config.Sources, nil
+}
+
+type sourceDownloader struct {
+	source    Source
+	cacheDir  string
+	newGetter func(*getter.Client) GetterClient
+	repo      []TemplateInfo
+	// err is the error of getting the source, kept so a failing source isn't fetched again
+	err error
+}
+
+var _ Downloader = &sourceDownloader{}
+
+func newSourceDownloader(source Source) *sourceDownloader {
+	return &sourceDownloader{
+		source:    source,
+		cacheDir:  filepath.Join(paths.NitricTemplatesDir(), "sources", source.Name),
+		newGetter: NewGetter,
+	}
+}
+
+func (d *sourceDownloader) repository() error {
+	pwd, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+
+	// go-getter links local directories into place, which requires the destination to be a link or not exist
+	if err := os.RemoveAll(d.cacheDir); err != nil {
+		return err
+	}
+
+	client := d.newGetter(&getter.Client{
+		Ctx:  context.Background(),
+		Src:  d.source.Location,
+		Dst:  d.cacheDir,
+		Pwd:  pwd,
+		Mode: getter.ClientModeDir,
+	})
+
+	if err := client.Get(); err != nil {
+		return errors.WithMessagef(err, "error getting template source %s", d.source.Name)
+	}
+
+	contents, err := os.ReadFile(filepath.Join(d.cacheDir, templateIndexFile))
+	if err != nil {
+		return errors.WithMessagef(err, "template source %s is missing its %s index", d.source.Name, templateIndexFile)
+	}
+
+	repo := repository{}
+
+	if err := yaml.Unmarshal(contents, &repo); err != nil {
+		return errors.WithMessagef(err, "template source %s index", d.source.Name)
+	}
+
+	d.repo = []TemplateInfo{}
+
+	for _, template := range repo.Templates {
+		templatePath := filepath.Clean(template.Path)
+
+		// templates are copied from within the source, paths must not escape it
+		if !filepath.IsLocal(templatePath) {
+			d.repo = nil
+			return fmt.Errorf("template source %s: template %s has path %s outside of the source", d.source.Name, template.Name, template.Path)
+		}
+
+		label := template.Label
+		if label == "" {
+			label = template.Name
+		}
+
+		d.repo = append(d.repo, TemplateInfo{
+			Name:         d.source.Name + "/" + template.Name,
+			Label:        fmt.Sprintf("%s (%s)", label, d.source.Name),
+			Desc:         template.Desc,
+			Path:         templatePath,
+			Placeholders: template.Placeholders,
+		})
+	}
+
+	return nil
+}
+
+func (d *sourceDownloader) Templates() ([]TemplateInfo, error) {
+	if d.repo == nil && d.err == nil {
+		d.err = d.repository()
+	}
+
+	if d.err != nil {
+		return nil, d.err
+	}
+
+	return d.repo, nil
+}
+
+func (d *sourceDownloader) Get(name string) *TemplateInfo {
+	templates, err := d.Templates()
+	if err != nil {
+		return nil
+	}
+
+	for _, ti := range templates {
+		if ti.Name == name {
+			return &ti
+		}
+	}
+
+	return nil
+}
+
+func (d *sourceDownloader) GetByLabel(label string) *TemplateInfo {
+	templates, err := d.Templates()
+	if err != nil {
+		return nil
+	}
+
+	for _, ti := range templates {
+		if ti.Label == label {
+			return &ti
+		}
+	}
+
+	return nil
+}
+
+func (d *sourceDownloader) DownloadDirectoryContents(name string, destDir string, force bool) error {
+	_, err := os.Stat(destDir)
+	if err == nil && !force {
+		return errors.New("project directory already exists and isn't empty, choose a different name or use the --force flag to create in a non-empty directory")
+	}
+
+	template := d.Get(name)
+	if template == nil {
+		return fmt.Errorf("template %s not found", name)
+	}
+
+	srcDir, err := filepath.EvalSymlinks(filepath.Join(d.cacheDir, template.Path))
+	if err != nil {
+		return errors.WithMessagef(err, "template %s", name)
+	}
+
+	sourceDir, err := filepath.EvalSymlinks(d.cacheDir)
+	if err != nil {
+		return errors.WithMessagef(err, "template %s", name)
+	}
+
+	// symlinks within the source must not lead out of it either
+	if rel, err := filepath.Rel(sourceDir, srcDir); err != nil || !filepath.IsLocal(rel) {
+		return fmt.Errorf("template %s is outside of its source", name)
+	}
+
+	return copyDir(srcDir, destDir)
+}
+
+// copyDir copies the contents of srcDir into destDir, skipping version control metadata
+func copyDir(srcDir string, destDir string) error {
+	return filepath.WalkDir(srcDir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(srcDir, path)
+		if err != nil {
+			return err
+		}
+
+		if entry.IsDir() && entry.Name() == ".git" {
+			return filepath.SkipDir
+		}
+
+		dest := filepath.Join(destDir, rel)
+
+		if entry.IsDir() {
+			return os.MkdirAll(dest, 0o755)
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+
+		src, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer src.Close()
+
+		dst, err := os.OpenFile(dest, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, info.Mode().Perm())
+		if err != nil {
+			return err
+		}
+		defer dst.Close()
+
+		_, err = io.Copy(dst, src)
+
+		return err
+	})
+}
+
+// multiDownloader combines the official templates with the templates of each configured source
+type multiDownloader struct {
+	downloaders []Downloader
+	// err is returned when listing templates if the sources configuration couldn't be read
+	err error
+	// skipped are the errors of the sources left out when listing templates
+	skipped []error
+}
+
+var _ Downloader = &multiDownloader{}
+
+func (m *multiDownloader) Templates() ([]TemplateInfo, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+
+	all := []TemplateInfo{}
+	m.skipped = nil
+
+	// an unreachable source shouldn't prevent using the templates of the others
+	for _, d := range m.downloaders {
+		templates, err := d.Templates()
+		if err != nil {
+			m.skipped = append(m.skipped, err)
+			continue
+		}
+
+		all = append(all, templates...)
+	}
+
+	if len(all) == 0 && len(m.skipped) > 0 {
+		msgs := make([]string, len(m.skipped))
+		for i, err := range m.skipped {
+			msgs[i] = err.Error()
+		}
+
+		return nil, errors.New(strings.Join(msgs, "\n"))
+	}
+
+	return all, nil
+}
+
+// SkippedSources returns the errors of the template sources that were left out of the last list of templates
+func SkippedSources(d Downloader) []error {
+	if m, ok := d.(*multiDownloader); ok {
+		return m.skipped
+	}
+
+	return nil
+}
+
+func (m *multiDownloader) Get(name string) *TemplateInfo {
+	for _, d := range m.downloaders {
+		if ti := d.Get(name); ti != nil {
+			return ti
+		}
+	}
+
+	return nil
+}
+
+func (m *multiDownloader) GetByLabel(label string) *TemplateInfo {
+	for _, d := range m.downloaders {
+		if ti := d.GetByLabel(label); ti != nil {
+			return ti
+		}
+	}
+
+	return nil
+}
+
+func (m *multiDownloader) DownloadDirectoryContents(name string, destDir string, force bool) error {
+	for _, d := range m.downloaders {
+		if d.Get(name) != nil {
+			return d.DownloadDirectoryContents(name, destDir, force)
+		}
+	}
+
+	return fmt.Errorf("template %s not found", name)
+}
+
+// placeholder returns the token substituted with a placeholder value in template files and paths
+func placeholder(key string) string {
+	return "{{" + key + "}}"
+}
+
+// ApplyPlaceholders substitutes {{key}} placeholders in the file contents and file names of a created project
+func ApplyPlaceholders(dir string, values map[string]string) error {
+	if len(values) == 0 {
+		return nil
+	}
+
+	replacements := []string{}
+	for key, value := range values {
+		replacements = append(replacements, placeholder(key), value)
+	}
+
+	replacer := strings.NewReplacer(replacements...)
+
+	renames := map[string]string{}
+
+	err := filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if entry.IsDir() && entry.Name() == ".git" {
+			return filepath.SkipDir
+		}
+
+		if renamed := replacer.Replace(entry.Name()); renamed != entry.Name() {
+			renames[path] = filepath.Join(filepath.Dir(path), renamed)
+		}
+
+		if entry.IsDir() {
+			return nil
+		}
+
+		contents, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+
+		replaced := replacer.Replace(string(contents))
+		if replaced == string(contents) {
+			return nil
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+
+		return os.WriteFile(path, []byte(replaced), info.Mode().Perm())
+	})
+	if err != nil {
+		return err
+	}
+
+	// rename the deepest paths first so parent directory renames don't invalidate them
+	oldPaths := make([]string, 0, len(renames))
+	for oldPath := range renames {
+		oldPaths = append(oldPaths, oldPath)
+	}
+
+	sort.Slice(oldPaths, func(i, j int) bool {
+		return strings.Count(oldPaths[i], string(filepath.Separator)) > strings.Count(oldPaths[j], string(filepath.Separator))
+	})
+
+	for _, oldPath := range oldPaths {
+		if err := os.Rename(oldPath, renames[oldPath]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
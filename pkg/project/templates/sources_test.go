@@ -0,0 +1,162 @@
+// Copyright Nitric Pty Ltd.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package templates
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	for name, contents := range files {
+		path := filepath.Join(dir, name)
+
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := os.WriteFile(path, []byte(contents), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestLocalSource(t *testing.T) {
+	sourceDir := t.TempDir()
+
+	writeFiles(t, sourceDir, map[string]string{
+		"cli-templates.yaml": `templates:
+  - name: go-api
+    label: Go API
+    path: ./go-api
+    placeholders:
+      service_name: api
+`,
+		"go-api/nitric.yaml":                           "name: {{project_name}}\n",
+		"go-api/services/{{service_name}}.go":          "// {{service_name}} service\n",
+		"go-api/.git/config":                           "ignored",
+		"go-api/{{service_name}}/readme.md":            "# {{project_name}}",
+		"go-api/{{service_name}}/{{project_name}}.txt": "nested",
+	})
+
+	d := newSourceDownloader(Source{Name: "acme", Location: sourceDir})
+	d.cacheDir = filepath.Join(t.TempDir(), "cache")
+
+	template := d.GetByLabel("Go API (acme)")
+	if template == nil || template.Name != "acme/go-api" {
+		t.Fatalf("GetByLabel() = %v, want acme/go-api", template)
+	}
+
+	projDir := filepath.Join(t.TempDir(), "my-project")
+
+	if err := d.DownloadDirectoryContents(template.Name, projDir, false); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ApplyPlaceholders(projDir, map[string]string{"project_name": "my-project", "service_name": "orders"}); err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]string{
+		"nitric.yaml":           "name: my-project\n",
+		"services/orders.go":    "// orders service\n",
+		"orders/readme.md":      "# my-project",
+		"orders/my-project.txt": "nested",
+	}
+
+	for name, contents := range want {
+		got, err := os.ReadFile(filepath.Join(projDir, name))
+		if err != nil {
+			t.Errorf("expected file %s: %v", name, err)
+			continue
+		}
+
+		if string(got) != contents {
+			t.Errorf("file %s = %q, want %q", name, got, contents)
+		}
+	}
+
+	if _, err := os.Stat(filepath.Join(projDir, ".git")); !os.IsNotExist(err) {
+		t.Errorf("expected .git directory not to be copied")
+	}
+}
+
+func TestSourceTemplatePaths(t *testing.T) {
+	for _, tt := range []struct {
+		name    string
+		path    string
+		wantErr bool
+	}{
+		{name: "relative path", path: "./go-api"},
+		{name: "source root", path: ""},
+		{name: "parent directory", path: "../go-api", wantErr: true},
+		{name: "nested parent directory", path: "go-api/../../go-api", wantErr: true},
+		{name: "absolute path", path: "/etc", wantErr: true},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			sourceDir := t.TempDir()
+
+			writeFiles(t, sourceDir, map[string]string{
+				"cli-templates.yaml": "templates:\n  - name: go-api\n    path: " + tt.path + "\n",
+			})
+
+			d := newSourceDownloader(Source{Name: "acme", Location: sourceDir})
+			d.cacheDir = filepath.Join(t.TempDir(), "cache")
+
+			_, err := d.Templates()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Templates() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestMultiDownloaderSkipsFailingSources(t *testing.T) {
+	sourceDir := t.TempDir()
+
+	writeFiles(t, sourceDir, map[string]string{
+		"cli-templates.yaml": "templates:\n  - name: go-api\n    path: ./go-api\n",
+	})
+
+	working := newSourceDownloader(Source{Name: "acme", Location: sourceDir})
+	working.cacheDir = filepath.Join(t.TempDir(), "acme")
+
+	failing := newSourceDownloader(Source{Name: "missing", Location: filepath.Join(t.TempDir(), "missing")})
+	failing.cacheDir = filepath.Join(t.TempDir(), "missing")
+
+	d := &multiDownloader{downloaders: []Downloader{failing, working}}
+
+	templates, err := d.Templates()
+	if err != nil {
+		t.Fatalf("Templates() error = %v", err)
+	}
+
+	if len(templates) != 1 || templates[0].Name != "acme/go-api" {
+		t.Errorf("Templates() = %v, want acme/go-api", templates)
+	}
+
+	if skipped := SkippedSources(d); len(skipped) != 1 {
+		t.Errorf("SkippedSources() = %v, want the missing source", skipped)
+	}
+
+	onlyFailing := &multiDownloader{downloaders: []Downloader{failing}}
+
+	if _, err := onlyFailing.Templates(); err == nil {
+		t.Errorf("Templates() expected an error when every source fails")
+	}
+}
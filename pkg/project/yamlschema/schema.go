@@ -0,0 +1,170 @@
+// Copyright Nitric Pty Ltd.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package yamlschema
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+)
+
+const draft = "https://json-schema.org/draft/2020-12/schema"
+
+// Schema is the subset of JSON Schema used to describe nitric configuration files
+type Schema struct {
+	Schema               string             `json:"$schema,omitempty"`
+	Title                string             `json:"title,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	AdditionalProperties any                `json:"additionalProperties,omitempty"`
+	Pattern              string             `json:"pattern,omitempty"`
+}
+
+// Root marks the schema as the schema of a whole document
+func (s *Schema) Root(title string) *Schema {
+	s.Schema = draft
+	s.Title = title
+
+	return s
+}
+
+// field is a yaml field of a struct, including fields of inlined structs
+type field struct {
+	name string
+	typ  reflect.Type
+}
+
+// yamlName returns the yaml key of a struct field, following the naming rules of gopkg.in/yaml
+func yamlName(f reflect.StructField) (name string, inline bool, skip bool) {
+	tag := f.Tag.Get("yaml")
+	parts := strings.Split(tag, ",")
+
+	if parts[0] == "-" || !f.IsExported() {
+		return "", false, true
+	}
+
+	for _, opt := range parts[1:] {
+		if opt == "inline" {
+			return "", true, false
+		}
+	}
+
+	if parts[0] != "" {
+		return parts[0], false, false
+	}
+
+	return strings.ToLower(f.Name), false, false
+}
+
+// fields returns the yaml fields of a struct type in declaration order
+func fields(t reflect.Type) []field {
+	result := []field{}
+
+	for i := 0; i < t.NumField(); i++ {
+		name, inline, skip := yamlName(t.Field(i))
+		if skip {
+			continue
+		}
+
+		if inline {
+			inlineType := indirect(t.Field(i).Type)
+			if inlineType.Kind() == reflect.Struct {
+				result = append(result, fields(inlineType)...)
+			}
+
+			continue
+		}
+
+		result = append(result, field{name: name, typ: t.Field(i).Type})
+	}
+
+	return result
+}
+
+func indirect(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	return t
+}
+
+// Generate returns the JSON Schema of a type decoded from yaml, unknown struct fields are not allowed
+func Generate(t reflect.Type) *Schema {
+	t = indirect(t)
+
+	switch t.Kind() {
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.Slice, reflect.Array:
+		return &Schema{Type: "array", Items: Generate(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: Generate(t.Elem())}
+	case reflect.Struct:
+		schema := &Schema{
+			Type:                 "object",
+			Properties:           map[string]*Schema{},
+			AdditionalProperties: false,
+		}
+
+		for _, f := range fields(t) {
+			schema.Properties[f.name] = Generate(f.typ)
+		}
+
+		return schema
+	default:
+		// interfaces accept any value
+		return &Schema{}
+	}
+}
+
+// Keys returns an object schema with the given keys, each accepting any value, and no other keys allowed
+func Keys(keys []string, required []string) *Schema {
+	schema := &Schema{
+		Type:                 "object",
+		Properties:           map[string]*Schema{},
+		Required:             required,
+		AdditionalProperties: false,
+	}
+
+	for _, key := range keys {
+		schema.Properties[key] = &Schema{}
+	}
+
+	return schema
+}
+
+// propertyNames returns the sorted property names of an object schema
+func (s *Schema) propertyNames() []string {
+	names := make([]string, 0, len(s.Properties))
+	for name := range s.Properties {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
@@ -0,0 +1,186 @@
+// Copyright Nitric Pty Ltd.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package yamlschema
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// FieldError is an unknown or misspelled key in a yaml document
+type FieldError struct {
+	// Path is the dot separated path of the mapping containing the key, empty for the root of the document
+	Path       string
+	Key        string
+	Line       int
+	Column     int
+	Suggestion string
+}
+
+func (e *FieldError) Error() string {
+	msg := fmt.Sprintf("line %d, column %d: unknown field %q", e.Line, e.Column, e.Key)
+
+	if e.Path != "" {
+		msg += " in " + e.Path
+	}
+
+	if e.Suggestion != "" {
+		msg += fmt.Sprintf(", did you mean %q?", e.Suggestion)
+	}
+
+	return msg
+}
+
+// FieldErrors is returned when a document contains unknown keys
+type FieldErrors []*FieldError
+
+func (e FieldErrors) Error() string {
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Error()
+	}
+
+	return strings.Join(msgs, "\n")
+}
+
+// Validate checks the keys of a yaml document against the schema, returning FieldErrors for any keys the schema doesn't allow.
+// Value types aren't checked, they are reported when the document is decoded.
+func Validate(contents []byte, schema *Schema) error {
+	doc := yaml.Node{}
+
+	if err := yaml.Unmarshal(contents, &doc); err != nil {
+		return err
+	}
+
+	if len(doc.Content) == 0 {
+		return nil
+	}
+
+	errs := validateNode(doc.Content[0], schema, "")
+	if len(errs) > 0 {
+		return errs
+	}
+
+	return nil
+}
+
+func joinPath(path string, key string) string {
+	if path == "" {
+		return key
+	}
+
+	return path + "." + key
+}
+
+func validateNode(node *yaml.Node, schema *Schema, path string) FieldErrors {
+	if schema == nil {
+		return nil
+	}
+
+	if node.Kind == yaml.AliasNode {
+		node = node.Alias
+	}
+
+	errs := FieldErrors{}
+
+	switch {
+	case node.Kind == yaml.MappingNode && schema.Type == "object":
+		for i := 0; i < len(node.Content)-1; i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+
+			// merge keys are expanded by the decoder
+			if key.Value == "<<" {
+				continue
+			}
+
+			if property, ok := schema.Properties[key.Value]; ok {
+				errs = append(errs, validateNode(value, property, joinPath(path, key.Value))...)
+				continue
+			}
+
+			switch additional := schema.AdditionalProperties.(type) {
+			case *Schema:
+				errs = append(errs, validateNode(value, additional, joinPath(path, key.Value))...)
+			case bool:
+				if additional {
+					continue
+				}
+
+				errs = append(errs, &FieldError{
+					Path:       path,
+					Key:        key.Value,
+					Line:       key.Line,
+					Column:     key.Column,
+					Suggestion: suggest(key.Value, schema.propertyNames()),
+				})
+			}
+		}
+	case node.Kind == yaml.SequenceNode && schema.Type == "array":
+		for i, item := range node.Content {
+			errs = append(errs, validateNode(item, schema.Items, joinPath(path, strconv.Itoa(i)))...)
+		}
+	}
+
+	return errs
+}
+
+// suggest returns the closest known key to a misspelled key, if there is one close enough
+func suggest(key string, known []string) string {
+	normalized := strings.ToLower(strings.ReplaceAll(key, "_", "-"))
+
+	best := ""
+	bestDistance := max(len(key)/3, 2) + 1
+
+	for _, candidate := range known {
+		distance := levenshtein(normalized, candidate)
+		if distance < bestDistance {
+			best = candidate
+			bestDistance = distance
+		}
+	}
+
+	return best
+}
+
+func levenshtein(a string, b string) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+
+		prev, curr = curr, prev
+	}
+
+	return prev[len(b)]
+}
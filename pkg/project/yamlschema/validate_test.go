@@ -0,0 +1,109 @@
+// Copyright Nitric Pty Ltd.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package yamlschema
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+type testService struct {
+	Match string `yaml:"match"`
+	Start string `yaml:"start"`
+}
+
+type testConfig struct {
+	Name     string                 `yaml:"name"`
+	Services []testService          `yaml:"services"`
+	Batches  []testService          `yaml:"batch-services"`
+	Apis     map[string]testService `yaml:"apis"`
+	Internal string                 `yaml:"-"`
+}
+
+func TestValidate(t *testing.T) {
+	schema := Generate(reflect.TypeOf(testConfig{}))
+
+	tests := []struct {
+		name     string
+		contents string
+		want     []FieldError
+	}{
+		{
+			name: "valid",
+			contents: `name: test
+services:
+  - match: services/*.ts
+apis:
+  main:
+    start: npm start
+`,
+		},
+		{
+			name: "misspelled keys",
+			contents: `name: test
+services:
+  - mach: services/*.ts
+batch_services: []
+apis:
+  main:
+    strat: npm start
+`,
+			want: []FieldError{
+				{Path: "services.0", Key: "mach", Line: 3, Column: 5, Suggestion: "match"},
+				{Key: "batch_services", Line: 4, Column: 1, Suggestion: "batch-services"},
+				{Path: "apis.main", Key: "strat", Line: 7, Column: 5, Suggestion: "start"},
+			},
+		},
+		{
+			name:     "unknown key without a suggestion",
+			contents: "internal: true\n",
+			want: []FieldError{
+				{Key: "internal", Line: 1, Column: 1},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Validate([]byte(tt.contents), schema)
+
+			if len(tt.want) == 0 {
+				if err != nil {
+					t.Fatalf("Validate() error = %v, want nil", err)
+				}
+
+				return
+			}
+
+			fieldErrs := FieldErrors{}
+			if !errors.As(err, &fieldErrs) {
+				t.Fatalf("Validate() error = %v, want FieldErrors", err)
+			}
+
+			if len(fieldErrs) != len(tt.want) {
+				t.Fatalf("Validate() returned %d errors, want %d: %v", len(fieldErrs), len(tt.want), err)
+			}
+
+			for i, want := range tt.want {
+				if *fieldErrs[i] != want {
+					t.Errorf("Validate() error %d = %+v, want %+v", i, *fieldErrs[i], want)
+				}
+			}
+		})
+	}
+}
@@ -21,6 +21,8 @@ import (
 	"encoding/hex"
 	"fmt"
 	"os"
+	"path/filepath"
+	"strings"
 
 	"github.com/spf13/afero"
 
@@ -77,3 +79,37 @@ func GetOrGeneratePassphraseFile(fs afero.Fs, isNonInteractive bool) (string, er
 
 	return path, nil
 }
+
+// GetOrGenerateLocalSecretsKey returns the key used to encrypt local secret values, generating it on first use.
+// The key is kept in the nitric home directory, outside of projects, so it isn't committed or synced with them.
+func GetOrGenerateLocalSecretsKey(fs afero.Fs) ([]byte, error) {
+	path := paths.NitricLocalSecretsKeyPath()
+
+	if contents, err := afero.ReadFile(fs, path); err == nil {
+		key, err := hex.DecodeString(strings.TrimSpace(string(contents)))
+		if err != nil || len(key) != passphraseBytes {
+			return nil, fmt.Errorf("local secrets key %s is invalid", path)
+		}
+
+		return key, nil
+	} else if !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	logger.Debugf("generating new local secrets key: %s", path)
+
+	newKey, err := randomString()
+	if err != nil {
+		return nil, fmt.Errorf("error generating local secrets key: %w", err)
+	}
+
+	if err := fs.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+		return nil, err
+	}
+
+	if err := afero.WriteFile(fs, path, []byte(newKey), 0o600); err != nil {
+		return nil, err
+	}
+
+	return hex.DecodeString(newKey)
+}
@@ -25,9 +25,11 @@ import (
 	"github.com/charmbracelet/bubbles/key"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/samber/lo"
 
 	"github.com/nitrictech/cli/pkg/cloud"
 	"github.com/nitrictech/cli/pkg/cloud/apis"
+	"github.com/nitrictech/cli/pkg/cloud/health"
 	"github.com/nitrictech/cli/pkg/cloud/http"
 	"github.com/nitrictech/cli/pkg/cloud/resources"
 	"github.com/nitrictech/cli/pkg/cloud/schedules"
@@ -81,6 +83,11 @@ type WebsiteSummary struct {
 	url  string
 }
 
+type ServiceHealthSummary struct {
+	name   string
+	status string
+}
+
 type TuiModel struct {
 	localCloud  *cloud.LocalCloud
 	apis        []ApiSummary
@@ -90,6 +97,7 @@ type TuiModel struct {
 	schedules   []ScheduleSummary
 	databases   []DatabaseSummary
 	websites    []WebsiteSummary
+	health      []ServiceHealthSummary
 
 	resources *resources.LocalResourcesState
 
@@ -112,6 +120,7 @@ func (t *TuiModel) Init() tea.Cmd {
 	reactive.ListenFor(t.reactiveSub, t.localCloud.Schedules.SubscribeToState)
 	reactive.ListenFor(t.reactiveSub, t.localCloud.Topics.SubscribeToState)
 	reactive.ListenFor(t.reactiveSub, t.localCloud.Websites.SubscribeToState)
+	reactive.ListenFor(t.reactiveSub, t.localCloud.Health.SubscribeToState)
 
 	return t.reactiveSub.AwaitNextMsg()
 }
@@ -242,6 +251,35 @@ func (t *TuiModel) ReactiveUpdate(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 
 		t.websites = newWebsitesSummary
+	case health.State:
+		newHealthSummary := []ServiceHealthSummary{}
+
+		for serviceName, replicas := range state {
+			healthy := lo.CountBy(replicas, func(replica health.ReplicaHealth) bool {
+				return replica.Status == health.Status_Healthy
+			})
+
+			status := string(health.Status_Healthy)
+
+			switch {
+			case len(replicas) == 1:
+				status = string(replicas[0].Status)
+			case healthy < len(replicas):
+				status = fmt.Sprintf("%d/%d replicas healthy", healthy, len(replicas))
+			}
+
+			newHealthSummary = append(newHealthSummary, ServiceHealthSummary{
+				name:   serviceName,
+				status: status,
+			})
+		}
+
+		// sort by name
+		sort.Slice(newHealthSummary, func(i, j int) bool {
+			return newHealthSummary[i].name < newHealthSummary[j].name
+		})
+
+		t.health = newHealthSummary
 	}
 
 	return t, t.reactiveSub.AwaitNextMsg()
@@ -314,6 +352,11 @@ func (t *TuiModel) View() string {
 		v.Addln(site.url).WithStyle(textHighlight)
 	}
 
+	for _, svc := range t.health {
+		v.Addf("svc:%s - ", svc.name)
+		v.Addln(svc.status).WithStyle(textHighlight)
+	}
+
 	if t.resources != nil {
 		if len(t.resources.ServiceErrors) > 0 {
 			v.Break()
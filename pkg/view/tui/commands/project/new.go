@@ -18,8 +18,10 @@ package project
 
 import (
 	"fmt"
+	"maps"
 	"path"
 	"path/filepath"
+	"slices"
 	"time"
 
 	"github.com/charmbracelet/bubbles/spinner"
@@ -49,6 +51,7 @@ type NewProjectStatus int
 const (
 	NameInput NewProjectStatus = iota
 	TemplateInput
+	PlaceholderInput
 	Pending
 	Done
 	Error
@@ -65,6 +68,15 @@ type Model struct {
 	force          bool
 
 	downloader templates.Downloader
+	// skippedSources are the errors of the template sources that couldn't be listed
+	skippedSources []error
+	// placeholders are values provided on the command line or prompted for template placeholders
+	placeholders map[string]string
+	// placeholderKeys are the template placeholders prompted for, in the order of placeholderPrompts
+	placeholderKeys    []string
+	placeholderPrompts []textprompt.TextPrompt
+	// placeholderIndex is the index of the placeholder prompt being answered
+	placeholderIndex int
 
 	fs afero.Fs
 
@@ -141,6 +153,20 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.status = TemplateInput
 		}
 
+		if m.status == PlaceholderInput && msg.ID == m.placeholderPrompts[m.placeholderIndex].ID {
+			m.placeholderPrompts[m.placeholderIndex].Blur()
+			m.placeholders[m.placeholderKeys[m.placeholderIndex]] = msg.Value
+			m.placeholderIndex++
+
+			if m.placeholderIndex < len(m.placeholderPrompts) {
+				return m, m.placeholderPrompts[m.placeholderIndex].Focus()
+			}
+
+			m.status = Pending
+
+			return m, tea.Batch(m.spinner.Tick, m.createProject(m.fs))
+		}
+
 		return m, nil
 	}
 
@@ -151,9 +177,19 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case TemplateInput:
 		m.templatePrompt, cmd = m.templatePrompt.UpdateListPrompt(msg)
 		if m.templatePrompt.Choice() != "" {
+			m.placeholderKeys, m.placeholderPrompts = placeholderPrompts(m.downloader.GetByLabel(m.templatePrompt.Choice()), m.placeholders)
+
+			if len(m.placeholderPrompts) > 0 {
+				m.status = PlaceholderInput
+				return m, m.placeholderPrompts[0].Focus()
+			}
+
 			m.status = Pending
+
 			return m, tea.Batch(m.spinner.Tick, m.createProject(m.fs))
 		}
+	case PlaceholderInput:
+		m.placeholderPrompts[m.placeholderIndex], cmd = m.placeholderPrompts[m.placeholderIndex].UpdateTextPrompt(msg)
 	case Pending:
 		m.spinner, cmd = m.spinner.Update(msg)
 	case Done:
@@ -176,6 +212,8 @@ var (
 var (
 	errorTagStyle           = lipgloss.NewStyle().Background(tui.Colors.Red).Foreground(tui.Colors.White).PaddingLeft(2).PaddingRight(2).Align(lipgloss.Center)
 	errorTextStyle          = lipgloss.NewStyle().PaddingLeft(2).Foreground(tui.Colors.Red)
+	warningTagStyle         = lipgloss.NewStyle().Background(tui.Colors.Orange).Foreground(tui.Colors.White).PaddingLeft(2).PaddingRight(2).Align(lipgloss.Center)
+	warningTextStyle        = lipgloss.NewStyle().PaddingLeft(2).Foreground(tui.Colors.Orange)
 	tagStyle                = lipgloss.NewStyle().Width(8).Background(tui.Colors.Purple).Foreground(tui.Colors.White).Align(lipgloss.Center)
 	projCreatedHeadingStyle = lipgloss.NewStyle().Bold(true).MarginLeft(2)
 )
@@ -193,6 +231,11 @@ func (m Model) View() string {
 		return v.Render()
 	}
 
+	for _, skipped := range m.skippedSources {
+		v.Add("warning").WithStyle(warningTagStyle)
+		v.Addln("skipped template source, %s", skipped.Error()).WithStyle(warningTextStyle)
+	}
+
 	if !m.nonInteractive {
 		v.Add("nitric").WithStyle(titleStyle)
 		v.Addln("Let's get going!")
@@ -204,6 +247,14 @@ func (m Model) View() string {
 		if m.status >= TemplateInput {
 			v.Add(m.templatePrompt.View())
 		}
+
+		// Template placeholder inputs
+		if m.status >= PlaceholderInput {
+			for i := 0; i < len(m.placeholderPrompts) && i <= m.placeholderIndex; i++ {
+				v.Break()
+				v.Add(m.placeholderPrompts[i].View())
+			}
+		}
 	}
 
 	// Creating Status
@@ -247,6 +298,8 @@ type Args struct {
 	ProjectName  string
 	TemplateName string
 	Force        bool
+	// Placeholders override the default values of the template's placeholders
+	Placeholders map[string]string
 }
 
 type TemplateItem struct {
@@ -281,14 +334,14 @@ func New(fs afero.Fs, args Args) (Model, error) {
 
 	downloadr := templates.NewDownloader()
 
-	templates, err := downloadr.Templates()
+	templateInfos, err := downloadr.Templates()
 	if err != nil {
 		return Model{}, err
 	}
 
 	templateItems := []list.ListItem{}
 
-	for _, template := range templates {
+	for _, template := range templateInfos {
 		templateItems = append(templateItems, &TemplateItem{Value: template.Label, Description: template.Desc})
 	}
 
@@ -336,7 +389,13 @@ func New(fs afero.Fs, args Args) (Model, error) {
 		projectStatus = Pending
 	}
 
+	placeholders := map[string]string{}
+	for k, v := range args.Placeholders {
+		placeholders[k] = v
+	}
+
 	return Model{
+		skippedSources: templates.SkippedSources(downloadr),
 		namePrompt:     namePrompt,
 		templatePrompt: templatePrompt,
 		nonInteractive: isNonInteractive,
@@ -345,10 +404,39 @@ func New(fs afero.Fs, args Args) (Model, error) {
 		err:            nil,
 		fs:             fs,
 		downloader:     downloadr,
+		placeholders:   placeholders,
 		force:          args.Force,
 	}, nil
 }
 
+// placeholderPrompts returns a prompt for each placeholder of the template that wasn't provided on the command line, sorted by name
+func placeholderPrompts(template *templates.TemplateInfo, provided map[string]string) ([]string, []textprompt.TextPrompt) {
+	keys := []string{}
+	prompts := []textprompt.TextPrompt{}
+
+	if template == nil {
+		return keys, prompts
+	}
+
+	for _, key := range slices.Sorted(maps.Keys(template.Placeholders)) {
+		// the project name is always the name entered for the project
+		if _, ok := provided[key]; ok || key == "project_name" {
+			continue
+		}
+
+		keys = append(keys, key)
+		prompts = append(prompts, textprompt.NewTextPrompt("placeholder-"+key, textprompt.TextPromptArgs{
+			Prompt:            fmt.Sprintf("What should %s be?", key),
+			Tag:               "value",
+			Placeholder:       template.Placeholders[key],
+			Validator:         func(string) error { return nil },
+			InFlightValidator: func(string) error { return nil },
+		}))
+	}
+
+	return keys, prompts
+}
+
 type projectCreateResultMsg struct {
 	err error
 }
@@ -366,8 +454,27 @@ func (m Model) createProject(fs afero.Fs) tea.Cmd {
 
 		projDir := path.Join(cd, m.ProjectName())
 
-		downloadr := templates.NewDownloader()
-		if err = downloadr.DownloadDirectoryContents(m.TemplateName(), projDir, m.force); err != nil {
+		template := m.downloader.GetByLabel(m.templatePrompt.Choice())
+
+		if err = m.downloader.DownloadDirectoryContents(template.Name, projDir, m.force); err != nil {
+			return projectCreateResultMsg{
+				err: err,
+			}
+		}
+
+		placeholders := map[string]string{
+			"project_name": m.ProjectName(),
+		}
+
+		for k, v := range template.Placeholders {
+			placeholders[k] = v
+		}
+
+		for k, v := range m.placeholders {
+			placeholders[k] = v
+		}
+
+		if err = templates.ApplyPlaceholders(projDir, placeholders); err != nil {
 			return projectCreateResultMsg{
 				err: err,
 			}
@@ -376,15 +483,19 @@ func (m Model) createProject(fs afero.Fs) tea.Cmd {
 		yamlPath := filepath.Join(projDir, "./nitric.yaml")
 
 		// Load and update the project name in the template's nitric.yaml
-		p, err := project.ConfigurationFromFile(fs, yamlPath)
+		editor, err := project.EditConfigurationFile(fs, yamlPath)
 		if err != nil {
 			return projectCreateResultMsg{
 				err: err,
 			}
 		}
 
-		p.Name = m.ProjectName()
+		if err := editor.Set("name", m.ProjectName()); err != nil {
+			return projectCreateResultMsg{
+				err: err,
+			}
+		}
 
-		return projectCreateResultMsg{err: p.ToFile(fs, yamlPath)}
+		return projectCreateResultMsg{err: editor.Save()}
 	}
 }
@@ -90,7 +90,7 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		// Write log to file and handle any errors
 		level := logrus.InfoLevel
 
-		if msg.Value.Status == project.ServiceRunStatus_Error {
+		if msg.Value.Status == project.ServiceRunStatus_Error || msg.Value.Status == project.ServiceRunStatus_OOMKilled {
 			level = logrus.ErrorLevel
 		}
 
@@ -217,7 +217,7 @@ func (m Model) View() string {
 
 	for _, update := range m.serviceRunUpdates {
 		statusColor := tui.Colors.TextMuted
-		if update.Status == project.ServiceRunStatus(project.ServiceBuildStatus_Error) {
+		if update.Status == project.ServiceRunStatus(project.ServiceBuildStatus_Error) || update.Status == project.ServiceRunStatus_OOMKilled {
 			statusColor = tui.Colors.Red
 		}
 
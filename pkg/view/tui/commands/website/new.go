@@ -527,10 +527,19 @@ func (m Model) updateConfig() tea.Cmd {
 			Path: path,
 		}
 
+		editor, err := project.EditConfigurationFile(m.fs, "")
+		if err != nil {
+			return configUpdatedResultMsg{err: err}
+		}
+
+		if err := editor.Append("websites", website); err != nil {
+			return configUpdatedResultMsg{err: err}
+		}
+
 		m.config.Websites = append(m.config.Websites, website)
 
 		return configUpdatedResultMsg{
-			err: m.config.ToFile(m.fs, ""),
+			err: editor.Save(),
 		}
 	}
 }
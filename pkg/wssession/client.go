@@ -0,0 +1,208 @@
+// Copyright Nitric Pty Ltd.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wssession
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/fasthttp/websocket"
+)
+
+// Message is a message sent or received by a test client
+type Message struct {
+	// Data is base64 encoded for binary messages
+	Data     string    `json:"data"`
+	Binary   bool      `json:"binary,omitempty"`
+	Outgoing bool      `json:"outgoing,omitempty"`
+	Time     time.Time `json:"time"`
+}
+
+func newMessage(data []byte, binary bool, outgoing bool) Message {
+	msg := Message{
+		Data:     string(data),
+		Binary:   binary,
+		Outgoing: outgoing,
+		Time:     time.Now(),
+	}
+
+	if binary {
+		msg.Data = base64.StdEncoding.EncodeToString(data)
+	}
+
+	return msg
+}
+
+// receiveBuffer is the number of pushed messages kept for Receive, later pushes are only kept in the message history
+const receiveBuffer = 100
+
+// Client is a websocket test client connected to a socket on the local gateway
+type Client struct {
+	conn *websocket.Conn
+
+	lock     sync.RWMutex
+	messages []Message
+	received chan Message
+	// dropped counts the pushed messages that didn't fit in the receive buffer since the last Receive
+	dropped int
+	closed  chan struct{}
+	err     error
+}
+
+// Dial connects a new test client to the websocket address, e.g. ws://localhost:4001
+func Dial(address string, query map[string]string) (*Client, error) {
+	u, err := url.Parse(address)
+	if err != nil {
+		return nil, fmt.Errorf("invalid websocket address %s: %w", address, err)
+	}
+
+	values := u.Query()
+	for k, v := range query {
+		values.Set(k, v)
+	}
+
+	u.RawQuery = values.Encode()
+
+	conn, _, err := websocket.DefaultDialer.Dial(u.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("unable to connect to %s: %w", u.String(), err)
+	}
+
+	c := &Client{
+		conn:     conn,
+		messages: []Message{},
+		received: make(chan Message, receiveBuffer),
+		closed:   make(chan struct{}),
+	}
+
+	go c.read()
+
+	return c, nil
+}
+
+func (c *Client) read() {
+	defer close(c.closed)
+
+	for {
+		messageType, data, err := c.conn.ReadMessage()
+		if err != nil {
+			c.lock.Lock()
+			c.err = err
+			c.lock.Unlock()
+
+			return
+		}
+
+		msg := newMessage(data, messageType == websocket.BinaryMessage, false)
+
+		c.record(msg)
+
+		select {
+		case c.received <- msg:
+		default:
+			// nobody is waiting on pushes, they remain available in the message history
+			c.lock.Lock()
+			c.dropped++
+			c.lock.Unlock()
+		}
+	}
+}
+
+func (c *Client) record(msg Message) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.messages = append(c.messages, msg)
+}
+
+// Send sends a text message, or a binary message when binary is true
+func (c *Client) Send(data []byte, binary bool) error {
+	messageType := websocket.TextMessage
+	if binary {
+		messageType = websocket.BinaryMessage
+	}
+
+	err := c.conn.WriteMessage(messageType, data)
+	if err != nil {
+		return err
+	}
+
+	c.record(newMessage(data, binary, true))
+
+	return nil
+}
+
+// Receive waits for the next message pushed by the server.
+// Messages pushed before the connection closed are received before the close is reported.
+func (c *Client) Receive(timeout time.Duration) (Message, error) {
+	c.lock.Lock()
+	dropped := c.dropped
+	c.dropped = 0
+	c.lock.Unlock()
+
+	if dropped > 0 {
+		return Message{}, fmt.Errorf("%d pushed messages were dropped, more than %d messages arrived before they were expected", dropped, receiveBuffer)
+	}
+
+	select {
+	case msg := <-c.received:
+		return msg, nil
+	case <-c.closed:
+		// the reader stops pushing before it closes, so any remaining messages are already buffered
+		select {
+		case msg := <-c.received:
+			return msg, nil
+		default:
+		}
+
+		c.lock.RLock()
+		defer c.lock.RUnlock()
+
+		return Message{}, fmt.Errorf("connection closed: %w", c.err)
+	case <-time.After(timeout):
+		return Message{}, fmt.Errorf("no message received within %s", timeout)
+	}
+}
+
+// Messages returns every message sent and received by the client
+func (c *Client) Messages() []Message {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	messages := make([]Message, len(c.messages))
+	copy(messages, c.messages)
+
+	return messages
+}
+
+// Close disconnects the client
+func (c *Client) Close() error {
+	err := c.conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+	if err != nil {
+		return c.conn.Close()
+	}
+
+	select {
+	case <-c.closed:
+	case <-time.After(time.Second):
+	}
+
+	return c.conn.Close()
+}
@@ -0,0 +1,149 @@
+// Copyright Nitric Pty Ltd.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wssession
+
+import (
+	"encoding/base64"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+type StepResult struct {
+	Step   string `json:"step"`
+	Passed bool   `json:"passed"`
+	Error  string `json:"error,omitempty"`
+}
+
+type Result struct {
+	Session  string        `json:"session"`
+	Passed   bool          `json:"passed"`
+	Steps    []StepResult  `json:"steps"`
+	Messages []Message     `json:"messages"`
+	Duration time.Duration `json:"duration"`
+}
+
+// matches compares a received message to the expected message, binary messages are compared base64 encoded
+// when the step is binary and as raw data otherwise
+func matches(step Step, msg Message) bool {
+	data := msg.Data
+
+	if msg.Binary && !step.Binary {
+		raw, _ := base64.StdEncoding.DecodeString(msg.Data)
+		data = string(raw)
+	}
+
+	switch step.matchType() {
+	case MatchType_Contains:
+		return strings.Contains(data, step.Expect)
+	case MatchType_Regex:
+		return regexp.MustCompile(step.Expect).MatchString(data)
+	default:
+		return data == step.Expect
+	}
+}
+
+func runStep(client *Client, step Step) error {
+	switch {
+	case step.Send != "":
+		data := []byte(step.Send)
+
+		if step.Binary {
+			data, _ = base64.StdEncoding.DecodeString(step.Send)
+		}
+
+		return client.Send(data, step.Binary)
+	case step.Expect != "":
+		timeout := defaultExpectTimeout
+
+		if step.Timeout != "" {
+			timeout, _ = time.ParseDuration(step.Timeout)
+		}
+
+		msg, err := client.Receive(timeout)
+		if err != nil {
+			return err
+		}
+
+		if !matches(step, msg) {
+			return fmt.Errorf("received %q", msg.Data)
+		}
+
+		return nil
+	default:
+		wait, _ := time.ParseDuration(step.Wait)
+
+		time.Sleep(wait)
+
+		return nil
+	}
+}
+
+// Run connects to the websocket address, runs each step of the session and disconnects.
+// The session stops at the first failing step.
+func Run(address string, session *Session) (*Result, error) {
+	if err := session.Validate(); err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+
+	client, err := Dial(address, session.QueryParams)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &Result{
+		Session: session.Name,
+		Passed:  true,
+		Steps:   []StepResult{},
+	}
+
+	for _, step := range session.Steps {
+		stepResult := StepResult{
+			Step:   step.String(),
+			Passed: true,
+		}
+
+		for i := 0; i < max(step.Repeat, 1); i++ {
+			if err := runStep(client, step); err != nil {
+				stepResult.Passed = false
+				stepResult.Error = err.Error()
+
+				break
+			}
+		}
+
+		result.Steps = append(result.Steps, stepResult)
+
+		if !stepResult.Passed {
+			result.Passed = false
+			break
+		}
+	}
+
+	err = client.Close()
+	if err != nil && result.Passed {
+		return nil, err
+	}
+
+	result.Messages = client.Messages()
+	result.Duration = time.Since(start)
+
+	return result, nil
+}
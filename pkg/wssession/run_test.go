@@ -0,0 +1,191 @@
+// Copyright Nitric Pty Ltd.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wssession
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fasthttp/websocket"
+)
+
+func newEchoServer(t *testing.T) string {
+	upgrader := websocket.Upgrader{}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		for {
+			messageType, data, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+
+			if err := conn.WriteMessage(messageType, []byte(r.URL.Query().Get("prefix")+string(data))); err != nil {
+				return
+			}
+		}
+	}))
+
+	t.Cleanup(srv.Close)
+
+	return "ws" + strings.TrimPrefix(srv.URL, "http")
+}
+
+func TestRun(t *testing.T) {
+	address := newEchoServer(t)
+
+	tests := []struct {
+		name       string
+		steps      []Step
+		wantPassed bool
+	}{
+		{
+			name: "repeated echo",
+			steps: []Step{
+				{Send: "hello", Repeat: 2},
+				{Expect: "echo: hello", Repeat: 2},
+			},
+			wantPassed: true,
+		},
+		{
+			name: "binary and regex match",
+			steps: []Step{
+				{Send: "aGk=", Binary: true},
+				{Expect: "^echo: hi$", Match: MatchType_Regex},
+			},
+			wantPassed: true,
+		},
+		{
+			name: "unexpected response",
+			steps: []Step{
+				{Send: "hello"},
+				{Expect: "goodbye", Match: MatchType_Contains},
+			},
+			wantPassed: false,
+		},
+		{
+			name: "missing response",
+			steps: []Step{
+				{Expect: "hello", Timeout: "50ms"},
+			},
+			wantPassed: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := Run(address, &Session{
+				Name:        tt.name,
+				Socket:      "test",
+				QueryParams: map[string]string{"prefix": "echo: "},
+				Steps:       tt.steps,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if result.Passed != tt.wantPassed {
+				t.Errorf("Run() passed = %v, want %v, steps %+v", result.Passed, tt.wantPassed, result.Steps)
+			}
+		})
+	}
+}
+
+// newPushServer returns the address of a server that pushes count messages to each connection and then closes it
+func newPushServer(t *testing.T, count int) string {
+	upgrader := websocket.Upgrader{}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		for i := 0; i < count; i++ {
+			if err := conn.WriteMessage(websocket.TextMessage, []byte(fmt.Sprint(i))); err != nil {
+				return
+			}
+		}
+
+		_ = conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+	}))
+
+	t.Cleanup(srv.Close)
+
+	return "ws" + strings.TrimPrefix(srv.URL, "http")
+}
+
+func TestReceive(t *testing.T) {
+	tests := []struct {
+		name         string
+		pushed       int
+		wantReceived int
+		wantErr      string
+	}{
+		{
+			name:         "messages before close",
+			pushed:       3,
+			wantReceived: 3,
+			wantErr:      "connection closed",
+		},
+		{
+			name:         "dropped messages",
+			pushed:       receiveBuffer + 5,
+			wantReceived: 0,
+			wantErr:      "5 pushed messages were dropped",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, err := Dial(newPushServer(t, tt.pushed), nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer client.Close()
+
+			// wait for the server to push every message and close
+			<-client.closed
+
+			for i := 0; i < tt.wantReceived; i++ {
+				msg, err := client.Receive(time.Second)
+				if err != nil {
+					t.Fatalf("Receive() %d error = %v", i, err)
+				}
+
+				if msg.Data != fmt.Sprint(i) {
+					t.Errorf("Receive() %d = %s", i, msg.Data)
+				}
+			}
+
+			_, err = client.Receive(time.Second)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Receive() error = %v, want %s", err, tt.wantErr)
+			}
+		})
+	}
+}
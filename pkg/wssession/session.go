@@ -0,0 +1,241 @@
+// Copyright Nitric Pty Ltd.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wssession
+
+import (
+	"encoding/base64"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/afero"
+	"gopkg.in/yaml.v3"
+
+	"github.com/nitrictech/cli/pkg/paths"
+)
+
+type MatchType string
+
+const (
+	MatchType_Exact    MatchType = "exact"
+	MatchType_Contains MatchType = "contains"
+	MatchType_Regex    MatchType = "regex"
+)
+
+const defaultExpectTimeout = 5 * time.Second
+
+// Step is a single step of a scripted session, exactly one of Send, Expect or Wait must be set
+type Step struct {
+	// Send a message to the socket, base64 encoded when Binary is true
+	Send string `yaml:"send,omitempty" json:"send,omitempty"`
+	// Expect a message from the socket
+	Expect string `yaml:"expect,omitempty" json:"expect,omitempty"`
+	// Wait for a duration, e.g. 500ms
+	Wait string `yaml:"wait,omitempty" json:"wait,omitempty"`
+
+	Binary bool `yaml:"binary,omitempty" json:"binary,omitempty"`
+	// Match determines how expected messages are compared, defaults to exact
+	Match MatchType `yaml:"match,omitempty" json:"match,omitempty"`
+	// Repeat the step this many times, defaults to once
+	Repeat int `yaml:"repeat,omitempty" json:"repeat,omitempty"`
+	// Timeout for expected messages, defaults to 5s
+	Timeout string `yaml:"timeout,omitempty" json:"timeout,omitempty"`
+}
+
+// Session is a scripted websocket session: connect, run each step in order, then disconnect
+type Session struct {
+	Name        string            `yaml:"-" json:"name"`
+	Socket      string            `yaml:"socket" json:"socket"`
+	QueryParams map[string]string `yaml:"query,omitempty" json:"query,omitempty"`
+	Steps       []Step            `yaml:"steps" json:"steps"`
+}
+
+func (s Step) String() string {
+	var desc string
+
+	switch {
+	case s.Send != "":
+		desc = fmt.Sprintf("send %q", s.Send)
+	case s.Expect != "":
+		desc = fmt.Sprintf("expect %s %q", s.matchType(), s.Expect)
+	default:
+		desc = fmt.Sprintf("wait %s", s.Wait)
+	}
+
+	if s.Repeat > 1 {
+		desc = fmt.Sprintf("%s x%d", desc, s.Repeat)
+	}
+
+	return desc
+}
+
+func (s Step) matchType() MatchType {
+	if s.Match == "" {
+		return MatchType_Exact
+	}
+
+	return s.Match
+}
+
+func (s Step) validate() error {
+	set := 0
+
+	for _, v := range []string{s.Send, s.Expect, s.Wait} {
+		if v != "" {
+			set++
+		}
+	}
+
+	if set != 1 {
+		return fmt.Errorf("exactly one of send, expect or wait must be set")
+	}
+
+	switch s.matchType() {
+	case MatchType_Exact, MatchType_Contains:
+	case MatchType_Regex:
+		if _, err := regexp.Compile(s.Expect); err != nil {
+			return fmt.Errorf("invalid expect regex: %w", err)
+		}
+	default:
+		return fmt.Errorf("unknown match type '%s', expected one of exact, contains or regex", s.Match)
+	}
+
+	if s.Binary && s.Send != "" {
+		if _, err := base64.StdEncoding.DecodeString(s.Send); err != nil {
+			return fmt.Errorf("binary messages must be base64 encoded: %w", err)
+		}
+	}
+
+	for _, d := range []string{s.Wait, s.Timeout} {
+		if d == "" {
+			continue
+		}
+
+		if _, err := time.ParseDuration(d); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Validate checks the session is runnable
+func (s *Session) Validate() error {
+	if s.Socket == "" {
+		return fmt.Errorf("session %s has no socket", s.Name)
+	}
+
+	for i, step := range s.Steps {
+		if err := step.validate(); err != nil {
+			return fmt.Errorf("session %s step %d: %w", s.Name, i+1, err)
+		}
+	}
+
+	return nil
+}
+
+var validSessionName = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
+
+func sessionFile(projectDir string, name string) (string, error) {
+	if !validSessionName.MatchString(name) {
+		return "", fmt.Errorf("invalid session name '%s', only letters, numbers, dashes and underscores are allowed", name)
+	}
+
+	return filepath.Join(paths.NitricWebsocketSessionsDir(projectDir), name+".yaml"), nil
+}
+
+// Load reads a saved session from the project
+func Load(fs afero.Fs, projectDir string, name string) (*Session, error) {
+	file, err := sessionFile(projectDir, name)
+	if err != nil {
+		return nil, err
+	}
+
+	contents, err := afero.ReadFile(fs, file)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read session %s: %w", name, err)
+	}
+
+	session := &Session{}
+
+	if err := yaml.Unmarshal(contents, session); err != nil {
+		return nil, fmt.Errorf("unable to parse session %s: %w", file, err)
+	}
+
+	session.Name = name
+
+	return session, session.Validate()
+}
+
+// Save writes a session to the project so it can be re-run
+func Save(fs afero.Fs, projectDir string, session *Session) error {
+	if err := session.Validate(); err != nil {
+		return err
+	}
+
+	file, err := sessionFile(projectDir, session.Name)
+	if err != nil {
+		return err
+	}
+
+	contents, err := yaml.Marshal(session)
+	if err != nil {
+		return err
+	}
+
+	if err := fs.MkdirAll(filepath.Dir(file), 0o755); err != nil {
+		return err
+	}
+
+	return afero.WriteFile(fs, file, contents, 0o644)
+}
+
+// Delete removes a saved session from the project
+func Delete(fs afero.Fs, projectDir string, name string) error {
+	file, err := sessionFile(projectDir, name)
+	if err != nil {
+		return err
+	}
+
+	return fs.Remove(file)
+}
+
+// List returns the saved sessions of the project, sorted by name
+func List(fs afero.Fs, projectDir string) ([]*Session, error) {
+	files, err := afero.Glob(fs, filepath.Join(paths.NitricWebsocketSessionsDir(projectDir), "*.yaml"))
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Strings(files)
+
+	sessions := []*Session{}
+
+	for _, file := range files {
+		session, err := Load(fs, projectDir, strings.TrimSuffix(filepath.Base(file), ".yaml"))
+		if err != nil {
+			return nil, err
+		}
+
+		sessions = append(sessions, session)
+	}
+
+	return sessions, nil
+}